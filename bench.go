@@ -0,0 +1,162 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rhartert/yass/sat"
+)
+
+var flagWorkers = flag.Int(
+	"workers",
+	runtime.NumCPU(),
+	"number of instances solved in parallel",
+)
+
+// benchResult is the result of solving one instance of a benchmark.
+type benchResult struct {
+	instance  string
+	status    sat.LBool
+	duration  time.Duration
+	conflicts uint64
+	err       error
+}
+
+// listInstances returns the DIMACS CNF files (possibly compressed) contained
+// in the file tree rooted in the given directory, in lexical order.
+func listInstances(dir string) ([]string, error) {
+	instances := []string{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && hasExt(path, ".cnf") {
+			instances = append(instances, path)
+		}
+		return nil
+	})
+	return instances, err
+}
+
+// runBench solves every instance of the directory with the configured solver
+// options and limits, and prints a summary table with the number of solved
+// instances and the PAR-2 score (i.e. the average solving time where unsolved
+// instances count as twice the timeout).
+func runBench(cfg *config) (int, error) {
+	if len(cfg.args) != 1 {
+		return exitUnknown, fmt.Errorf("bench requires a single directory argument")
+	}
+	instances, err := listInstances(cfg.args[0])
+	if err != nil {
+		return exitUnknown, err
+	}
+	if len(instances) == 0 {
+		return exitUnknown, fmt.Errorf("no instance (.cnf file) found in %q", cfg.args[0])
+	}
+	if cfg.workers < 1 {
+		return exitUnknown, fmt.Errorf("the number of workers must be positive, got %d", cfg.workers)
+	}
+
+	results := solveInstances(cfg, instances, cfg.workers)
+	printBenchResults(cfg, results, func(instance string) string {
+		name, _ := filepath.Rel(cfg.args[0], instance)
+		return name
+	})
+	return exitUnknown, nil
+}
+
+// solveInstances solves the instances with the given number of workers and
+// returns their results in the same order.
+func solveInstances(cfg *config, instances []string, workers int) []benchResult {
+	results := make([]benchResult, len(instances))
+	jobs := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = benchInstance(cfg, instances[i])
+			}
+		}()
+	}
+	for i := range instances {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// printBenchResults prints a table of the results, where instances are named
+// with nameOf, followed by the number of solved instances and the PAR-2 score.
+func printBenchResults(cfg *config, results []benchResult, nameOf func(string) string) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "instance\tstatus\ttime (sec)\tconflicts\t\n")
+	nSAT, nUNSAT := 0, 0
+	par2 := 0.0
+	for _, r := range results {
+		name := nameOf(r.instance)
+		if r.err != nil {
+			fmt.Fprintf(tw, "%s\tERROR\t\t\t\n", name)
+			fmt.Fprintf(os.Stderr, "%s: %s\n", r.instance, r.err)
+		} else {
+			fmt.Fprintf(tw, "%s\t%s\t%.3f\t%d\t\n", name, statusName(r.status), r.duration.Seconds(), r.conflicts)
+		}
+
+		switch {
+		case r.err == nil && r.status == sat.True:
+			nSAT++
+			par2 += r.duration.Seconds()
+		case r.err == nil && r.status == sat.False:
+			nUNSAT++
+			par2 += r.duration.Seconds()
+		default:
+			par2 += 2 * cfg.timeout.Seconds()
+		}
+	}
+	tw.Flush()
+
+	fmt.Printf("\n")
+	fmt.Printf("solved:  %d/%d (%d SAT, %d UNSAT)\n", nSAT+nUNSAT, len(results), nSAT, nUNSAT)
+	switch {
+	case cfg.timeout <= 0:
+		fmt.Printf("PAR-2:   n/a (requires -timeout)\n")
+	case len(results) == 0:
+		fmt.Printf("PAR-2:   n/a (no instance)\n")
+	default:
+		fmt.Printf("PAR-2:   %.3f sec\n", par2/float64(len(results)))
+	}
+}
+
+// benchInstance solves the instance with the configured solver options.
+func benchInstance(cfg *config, instance string) benchResult {
+	instCfg := *cfg
+	instCfg.instanceFile = instance
+	instCfg.verbosity = 0
+
+	res := benchResult{instance: instance}
+	s, err := sat.NewSolver(sat.WithOptions(solverOptions(&instCfg)))
+	if err != nil {
+		res.err = err
+		return res
+	}
+	if err := loadInstance(&instCfg, s); err != nil {
+		res.err = err
+		return res
+	}
+
+	tStart := time.Now()
+	result := s.Solve()
+	res.duration = time.Since(tStart)
+	res.status = result.Status
+	res.conflicts = result.Statistics.Conflicts
+	return res
+}
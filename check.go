@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rhartert/yass/parsers"
+	"github.com/rhartert/yass/sat"
+)
+
+// runCheck verifies that the model printed by a SAT solver (e.g. the output
+// of the solve command) satisfies every clause of the instance. Unsatisfiable
+// claims cannot be checked without a proof and are rejected.
+func runCheck(cfg *config) (int, error) {
+	if len(cfg.args) != 2 {
+		return exitUnknown, fmt.Errorf("check requires an instance and a model file")
+	}
+
+	cnf := &parsers.CNF{}
+	if err := loadInstance(cfg, cnf); err != nil {
+		return exitUnknown, fmt.Errorf("could not load instance: %s", err)
+	}
+
+	f, err := os.Open(cfg.args[1])
+	if err != nil {
+		return exitUnknown, fmt.Errorf("could not read model: %s", err)
+	}
+	defer f.Close()
+	sol, err := parsers.ReadSolution(f)
+	if err != nil {
+		return exitUnknown, fmt.Errorf("could not read model: %s", err)
+	}
+	if sol.Status != "" && sol.Status != "SATISFIABLE" {
+		return exitUnknown, fmt.Errorf("cannot check status %s: only models can be checked", sol.Status)
+	}
+	model, unassigned, err := sol.Model(cnf.NumVars)
+	if err != nil {
+		return exitUnknown, fmt.Errorf("invalid model: %s", err)
+	}
+
+	if unassigned > 0 {
+		fmt.Printf("c unassigned:   %d variables (considered false)\n", unassigned)
+	}
+	if i := sat.VerifyModel(cnf.Clauses, model); i >= 0 {
+		return exitUnknown, fmt.Errorf("model violates clause %d: %s 0", i+1, formatLiterals(sat.LiteralsToDIMACS(cnf.Clauses[i])))
+	}
+	fmt.Printf("c model satisfies all %d clauses\n", len(cnf.Clauses))
+	return exitSatisfiable, nil
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// command is a subcommand of the CLI, e.g. "yass solve instance.cnf".
+type command struct {
+	name    string
+	args    string // usage of the positional arguments
+	summary string
+
+	// Names of the flags accepted by the command. Flags are declared once on
+	// the default flag set and shared by the commands that accept them.
+	flags []string
+
+	run func(cfg *config) (int, error)
+}
+
+// Flags shared by the commands reading an instance.
+var inputFlags = []string{
+	"gzip",
+	"compression",
+	"strict",
+	"mmap",
+}
+
+// Flags shared by the commands running the solver.
+var solverFlags = []string{
+	"max_conflicts",
+	"timeout",
+	"max_memory",
+	"preset",
+	"phase",
+	"polarity",
+	"pool_learnts",
+	"learning",
+	"reason_bump",
+	"binary_strengthen",
+	"probe",
+	"ternary",
+	"seed",
+	"random_freq",
+	"cpuprof",
+	"memprof",
+	"memprof_every",
+	"memprof_reduce",
+}
+
+// Flags controlling the solver's logs.
+var logFlags = []string{
+	"verbose",
+	"quiet",
+	"stats_log",
+	"stats_interval",
+}
+
+func flagNames(groups ...[]string) []string {
+	names := []string{}
+	for _, g := range groups {
+		names = append(names, g...)
+	}
+	return names
+}
+
+// commands are the CLI's subcommands. The first one is the default command,
+// run when no command is given.
+var commands = []*command{
+	{
+		name:    "solve",
+		args:    "[instance...]",
+		summary: "decide the satisfiability of the instance",
+		flags: flagNames(inputFlags, solverFlags, logFlags, []string{
+			"json",
+			"print_model",
+			"print_names",
+			"model_out",
+			"models_out",
+			"all_models",
+			"minimize",
+			"max_models",
+			"assume",
+			"assume_file",
+			"proof",
+			"proof_format",
+			"learnts_out",
+			"learnts_max_lbd",
+			"conflict_graphs",
+			"conflict_graphs_max",
+			"conflict_trace",
+			"conflict_trace_max",
+			"debug_addr",
+			"parallel",
+		}),
+		run: runSolve,
+	},
+	{
+		name:    "count",
+		args:    "[instance]",
+		summary: "count the models of the instance",
+		flags:   flagNames(inputFlags, solverFlags, []string{"max_models"}),
+		run:     runCount,
+	},
+	{
+		name:    "compile",
+		args:    "[instance]",
+		summary: "compile the instance into a Decision-DNNF printed in the c2d NNF format",
+		flags:   flagNames(inputFlags, []string{"timeout", "max_nodes"}),
+		run:     runCompile,
+	},
+	{
+		name:    "obdd",
+		args:    "[instance]",
+		summary: "print the OBDD of the instance projected onto a few variables, in DOT format",
+		flags:   flagNames(inputFlags, solverFlags, []string{"project"}),
+		run:     runOBDD,
+	},
+	{
+		name:    "check",
+		args:    "instance model",
+		summary: "verify that a model printed by a SAT solver satisfies the instance",
+		flags:   inputFlags,
+		run:     runCheck,
+	},
+	{
+		name:    "equiv",
+		args:    "instance1 instance2",
+		summary: "check whether two instances are equisatisfiable and equivalent",
+		flags:   flagNames(inputFlags, solverFlags, []string{"var_map"}),
+		run:     runEquiv,
+	},
+	{
+		name:    "simplify",
+		args:    "[instance]",
+		summary: "print the instance simplified with its root-level implications",
+		flags:   inputFlags,
+		run:     runSimplify,
+	},
+	{
+		name:    "mus",
+		args:    "[instance]",
+		summary: "print a minimal unsatisfiable subset of the instance's clauses",
+		flags:   flagNames(inputFlags, solverFlags),
+		run:     runMUS,
+	},
+	{
+		name:    "bench",
+		args:    "directory",
+		summary: "solve the .cnf instances of a directory and summarize the results",
+		flags:   flagNames(inputFlags, solverFlags, []string{"workers"}),
+		run:     runBench,
+	},
+	{
+		name:    "serve",
+		args:    "",
+		summary: "serve the solver over HTTP: POST an instance to /solve to get its JSON result",
+		flags:   flagNames([]string{"compression", "strict", "listen", "workers"}, solverFlags),
+		run:     runServe,
+	},
+	{
+		name:    "gen",
+		args:    "family [family flags]",
+		summary: "write an instance of a family (" + generatorNames() + ") to stdout",
+		flags:   []string{"seed"},
+		run:     runGen,
+	},
+	{
+		name:    "scramble",
+		args:    "[instance]",
+		summary: "write an equivalent instance with shuffled variables, polarities, and clauses",
+		flags:   flagNames(inputFlags, []string{"seed", "map_out"}),
+		run:     runScramble,
+	},
+	{
+		name:    "fuzz",
+		args:    "",
+		summary: "cross-check solver configurations on random instances",
+		flags:   flagNames(solverFlags, []string{"iterations"}),
+		run:     runFuzz,
+	},
+	{
+		name:    "shrink",
+		args:    "instance command [args...]",
+		summary: "minimize an instance while a command keeps exiting with the same code",
+		flags:   flagNames(inputFlags, []string{"out", "predicate_timeout"}),
+		run:     runShrink,
+	},
+}
+
+// findCommand returns the command with the given name, or nil if there is no
+// such command.
+func findCommand(name string) *command {
+	for _, c := range commands {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// flagSet returns a flag set containing the flags accepted by the command.
+func (c *command) flagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet(c.name, flag.ExitOnError)
+	for _, name := range c.flags {
+		f := flag.Lookup(name)
+		fs.Var(f.Value, f.Name, f.Usage)
+	}
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintf(out, "usage: yass %s [flags] %s\n\n", c.name, c.args)
+		fmt.Fprintf(out, "Command %s: %s.\n", c.name, c.summary)
+		if c == commands[0] {
+			fmt.Fprintf(out, "This is the default command when no command is given.\n")
+		}
+		fmt.Fprintf(out, "\nCommands:\n")
+		for _, cmd := range commands {
+			fmt.Fprintf(out, "  %-10s %s\n", cmd.name, cmd.summary)
+		}
+		fmt.Fprintf(out, "\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseCommand returns the command designated by the program's arguments and
+// parses its flags.
+func parseCommand(args []string) (*command, *flag.FlagSet) {
+	cmd := commands[0]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		if c := findCommand(args[0]); c != nil {
+			cmd = c
+			args = args[1:]
+		}
+	}
+	fs := cmd.flagSet()
+	fs.Parse(args)
+	return cmd, fs
+}
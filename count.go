@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rhartert/yass/sat"
+)
+
+// runCount counts the models of the instance by enumerating them. The count is
+// printed in the model counting competition format ("s mc <count>") if all the
+// models were enumerated.
+func runCount(cfg *config) (int, error) {
+	cfg.verbosity = 0 // logs would be repeated for each model
+	s, err := sat.NewSolver(sat.WithOptions(solverOptions(cfg)))
+	if err != nil {
+		return exitUnknown, fmt.Errorf("invalid solver configuration: %s", err)
+	}
+	defer interruptOnSignal(s)()
+	defer diagnoseOnSignal(s)()
+	if err := loadInstance(cfg, s); err != nil {
+		return exitUnknown, fmt.Errorf("could not load instance: %s", err)
+	}
+
+	tStart := time.Now()
+	e := s.EnumerateModels(sat.EnumerateOptions{
+		MaxModels: cfg.maxModels,
+		Timeout:   cfg.timeout,
+	})
+	found := e.Models
+
+	fmt.Printf("c count time:   %.3f sec\n", time.Since(tStart).Seconds())
+	fmt.Printf("c models:       %d\n", found)
+	if !e.Exhaustive {
+		fmt.Printf("c stopped:      %s\n", e.StopReason)
+		fmt.Printf("c count is a lower bound\n")
+		fmt.Printf("s UNKNOWN\n")
+		return exitUnknown, nil
+	}
+	fmt.Printf("s mc %d\n", found)
+	if found == 0 {
+		return exitUnsatisfiable, nil
+	}
+	return exitSatisfiable, nil
+}
@@ -0,0 +1,429 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rhartert/yass/parsers"
+	"github.com/rhartert/yass/sat"
+)
+
+var flagMaxNodes = flag.Int(
+	"max_nodes",
+	-1,
+	"maximum number of nodes created by the compilation (-1 = no maximum)",
+)
+
+// errCompileTimeout and errCompileNodeLimit are returned when the compilation
+// exceeds its budget (see compileDDNNF).
+var (
+	errCompileTimeout   = errors.New("timeout")
+	errCompileNodeLimit = errors.New("node limit")
+)
+
+// runCompile compiles the instance into a Decision-DNNF and prints it in the
+// NNF format of the c2d compiler, preceded by "c" lines that report its size
+// and model count. The compilation is exhaustive and is thus only practical on
+// small instances or on instances that decompose well, which -timeout and
+// -max_nodes bound.
+func runCompile(cfg *config) (int, error) {
+	cnf := &parsers.CNF{}
+	if err := loadInstance(cfg, cnf); err != nil {
+		return exitUnknown, fmt.Errorf("could not load instance: %s", err)
+	}
+
+	tStart := time.Now()
+	d, err := compileDDNNF(cnf.Clauses, cfg.timeout, cfg.maxNodes)
+	fmt.Printf("c compile time: %.3f sec\n", time.Since(tStart).Seconds())
+	if errors.Is(err, errCompileTimeout) || errors.Is(err, errCompileNodeLimit) {
+		fmt.Printf("c stopped:      %s\n", err)
+		return exitUnknown, nil
+	}
+	count := d.count(cnf.NumVars)
+
+	fmt.Printf("c nodes:        %d\n", len(d.reachable()))
+	fmt.Printf("c models:       %s\n", count)
+	if err := d.write(os.Stdout, cnf.NumVars); err != nil {
+		return exitUnknown, err
+	}
+	if count.Sign() == 0 {
+		return exitUnsatisfiable, nil
+	}
+	return exitSatisfiable, nil
+}
+
+// nnfNode is a node of an NNF circuit: a literal, a conjunction, or a
+// disjunction of its children.
+type nnfNode struct {
+	kind     byte        // 'L', 'A' or 'O'
+	lit      sat.Literal // literal of 'L' nodes
+	decision int         // decision variable of 'O' nodes (-1 if none)
+	children []int
+}
+
+// ddnnf is a Decision-DNNF: an NNF circuit whose conjunctions are decomposable
+// (their children share no variable) and whose disjunctions are decisions on a
+// variable (their two children are conjunctions with the variable's literals).
+// Children are always added before their parents.
+type ddnnf struct {
+	nodes  []nnfNode
+	root   int
+	unique map[string]int // index of the nodes, by content
+}
+
+// add returns the index of the node, which is only added if there is no
+// identical node.
+func (d *ddnnf) add(n nnfNode) int {
+	key := fmt.Sprint(n.kind, n.lit, n.decision, n.children)
+	if i, ok := d.unique[key]; ok {
+		return i
+	}
+	d.nodes = append(d.nodes, n)
+	d.unique[key] = len(d.nodes) - 1
+	return len(d.nodes) - 1
+}
+
+func (d *ddnnf) isTrue(i int) bool {
+	return d.nodes[i].kind == 'A' && len(d.nodes[i].children) == 0
+}
+
+func (d *ddnnf) isFalse(i int) bool {
+	return d.nodes[i].kind == 'O' && len(d.nodes[i].children) == 0
+}
+
+func (d *ddnnf) literal(l sat.Literal) int {
+	return d.add(nnfNode{kind: 'L', lit: l, decision: -1})
+}
+
+func (d *ddnnf) constant(v bool) int {
+	if v {
+		return d.add(nnfNode{kind: 'A', decision: -1})
+	}
+	return d.add(nnfNode{kind: 'O', decision: -1})
+}
+
+// and returns the conjunction of the children, which must not share variables.
+func (d *ddnnf) and(children []int) int {
+	kept := []int{}
+	for _, c := range children {
+		if d.isFalse(c) {
+			return c
+		}
+		if !d.isTrue(c) {
+			kept = append(kept, c)
+		}
+	}
+	if len(kept) == 1 {
+		return kept[0]
+	}
+	return d.add(nnfNode{kind: 'A', decision: -1, children: kept})
+}
+
+// decide returns the disjunction of pos and neg, which are respectively
+// conjunctions with the positive and negative literal of variable v.
+func (d *ddnnf) decide(v int, pos, neg int) int {
+	switch {
+	case d.isFalse(pos):
+		return neg
+	case d.isFalse(neg):
+		return pos
+	}
+	return d.add(nnfNode{kind: 'O', decision: v, children: []int{pos, neg}})
+}
+
+// reachable returns the indices of the nodes reachable from the root, in
+// increasing order.
+func (d *ddnnf) reachable() []int {
+	seen := make([]bool, len(d.nodes))
+	seen[d.root] = true
+	for i := d.root; i >= 0; i-- {
+		if seen[i] {
+			for _, c := range d.nodes[i].children {
+				seen[c] = true
+			}
+		}
+	}
+	nodes := []int{}
+	for i, ok := range seen {
+		if ok {
+			nodes = append(nodes, i)
+		}
+	}
+	return nodes
+}
+
+// write writes the circuit's reachable nodes in the NNF format of c2d.
+func (d *ddnnf) write(w io.Writer, numVars int) error {
+	nodes := d.reachable()
+	id := make([]int, len(d.nodes))
+	edges := 0
+	for i, n := range nodes {
+		id[n] = i
+		edges += len(d.nodes[n].children)
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "nnf %d %d %d\n", len(nodes), edges, numVars)
+	for _, i := range nodes {
+		n := d.nodes[i]
+		switch n.kind {
+		case 'L':
+			fmt.Fprintf(bw, "L %d\n", n.lit.ToDIMACS())
+			continue
+		case 'A':
+			fmt.Fprintf(bw, "A %d", len(n.children))
+		case 'O':
+			fmt.Fprintf(bw, "O %d %d", n.decision+1, len(n.children))
+		}
+		for _, c := range n.children {
+			fmt.Fprintf(bw, " %d", id[c])
+		}
+		fmt.Fprintln(bw)
+	}
+	return bw.Flush()
+}
+
+// count returns the number of models of the circuit over numVars variables.
+// Variables that do not appear in a child of a disjunction are free in that
+// child, which multiplies its count.
+func (d *ddnnf) count(numVars int) *big.Int {
+	counts := make([]*big.Int, len(d.nodes))
+	vars := make([][]int, len(d.nodes)) // sorted variables of each node
+	for i, n := range d.nodes {
+		switch n.kind {
+		case 'L':
+			counts[i] = big.NewInt(1)
+			vars[i] = []int{n.lit.VarID()}
+		case 'A':
+			counts[i] = big.NewInt(1)
+			for _, c := range n.children {
+				counts[i].Mul(counts[i], counts[c])
+				vars[i] = append(vars[i], vars[c]...)
+			}
+			slices.Sort(vars[i])
+		case 'O':
+			counts[i] = big.NewInt(0)
+			for _, c := range n.children {
+				vars[i] = append(vars[i], vars[c]...)
+			}
+			slices.Sort(vars[i])
+			vars[i] = slices.Compact(vars[i])
+			for _, c := range n.children {
+				free := new(big.Int).Lsh(big.NewInt(1), uint(len(vars[i])-len(vars[c])))
+				counts[i].Add(counts[i], free.Mul(free, counts[c]))
+			}
+		}
+	}
+	free := new(big.Int).Lsh(big.NewInt(1), uint(numVars-len(vars[d.root])))
+	return free.Mul(free, counts[d.root])
+}
+
+// compileDDNNF compiles the clauses into a Decision-DNNF with an exhaustive
+// DPLL search: the formula is simplified by unit propagation and split into
+// components that share no variable, which are compiled independently. Each
+// component is compiled by deciding on its most frequent variable, and is
+// cached so that it is compiled only once.
+//
+// The compilation stops with errCompileTimeout once it ran for timeout, or
+// with errCompileNodeLimit once the circuit has more than maxNodes nodes (a
+// negative value disables the corresponding limit).
+//
+// The search does not reuse the solver, whose model enumeration (see runCount)
+// visits the models one by one: compiling requires to decompose the formula
+// into components and to cache them, which the solver's watched clauses cannot
+// do. The clauses are thus conditioned by copying them, which is only
+// affordable on the small instances that can be compiled anyway.
+func compileDDNNF(clauses [][]sat.Literal, timeout time.Duration, maxNodes int) (*ddnnf, error) {
+	c := &ddnnfCompiler{
+		d:        &ddnnf{unique: map[string]int{}},
+		cache:    map[string]int{},
+		maxNodes: maxNodes,
+	}
+	if timeout >= 0 {
+		c.deadline = time.Now().Add(timeout)
+	}
+	c.d.root = c.compile(normalize(clauses))
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.d, nil
+}
+
+// normalize returns the clauses without duplicate literals nor tautologies.
+func normalize(clauses [][]sat.Literal) [][]sat.Literal {
+	normalized := make([][]sat.Literal, 0, len(clauses))
+	for _, cl := range clauses {
+		cl = slices.Clone(cl)
+		slices.Sort(cl)
+		cl = slices.Compact(cl)
+		tautology := false
+		for i := 1; i < len(cl); i++ {
+			tautology = tautology || cl[i] == cl[i-1].Opposite()
+		}
+		if !tautology {
+			normalized = append(normalized, cl)
+		}
+	}
+	return normalized
+}
+
+type ddnnfCompiler struct {
+	d     *ddnnf
+	cache map[string]int // compiled components, by content
+
+	deadline time.Time // zero if none
+	maxNodes int       // negative if none
+	err      error     // set once the budget is exhausted
+}
+
+// exhausted returns true if the compilation exceeded its budget, in which case
+// c.err is set and the circuit is incomplete.
+func (c *ddnnfCompiler) exhausted() bool {
+	switch {
+	case c.err != nil:
+	case c.maxNodes >= 0 && len(c.d.nodes) > c.maxNodes:
+		c.err = errCompileNodeLimit
+	case !c.deadline.IsZero() && time.Now().After(c.deadline):
+		c.err = errCompileTimeout
+	}
+	return c.err != nil
+}
+
+// compile returns the node of the formula made of the clauses.
+func (c *ddnnfCompiler) compile(clauses [][]sat.Literal) int {
+	units, clauses, ok := propagate(clauses)
+	if !ok {
+		return c.d.constant(false)
+	}
+	children := []int{}
+	for _, l := range units {
+		children = append(children, c.d.literal(l))
+	}
+	for _, comp := range components(clauses) {
+		children = append(children, c.compileComponent(comp))
+	}
+	return c.d.and(children)
+}
+
+// compileComponent returns the node of the component made of the clauses,
+// which contain no unit clause.
+func (c *ddnnfCompiler) compileComponent(clauses [][]sat.Literal) int {
+	if c.exhausted() {
+		return c.d.constant(false)
+	}
+	key := componentKey(clauses)
+	if n, ok := c.cache[key]; ok {
+		return n
+	}
+
+	occurs := map[int]int{}
+	v := -1
+	for _, cl := range clauses {
+		for _, l := range cl {
+			u := l.VarID()
+			occurs[u]++
+			if v < 0 || occurs[u] > occurs[v] || (occurs[u] == occurs[v] && u < v) {
+				v = u
+			}
+		}
+	}
+	pos := sat.PositiveLiteral(v)
+	neg := sat.NegativeLiteral(v)
+	n := c.d.decide(v,
+		c.d.and([]int{c.d.literal(pos), c.compile(condition(clauses, pos))}),
+		c.d.and([]int{c.d.literal(neg), c.compile(condition(clauses, neg))}),
+	)
+	c.cache[key] = n
+	return n
+}
+
+// condition returns the clauses simplified with literal l set to true.
+func condition(clauses [][]sat.Literal, l sat.Literal) [][]sat.Literal {
+	conditioned := make([][]sat.Literal, 0, len(clauses))
+	for _, cl := range clauses {
+		if slices.Contains(cl, l) {
+			continue
+		}
+		if i := slices.Index(cl, l.Opposite()); i >= 0 {
+			cl = slices.Delete(slices.Clone(cl), i, i+1)
+		}
+		conditioned = append(conditioned, cl)
+	}
+	return conditioned
+}
+
+// propagate applies unit propagation to the clauses. It returns the implied
+// literals and the simplified clauses, or false if propagation falsifies a
+// clause.
+func propagate(clauses [][]sat.Literal) ([]sat.Literal, [][]sat.Literal, bool) {
+	units := []sat.Literal{}
+	for {
+		i := slices.IndexFunc(clauses, func(cl []sat.Literal) bool { return len(cl) <= 1 })
+		if i < 0 {
+			return units, clauses, true
+		}
+		if len(clauses[i]) == 0 {
+			return nil, nil, false
+		}
+		l := clauses[i][0]
+		units = append(units, l)
+		clauses = condition(clauses, l)
+	}
+}
+
+// components splits the clauses into groups that share no variable.
+func components(clauses [][]sat.Literal) [][][]sat.Literal {
+	parent := map[int]int{}
+	var find func(v int) int
+	find = func(v int) int {
+		p, ok := parent[v]
+		if !ok || p == v {
+			return v
+		}
+		parent[v] = find(p)
+		return parent[v]
+	}
+	for _, cl := range clauses {
+		r := find(cl[0].VarID())
+		for _, l := range cl[1:] {
+			if u := find(l.VarID()); u != r {
+				parent[u] = r
+			}
+		}
+	}
+
+	index := map[int]int{} // index of the component of each root variable
+	comps := [][][]sat.Literal{}
+	for _, cl := range clauses {
+		r := find(cl[0].VarID())
+		i, ok := index[r]
+		if !ok {
+			i = len(comps)
+			index[r] = i
+			comps = append(comps, nil)
+		}
+		comps[i] = append(comps[i], cl)
+	}
+	return comps
+}
+
+// componentKey returns a key that identifies the component regardless of the
+// order of its clauses and of their literals.
+func componentKey(clauses [][]sat.Literal) string {
+	keys := make([]string, len(clauses))
+	for i, cl := range clauses {
+		sorted := slices.Clone(cl)
+		slices.Sort(sorted)
+		keys[i] = fmt.Sprint(sorted)
+	}
+	slices.Sort(keys)
+	return strings.Join(keys, "")
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"sync"
+
+	"github.com/rhartert/yass/sat"
+)
+
+// debugServer serves the pprof handlers and the latest progress of the search
+// as JSON on /debug/stats.
+type debugServer struct {
+	server *http.Server
+
+	mu       sync.Mutex
+	progress sat.Progress
+}
+
+// startDebugServer starts serving the debug endpoints on the given address.
+// The handlers are registered on a dedicated mux (rather than the default one)
+// so that several servers can be started by the same process. The server must
+// be closed once the run ends.
+func startDebugServer(addr string) (*debugServer, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	ds := &debugServer{server: &http.Server{Handler: mux}}
+	mux.HandleFunc("/debug/stats", ds.serveStats)
+	go ds.server.Serve(ln)
+	return ds, nil
+}
+
+// close stops the server and closes its connections.
+func (ds *debugServer) close() error {
+	return ds.server.Close()
+}
+
+// update records the progress of the search. It is called from the solver's
+// goroutine while the handlers read the progress from the server's ones.
+func (ds *debugServer) update(p sat.Progress) {
+	ds.mu.Lock()
+	ds.progress = p
+	ds.mu.Unlock()
+}
+
+func (ds *debugServer) serveStats(w http.ResponseWriter, _ *http.Request) {
+	ds.mu.Lock()
+	p := ds.progress
+	ds.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		Time         float64 `json:"time_sec"`
+		Conflicts    uint64  `json:"conflicts"`
+		Propagations uint64  `json:"propagations"`
+		Decisions    uint64  `json:"decisions"`
+		Restarts     uint64  `json:"restarts"`
+		Learnts      int     `json:"learnts"`
+		CoreLearnts  int     `json:"core_learnts"`
+		AvgCoreLBD   float64 `json:"avg_core_lbd"`
+		AvgLearntLBD float64 `json:"avg_learnt_lbd"`
+	}{
+		Time:         p.Time.Seconds(),
+		Conflicts:    p.Conflicts,
+		Propagations: p.Propagations,
+		Decisions:    p.Decisions,
+		Restarts:     p.Restarts,
+		Learnts:      p.Learnts,
+		CoreLearnts:  p.CoreLearnts,
+		AvgCoreLBD:   p.AvgCoreLBD,
+		AvgLearntLBD: p.AvgLearntLBD,
+	})
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/rhartert/yass/parsers"
+	"github.com/rhartert/yass/sat"
+)
+
+var flagVarMap = flag.String(
+	"var_map",
+	"",
+	`file mapping the variables of the second instance to the first one's, as lines "x y" stating that literal y of the second instance is literal x of the first one (default: same variable ids)`,
+)
+
+// runEquiv checks whether two instances are equisatisfiable and equivalent,
+// e.g. to validate a preprocessor or an encoder. The instances are equivalent
+// if they have the same models over the shared variables, which is decided
+// with a miter: a formula satisfiable iff exactly one of the instances is
+// satisfied by an assignment (a counterexample). Shared variables are given
+// by -var_map, the other variables of the second instance being distinct from
+// the first one's. Note that every variable is treated as an input of the
+// formulas: instances with different auxiliary variables (e.g. introduced by
+// a Tseitin encoding) may be equisatisfiable without being equivalent.
+//
+// Following the miter's status, the exit code is 20 if the instances are
+// equivalent and 10 if a counterexample is found.
+func runEquiv(cfg *config) (int, error) {
+	if len(cfg.args) != 2 {
+		return exitUnknown, fmt.Errorf("equiv requires two instances")
+	}
+	cnfs := [2]*parsers.CNF{}
+	for i, instance := range cfg.args {
+		instCfg := *cfg
+		instCfg.instanceFile = instance
+		cnfs[i] = &parsers.CNF{}
+		if err := loadInstance(&instCfg, cnfs[i]); err != nil {
+			return exitUnknown, fmt.Errorf("could not load instance %s: %s", instance, err)
+		}
+	}
+	a, b := cnfs[0], cnfs[1]
+
+	mapping, err := identityMapping(a, b)
+	if cfg.varMap != "" {
+		mapping, err = readVarMap(cfg.varMap, a, b)
+	}
+	if err != nil {
+		return exitUnknown, fmt.Errorf("invalid variable mapping: %s", err)
+	}
+
+	cfg.verbosity = 0 // logs would be repeated for each call to the solver
+	statuses := [2]sat.LBool{}
+	for i, cnf := range cnfs {
+		s, err := sat.NewSolver(sat.WithOptions(solverOptions(cfg)))
+		if err != nil {
+			return exitUnknown, fmt.Errorf("invalid solver configuration: %s", err)
+		}
+		if err := cnf.Load(s); err != nil {
+			return exitUnknown, fmt.Errorf("could not load instance %s: %s", cfg.args[i], err)
+		}
+		statuses[i] = s.Solve().Status
+		fmt.Printf("c %s: %s\n", cfg.args[i], statusName(statuses[i]))
+	}
+	// Instances are known not to be equisatisfiable only if both statuses are
+	// known, in which case they are not equivalent either.
+	notEquisat := false
+	switch {
+	case statuses[0] == sat.Unknown || statuses[1] == sat.Unknown:
+		fmt.Printf("c equisatisfiable: unknown\n")
+	case statuses[0] != statuses[1]:
+		fmt.Printf("c equisatisfiable: no\n")
+		notEquisat = true
+	default:
+		fmt.Printf("c equisatisfiable: yes\n")
+	}
+
+	s, err := sat.NewSolver(sat.WithOptions(solverOptions(cfg)))
+	if err != nil {
+		return exitUnknown, fmt.Errorf("invalid solver configuration: %s", err)
+	}
+	defer interruptOnSignal(s)()
+	for i := 0; i < a.NumVars; i++ {
+		s.AddVariable()
+	}
+	for v := 0; v < b.NumVars; v++ {
+		if _, ok := mapping[v]; !ok {
+			mapping[v] = sat.PositiveLiteral(s.AddVariable())
+		}
+	}
+	outA, err := encodeFormula(s, a.Clauses, func(l sat.Literal) sat.Literal { return l })
+	if err != nil {
+		return exitUnknown, fmt.Errorf("could not encode the miter: %s", err)
+	}
+	outB, err := encodeFormula(s, b.Clauses, func(l sat.Literal) sat.Literal {
+		if l.IsPositive() {
+			return mapping[l.VarID()]
+		}
+		return mapping[l.VarID()].Opposite()
+	})
+	if err != nil {
+		return exitUnknown, fmt.Errorf("could not encode the miter: %s", err)
+	}
+	err = s.AddClauses([][]sat.Literal{
+		{outA, outB},
+		{outA.Opposite(), outB.Opposite()},
+	})
+	if err != nil {
+		return exitUnknown, fmt.Errorf("could not encode the miter: %s", err)
+	}
+
+	switch s.Solve().Status {
+	case sat.False:
+		fmt.Printf("s EQUIVALENT\n")
+		return exitUnsatisfiable, nil
+	case sat.True:
+		model := s.Model()
+		satisfied := cfg.args[0]
+		if !model[outA.VarID()] {
+			satisfied = cfg.args[1]
+		}
+		fmt.Printf("c counterexample only satisfies %s\n", satisfied)
+		fmt.Printf("s NOT EQUIVALENT\n")
+		printModel(os.Stdout, model[:a.NumVars])
+		return exitSatisfiable, nil
+	default:
+		if notEquisat {
+			fmt.Printf("s NOT EQUIVALENT\n")
+		} else {
+			fmt.Printf("s UNKNOWN\n")
+		}
+		return exitUnknown, nil
+	}
+}
+
+// encodeFormula adds variables and clauses to the solver such that the
+// returned literal is true iff all the clauses are satisfied, with the
+// literals of the clauses renamed by rename. Each clause c_i is associated with
+// a literal f_i implying that c_i is falsified:
+//
+//	out => c_i   for each clause c_i
+//	f_i => ¬l    for each literal l of c_i
+//	¬out => f_1 ∨ ... ∨ f_m
+//
+// It returns the first error returned by the solver when adding the clauses.
+func encodeFormula(s *sat.Solver, clauses [][]sat.Literal, rename func(sat.Literal) sat.Literal) (sat.Literal, error) {
+	out := sat.PositiveLiteral(s.AddVariable())
+	falsified := []sat.Literal{out}
+	tmp := []sat.Literal{}
+	for _, c := range clauses {
+		f := sat.PositiveLiteral(s.AddVariable())
+		falsified = append(falsified, f)
+
+		tmp = append(tmp[:0], out.Opposite())
+		for _, l := range c {
+			tmp = append(tmp, rename(l))
+		}
+		if err := s.AddClause(tmp); err != nil {
+			return out, err
+		}
+		for _, l := range c {
+			if err := s.AddClause([]sat.Literal{f.Opposite(), rename(l).Opposite()}); err != nil {
+				return out, err
+			}
+		}
+	}
+	return out, s.AddClause(falsified)
+}
+
+// identityMapping maps each variable of b to the variable of a with the same
+// id, if any.
+func identityMapping(a, b *parsers.CNF) (map[int]sat.Literal, error) {
+	mapping := map[int]sat.Literal{}
+	for v := 0; v < min(a.NumVars, b.NumVars); v++ {
+		mapping[v] = sat.PositiveLiteral(v)
+	}
+	return mapping, nil
+}
+
+// readVarMap reads the mapping of the variables of b to the literals of a from
+// the file (see -var_map).
+func readVarMap(filename string, a, b *parsers.CNF) (map[int]sat.Literal, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	mapping := map[int]sat.Literal{}
+	sc := bufio.NewScanner(f)
+	for lineNum := 1; sc.Scan(); lineNum++ {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 || fields[0] == "c" {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: want 2 literals, got %d fields", lineNum, len(fields))
+		}
+		x, errX := strconv.Atoi(fields[0])
+		y, errY := strconv.Atoi(fields[1])
+		switch {
+		case errX != nil || errY != nil || x == 0 || y == 0:
+			return nil, fmt.Errorf("line %d: invalid literals %q", lineNum, sc.Text())
+		case abs(x) > a.NumVars:
+			return nil, fmt.Errorf("line %d: unknown variable %d in the first instance", lineNum, abs(x))
+		case abs(y) > b.NumVars:
+			return nil, fmt.Errorf("line %d: unknown variable %d in the second instance", lineNum, abs(y))
+		}
+		if y < 0 {
+			x, y = -x, -y
+		}
+		mapping[y-1] = sat.LiteralFromDIMACS(x)
+	}
+	return mapping, sc.Err()
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
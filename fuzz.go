@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/rhartert/yass/gen"
+	"github.com/rhartert/yass/parsers"
+	"github.com/rhartert/yass/sat"
+)
+
+var flagIterations = flag.Int(
+	"iterations",
+	1000,
+	"number of instances generated by the fuzz command",
+)
+
+// fuzzConfigs are the solver configurations cross-checked by the fuzz command.
+// They are applied on top of the configured solver options.
+var fuzzConfigs = []struct {
+	name  string
+	apply func(*sat.Options)
+}{
+	{"default", func(*sat.Options) {}},
+	{"phase+random", func(ops *sat.Options) {
+		ops.PhaseSaving = true
+		ops.RandomDecisionFreq = 0.05
+		ops.Seed++
+	}},
+}
+
+// runFuzz generates random instances, solves them with each of fuzzConfigs,
+// and reports discrepancies: different answers, models violating a clause, or
+// unsatisfiability proofs that cannot be checked. Instances that reveal a
+// discrepancy are saved in the current directory.
+func runFuzz(cfg *config) (int, error) {
+	rng := rand.New(rand.NewSource(cfg.seed))
+	failures := 0
+	for i := 0; i < cfg.iterations; i++ {
+		var cnf *parsers.CNF
+		switch rng.Intn(10) {
+		case 0, 1:
+			cnf = gen.Pigeonhole(2 + rng.Intn(4))
+		case 2:
+			cnf = gen.Parity(rng, 2+rng.Intn(8))
+		case 3:
+			nNodes := 5 + rng.Intn(16)
+			edges := gen.RandomGraph(rng, nNodes, rng.Intn(nNodes*(nNodes-1)/4+1))
+			cnf = gen.GraphColoring(nNodes, edges, 2+rng.Intn(3))
+		default:
+			nVars := 5 + rng.Intn(36)
+			ratio := gen.Threshold3SAT + (rng.Float64()-0.5)*1.5
+			cnf = gen.RandomKSATRatio(rng, 3, nVars, ratio)
+		}
+
+		err := fuzzInstance(cfg, cnf)
+		if err == nil {
+			continue
+		}
+		failures++
+		filename := fmt.Sprintf("fuzz-%d-%d.cnf", cfg.seed, i)
+		fmt.Printf("instance %d: %s (saved in %s)\n", i, err, filename)
+		if err := saveCNF(filename, cnf); err != nil {
+			return exitUnknown, err
+		}
+	}
+
+	fmt.Printf("instances:     %d\n", cfg.iterations)
+	fmt.Printf("discrepancies: %d\n", failures)
+	if failures > 0 {
+		return exitUnknown, fmt.Errorf("found %d discrepancies", failures)
+	}
+	return exitUnknown, nil
+}
+
+// fuzzInstance solves the instance with each configuration and returns an
+// error describing the first discrepancy found, if any.
+func fuzzInstance(cfg *config, cnf *parsers.CNF) error {
+	statuses := make([]sat.LBool, len(fuzzConfigs))
+	for i, fc := range fuzzConfigs {
+		proof := &bytes.Buffer{}
+		ops := solverOptions(cfg)
+		ops.Logger = nil
+		ops.Proof = proof
+		ops.ProofFormat = sat.ProofText
+		fc.apply(&ops)
+
+		s, err := sat.NewSolver(sat.WithOptions(ops))
+		if err != nil {
+			return err
+		}
+		if err := cnf.Load(s); err != nil {
+			return err
+		}
+
+		statuses[i] = s.Solve().Status
+		switch statuses[i] {
+		case sat.True:
+			model := s.Model()
+			if c := sat.VerifyModel(cnf.Clauses, model); c >= 0 {
+				return fmt.Errorf("config %s: model violates clause %d", fc.name, c+1)
+			}
+		case sat.False:
+			if err := checkDRAT(cnf, proof.String()); err != nil {
+				return fmt.Errorf("config %s: invalid proof: %s", fc.name, err)
+			}
+		}
+	}
+
+	for i := 1; i < len(statuses); i++ {
+		if statuses[0] != sat.Unknown && statuses[i] != sat.Unknown && statuses[0] != statuses[i] {
+			return fmt.Errorf("config %s answers %s but config %s answers %s",
+				fuzzConfigs[0].name, statusName(statuses[0]),
+				fuzzConfigs[i].name, statusName(statuses[i]))
+		}
+	}
+	return nil
+}
+
+// checkDRAT checks that the textual DRAT proof refutes the formula. Each added
+// clause must be implied by unit propagation (i.e. be RUP) and the proof must
+// derive the empty clause. Deletions are ignored, which is sound, and RAT
+// clauses are not supported as the solver does not produce them. The checker
+// is naive and only meant for small formulas.
+func checkDRAT(cnf *parsers.CNF, proof string) error {
+	clauses := [][]int{}
+	for _, c := range cnf.Clauses {
+		clauses = append(clauses, sat.LiteralsToDIMACS(c))
+	}
+
+	for i, line := range strings.Split(proof, "\n") {
+		if line == "" || strings.HasPrefix(line, "d ") {
+			continue
+		}
+		clause := []int{}
+		for _, f := range strings.Fields(line) {
+			l, err := strconv.Atoi(f)
+			if err != nil {
+				return fmt.Errorf("line %d: invalid literal %q", i+1, f)
+			}
+			if l != 0 {
+				clause = append(clause, l)
+			}
+		}
+		if !isRUP(clauses, clause) {
+			return fmt.Errorf("line %d: clause %q is not implied by unit propagation", i+1, line)
+		}
+		if len(clause) == 0 {
+			return nil
+		}
+		clauses = append(clauses, clause)
+	}
+	return errors.New("the proof does not derive the empty clause")
+}
+
+// isRUP returns true if unit propagation on the clauses and the negation of the
+// candidate clause leads to a conflict.
+func isRUP(clauses [][]int, candidate []int) bool {
+	assigned := map[int]bool{}
+	for _, l := range candidate {
+		assigned[-l] = true
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, c := range clauses {
+			unassigned, free := 0, 0
+			satisfied := false
+			for _, l := range c {
+				switch {
+				case assigned[l]:
+					satisfied = true
+				case !assigned[-l]:
+					unassigned++
+					free = l
+				}
+			}
+			if satisfied {
+				continue
+			}
+			if unassigned == 0 {
+				return true
+			}
+			if unassigned == 1 {
+				assigned[free] = true
+				changed = true
+			}
+		}
+	}
+	return false
+}
+
+// saveCNF writes the formula in the DIMACS format to the file.
+func saveCNF(filename string, cnf *parsers.CNF) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := parsers.WriteDIMACS(f, cnf); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+
+	"github.com/rhartert/yass/gen"
+	"github.com/rhartert/yass/parsers"
+)
+
+// generator generates a family of instances, configured by the flags it
+// defines on the family's flag set.
+type generator struct {
+	name    string
+	summary string
+	flags   func(fs *flag.FlagSet) func(rng *rand.Rand) (*parsers.CNF, error)
+}
+
+var generators = []generator{
+	{
+		name:    "random",
+		summary: "random k-SAT formula with a given clause/variable ratio",
+		flags: func(fs *flag.FlagSet) func(*rand.Rand) (*parsers.CNF, error) {
+			k := fs.Int("k", 3, "number of literals per clause")
+			nVars := fs.Int("vars", 100, "number of variables")
+			ratio := fs.Float64("ratio", gen.Threshold3SAT, "clause/variable ratio")
+			return func(rng *rand.Rand) (*parsers.CNF, error) {
+				if *k < 1 || *k > *nVars {
+					return nil, fmt.Errorf("k must be in [1, %d], got %d", *nVars, *k)
+				}
+				if *ratio < 0 {
+					return nil, fmt.Errorf("the ratio must be positive, got %v", *ratio)
+				}
+				return gen.RandomKSATRatio(rng, *k, *nVars, *ratio), nil
+			}
+		},
+	},
+	{
+		name:    "php",
+		summary: "pigeonhole formula placing n+1 pigeons in n holes (unsatisfiable)",
+		flags: func(fs *flag.FlagSet) func(*rand.Rand) (*parsers.CNF, error) {
+			n := fs.Int("n", 10, "number of holes")
+			return func(*rand.Rand) (*parsers.CNF, error) {
+				if *n < 1 {
+					return nil, fmt.Errorf("the number of holes must be positive, got %d", *n)
+				}
+				return gen.Pigeonhole(*n), nil
+			}
+		},
+	},
+	{
+		name:    "parity",
+		summary: "XOR of n variables constrained to be both 0 and 1 (unsatisfiable)",
+		flags: func(fs *flag.FlagSet) func(*rand.Rand) (*parsers.CNF, error) {
+			n := fs.Int("n", 50, "number of variables")
+			return func(rng *rand.Rand) (*parsers.CNF, error) {
+				if *n < 1 {
+					return nil, fmt.Errorf("the number of variables must be positive, got %d", *n)
+				}
+				return gen.Parity(rng, *n), nil
+			}
+		},
+	},
+	{
+		name:    "coloring",
+		summary: "coloring of a random graph with k colors",
+		flags: func(fs *flag.FlagSet) func(*rand.Rand) (*parsers.CNF, error) {
+			nNodes := fs.Int("nodes", 50, "number of nodes")
+			nEdges := fs.Int("edges", 100, "number of edges")
+			k := fs.Int("colors", 3, "number of colors")
+			return func(rng *rand.Rand) (*parsers.CNF, error) {
+				if max := *nNodes * (*nNodes - 1) / 2; *nEdges < 0 || *nEdges > max {
+					return nil, fmt.Errorf("the number of edges must be in [0, %d], got %d", max, *nEdges)
+				}
+				if *k < 1 {
+					return nil, fmt.Errorf("the number of colors must be positive, got %d", *k)
+				}
+				edges := gen.RandomGraph(rng, *nNodes, *nEdges)
+				return gen.GraphColoring(*nNodes, edges, *k), nil
+			}
+		},
+	},
+}
+
+// runGen writes an instance of the family given as first argument to stdout,
+// in the DIMACS format. The family's flags follow its name, e.g. "yass gen
+// random -k 3 -vars 200 -ratio 4.26". Random families are generated from the
+// -seed flag, which can be given before or after the family's name.
+func runGen(cfg *config) (int, error) {
+	if len(cfg.args) == 0 {
+		return exitUnknown, fmt.Errorf("gen requires a family: %s", generatorNames())
+	}
+	var g *generator
+	for i := range generators {
+		if generators[i].name == cfg.args[0] {
+			g = &generators[i]
+		}
+	}
+	if g == nil {
+		return exitUnknown, fmt.Errorf("unknown family %q (available: %s)", cfg.args[0], generatorNames())
+	}
+
+	fs := flag.NewFlagSet("gen "+g.name, flag.ExitOnError)
+	generate := g.flags(fs)
+	seed := fs.Int64("seed", cfg.seed, "seed of the pseudo-random generator")
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintf(out, "usage: yass gen %s [flags]\n\n", g.name)
+		fmt.Fprintf(out, "Family %s: %s.\n\nFlags:\n", g.name, g.summary)
+		fs.PrintDefaults()
+	}
+	fs.Parse(cfg.args[1:])
+
+	cnf, err := generate(rand.New(rand.NewSource(*seed)))
+	if err != nil {
+		return exitUnknown, err
+	}
+	return exitUnknown, parsers.WriteDIMACS(os.Stdout, cnf)
+}
+
+func generatorNames() string {
+	names := make([]string, len(generators))
+	for i, g := range generators {
+		names[i] = g.name
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,228 @@
+// Package gen generates CNF formulas, e.g. to test or benchmark solvers.
+// Random generators take their source of randomness as argument so that the
+// generated formulas are reproducible from a seed.
+package gen
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/rhartert/yass/parsers"
+	"github.com/rhartert/yass/sat"
+)
+
+// RandomKSAT returns a random k-SAT formula with nVars variables and nClauses
+// clauses. Each clause is made of k distinct variables picked uniformly at
+// random, each of which is negated with probability 1/2. The function panics
+// if k is larger than nVars.
+func RandomKSAT(rng *rand.Rand, k int, nVars int, nClauses int) *parsers.CNF {
+	if k > nVars {
+		panic("k-SAT clauses require at least k variables")
+	}
+
+	cnf := &parsers.CNF{NumVars: nVars}
+	clause := make([]sat.Literal, 0, k)
+	for i := 0; i < nClauses; i++ {
+		clause = clause[:0]
+		for len(clause) < k {
+			v := rng.Intn(nVars)
+			if containsVar(clause, v) {
+				continue
+			}
+			l := sat.PositiveLiteral(v)
+			if rng.Intn(2) == 0 {
+				l = l.Opposite()
+			}
+			clause = append(clause, l)
+		}
+		cnf.AddClause(clause)
+	}
+	return cnf
+}
+
+// Threshold3SAT is the clause/variable ratio around which random 3-SAT
+// formulas go from mostly satisfiable to mostly unsatisfiable, and are the
+// hardest to solve.
+const Threshold3SAT = 4.26
+
+// RandomKSATRatio returns a random k-SAT formula with nVars variables and
+// ratio*nVars clauses (rounded to the nearest integer), see RandomKSAT.
+func RandomKSATRatio(rng *rand.Rand, k int, nVars int, ratio float64) *parsers.CNF {
+	return RandomKSAT(rng, k, nVars, int(math.Round(ratio*float64(nVars))))
+}
+
+func containsVar(clause []sat.Literal, v int) bool {
+	for _, l := range clause {
+		if l.VarID() == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Pigeonhole returns the formula stating that n+1 pigeons can be placed in n
+// holes without two pigeons sharing a hole, which is unsatisfiable but hard to
+// refute for resolution-based solvers. Variable p*n+h states that pigeon p is
+// in hole h.
+func Pigeonhole(n int) *parsers.CNF {
+	v := func(p, h int) int { return p*n + h }
+	cnf := &parsers.CNF{NumVars: (n + 1) * n}
+
+	clause := make([]sat.Literal, 0, n)
+	for p := 0; p <= n; p++ {
+		clause = clause[:0]
+		for h := 0; h < n; h++ {
+			clause = append(clause, sat.PositiveLiteral(v(p, h)))
+		}
+		cnf.AddClause(clause)
+	}
+	for h := 0; h < n; h++ {
+		for p := 0; p <= n; p++ {
+			for q := p + 1; q <= n; q++ {
+				cnf.AddClause([]sat.Literal{
+					sat.NegativeLiteral(v(p, h)),
+					sat.NegativeLiteral(v(q, h)),
+				})
+			}
+		}
+	}
+	return cnf
+}
+
+// Parity returns an unsatisfiable formula stating that the XOR of n variables
+// is both 0 and 1, each XOR being encoded as a chain of auxiliary variables
+// over a different (random) order of the variables. Such formulas are hard to
+// refute for resolution-based solvers despite their simple structure. The
+// first n variables are the formula's inputs. The function panics if n is
+// not positive.
+func Parity(rng *rand.Rand, n int) *parsers.CNF {
+	if n < 1 {
+		panic("parity formulas require at least one variable")
+	}
+	cnf := &parsers.CNF{NumVars: n}
+	vars := make([]int, n)
+	for i := range vars {
+		vars[i] = i
+	}
+	addXOR(cnf, vars, false)
+	rng.Shuffle(n, func(i, j int) { vars[i], vars[j] = vars[j], vars[i] })
+	addXOR(cnf, vars, true)
+	return cnf
+}
+
+// addXOR adds clauses stating that the XOR of the variables is equal to
+// parity. The XOR is computed by a chain of auxiliary variables t_i equal to
+// t_{i-1} XOR vars[i], with t_0 = vars[0].
+func addXOR(cnf *parsers.CNF, vars []int, parity bool) {
+	acc := sat.PositiveLiteral(vars[0])
+	for _, v := range vars[1:] {
+		x := sat.PositiveLiteral(v)
+		t := sat.PositiveLiteral(cnf.AddVariable())
+		cnf.AddClause([]sat.Literal{t.Opposite(), acc, x})
+		cnf.AddClause([]sat.Literal{t.Opposite(), acc.Opposite(), x.Opposite()})
+		cnf.AddClause([]sat.Literal{t, acc.Opposite(), x})
+		cnf.AddClause([]sat.Literal{t, acc, x.Opposite()})
+		acc = t
+	}
+	if !parity {
+		acc = acc.Opposite()
+	}
+	cnf.AddClause([]sat.Literal{acc})
+}
+
+// Edge is an undirected edge between two nodes of a graph.
+type Edge [2]int
+
+// RandomGraph returns nEdges distinct edges picked uniformly at random between
+// nNodes nodes, without self-loops. The function panics if nEdges exceeds the
+// number of possible edges.
+func RandomGraph(rng *rand.Rand, nNodes int, nEdges int) []Edge {
+	if nEdges > nNodes*(nNodes-1)/2 {
+		panic("too many edges for the number of nodes")
+	}
+	seen := map[Edge]bool{}
+	edges := make([]Edge, 0, nEdges)
+	for len(edges) < nEdges {
+		u, v := rng.Intn(nNodes), rng.Intn(nNodes)
+		if u == v {
+			continue
+		}
+		e := Edge{min(u, v), max(u, v)}
+		if seen[e] {
+			continue
+		}
+		seen[e] = true
+		edges = append(edges, e)
+	}
+	return edges
+}
+
+// GraphColoring returns the formula stating that the graph with nNodes nodes
+// and the given edges can be colored with k colors, such that adjacent nodes
+// have different colors. Variable n*k+c states that node n has color c.
+func GraphColoring(nNodes int, edges []Edge, k int) *parsers.CNF {
+	v := func(n, c int) int { return n*k + c }
+	cnf := &parsers.CNF{NumVars: nNodes * k}
+
+	clause := make([]sat.Literal, 0, k)
+	for n := 0; n < nNodes; n++ {
+		clause = clause[:0]
+		for c := 0; c < k; c++ {
+			clause = append(clause, sat.PositiveLiteral(v(n, c)))
+		}
+		cnf.AddClause(clause)
+		for c := 0; c < k; c++ {
+			for d := c + 1; d < k; d++ {
+				cnf.AddClause([]sat.Literal{
+					sat.NegativeLiteral(v(n, c)),
+					sat.NegativeLiteral(v(n, d)),
+				})
+			}
+		}
+	}
+	for _, e := range edges {
+		for c := 0; c < k; c++ {
+			cnf.AddClause([]sat.Literal{
+				sat.NegativeLiteral(v(e[0], c)),
+				sat.NegativeLiteral(v(e[1], c)),
+			})
+		}
+	}
+	return cnf
+}
+
+// Scramble returns a copy of the formula whose variables are renamed by a
+// random permutation, with random polarities, and whose clauses and literals
+// are shuffled. The scrambled formula is equivalent to the original one up to
+// the returned mapping: literal l of the original formula is literal
+// mapping[l.VarID()] of the scrambled one if l is positive, and its negation
+// otherwise. Variable names are preserved.
+func Scramble(rng *rand.Rand, cnf *parsers.CNF) (*parsers.CNF, []sat.Literal) {
+	mapping := make([]sat.Literal, cnf.NumVars)
+	for v, p := range rng.Perm(cnf.NumVars) {
+		mapping[v] = sat.PositiveLiteral(p)
+		if rng.Intn(2) == 0 {
+			mapping[v] = mapping[v].Opposite()
+		}
+	}
+	rename := func(l sat.Literal) sat.Literal {
+		if l.IsPositive() {
+			return mapping[l.VarID()]
+		}
+		return mapping[l.VarID()].Opposite()
+	}
+
+	scrambled := &parsers.CNF{NumVars: cnf.NumVars}
+	for _, i := range rng.Perm(len(cnf.Clauses)) {
+		clause := make([]sat.Literal, len(cnf.Clauses[i]))
+		for j, l := range cnf.Clauses[i] {
+			clause[j] = rename(l)
+		}
+		rng.Shuffle(len(clause), func(a, b int) { clause[a], clause[b] = clause[b], clause[a] })
+		scrambled.Clauses = append(scrambled.Clauses, clause)
+	}
+	for v, name := range cnf.Names {
+		scrambled.NameVariable(mapping[v].VarID(), name)
+	}
+	return scrambled, mapping
+}
@@ -0,0 +1,92 @@
+package gen
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rhartert/yass/sat"
+)
+
+func TestRandomKSAT(t *testing.T) {
+	cnf := RandomKSAT(rand.New(rand.NewSource(42)), 3, 10, 50)
+
+	if cnf.NumVars != 10 || len(cnf.Clauses) != 50 {
+		t.Fatalf("RandomKSAT(): want 10 variables and 50 clauses, got %d and %d", cnf.NumVars, len(cnf.Clauses))
+	}
+	for _, c := range cnf.Clauses {
+		if len(c) != 3 || c[0].VarID() == c[1].VarID() || c[0].VarID() == c[2].VarID() || c[1].VarID() == c[2].VarID() {
+			t.Errorf("RandomKSAT(): want 3 distinct variables per clause, got %v", c)
+		}
+	}
+
+	same := RandomKSAT(rand.New(rand.NewSource(42)), 3, 10, 50)
+	if diff := cmp.Diff(cnf, same); diff != "" {
+		t.Errorf("RandomKSAT(): same seed, different formulas (+want, -got):\n%s", diff)
+	}
+}
+
+func TestPigeonhole(t *testing.T) {
+	cnf := Pigeonhole(3)
+
+	// 4 pigeon clauses and 3 holes * C(4, 2) at-most-one clauses.
+	if cnf.NumVars != 12 || len(cnf.Clauses) != 4+3*6 {
+		t.Errorf("Pigeonhole(3): want 12 variables and 22 clauses, got %d and %d", cnf.NumVars, len(cnf.Clauses))
+	}
+}
+
+func TestParity(t *testing.T) {
+	cnf := Parity(rand.New(rand.NewSource(42)), 4)
+
+	// 4 inputs and 2 chains of 3 XORs, each with 4 clauses, plus the parities.
+	if cnf.NumVars != 4+2*3 || len(cnf.Clauses) != 2*(3*4+1) {
+		t.Errorf("Parity(4): want 10 variables and 26 clauses, got %d and %d", cnf.NumVars, len(cnf.Clauses))
+	}
+}
+
+func TestGraphColoring(t *testing.T) {
+	edges := RandomGraph(rand.New(rand.NewSource(42)), 5, 7)
+	if len(edges) != 7 {
+		t.Fatalf("RandomGraph(): want 7 edges, got %d", len(edges))
+	}
+	cnf := GraphColoring(5, edges, 3)
+
+	// 5 nodes with 1 + C(3, 2) clauses each, and 3 clauses per edge.
+	if cnf.NumVars != 15 || len(cnf.Clauses) != 5*4+7*3 {
+		t.Errorf("GraphColoring(): want 15 variables and 41 clauses, got %d and %d", cnf.NumVars, len(cnf.Clauses))
+	}
+}
+
+func TestScramble(t *testing.T) {
+	cnf := RandomKSAT(rand.New(rand.NewSource(42)), 3, 10, 50)
+	scrambled, mapping := Scramble(rand.New(rand.NewSource(7)), cnf)
+
+	// Renaming the original clauses must give the scrambled clauses, up to
+	// the order of the clauses and of their literals.
+	normalize := func(clauses [][]sat.Literal) [][]sat.Literal {
+		out := [][]sat.Literal{}
+		for _, c := range clauses {
+			c = slices.Clone(c)
+			slices.Sort(c)
+			out = append(out, c)
+		}
+		slices.SortFunc(out, slices.Compare)
+		return out
+	}
+	renamed := [][]sat.Literal{}
+	for _, c := range cnf.Clauses {
+		r := []sat.Literal{}
+		for _, l := range c {
+			m := mapping[l.VarID()]
+			if !l.IsPositive() {
+				m = m.Opposite()
+			}
+			r = append(r, m)
+		}
+		renamed = append(renamed, r)
+	}
+	if diff := cmp.Diff(normalize(renamed), normalize(scrambled.Clauses)); diff != "" {
+		t.Errorf("Scramble(): mismatch (+want, -got):\n%s", diff)
+	}
+}
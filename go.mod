@@ -4,6 +4,6 @@ go 1.22
 
 require (
 	github.com/google/go-cmp v0.6.0
-	github.com/rhartert/dimacs v0.1.0
-	github.com/rhartert/yagh v0.5.0
+	github.com/klauspost/compress v1.18.0
+	github.com/ulikunitz/xz v0.5.15
 )
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/rhartert/yass/sat"
+)
+
+// jsonResult is the result of a run as printed with the -json flag.
+type jsonResult struct {
+	Status     string          `json:"status"`
+	StopReason string          `json:"stop_reason,omitempty"`
+	Model      []int           `json:"model,omitempty"`
+	NamedModel map[string]bool `json:"named_model,omitempty"`
+	Failed     []int           `json:"failed_assumptions,omitempty"`
+	UnsatAt    int             `json:"unsat_clause,omitempty"` // see sat.Solver.UnsatClause, from 1
+	UnsatLine  int             `json:"unsat_line,omitempty"`   // see sat.Solver.UnsatClauseLine
+	Statistics jsonStatistics  `json:"statistics"`
+	ReadTime   float64         `json:"read_time_sec"`
+	SolveTime  float64         `json:"solve_time_sec"`
+	Config     jsonConfig      `json:"config"`
+}
+
+type jsonStatistics struct {
+	Conflicts    uint64                        `json:"conflicts"`
+	Propagations uint64                        `json:"propagations"`
+	Decisions    uint64                        `json:"decisions"`
+	Restarts     uint64                        `json:"restarts"`
+	Iterations   uint64                        `json:"iterations"`
+	Variables    int                           `json:"variables"`
+	Clauses      int                           `json:"clauses"`
+	Learnts      int                           `json:"learnts"`
+	Averages     jsonAverages                  `json:"averages"`
+	Tiers        map[string]jsonTierStatistics `json:"tiers"` // by tier name
+}
+
+// jsonAverages are the moving averages maintained on conflicts.
+type jsonAverages struct {
+	ConflictLevel sat.EMA `json:"conflict_level"`
+	SlowLBD       sat.EMA `json:"slow_lbd"`
+	FastLBD       sat.EMA `json:"fast_lbd"`
+	Trail         sat.EMA `json:"trail"`
+}
+
+type jsonTierStatistics struct {
+	Visits       uint64  `json:"visits"`
+	Guards       uint64  `json:"guards"`
+	GuardHitRate float64 `json:"guard_hit_rate"`
+	Implied      uint64  `json:"implied"`
+}
+
+// tierStatistics returns the propagation statistics of each tier of clauses,
+// by tier name.
+func tierStatistics(s *sat.Solver) map[string]jsonTierStatistics {
+	tiers := map[string]jsonTierStatistics{}
+	for t, ts := range s.Statistics.Tiers {
+		tiers[sat.Tier(t).String()] = jsonTierStatistics{
+			Visits:       ts.Visits,
+			Guards:       ts.Guards,
+			GuardHitRate: ts.GuardHitRate(),
+			Implied:      ts.Implied,
+		}
+	}
+	return tiers
+}
+
+type jsonConfig struct {
+	Instance     string  `json:"instance"`
+	Compression  string  `json:"compression"`
+	Strict       bool    `json:"strict"`
+	MaxConflicts int64   `json:"max_conflicts"`
+	Timeout      string  `json:"timeout"`
+	MaxMemoryMB  int64   `json:"max_memory_mb"`
+	Preset       string  `json:"preset"`
+	PhaseSaving  bool    `json:"phase_saving"`
+	Seed         int64   `json:"seed"`
+	RandomFreq   float64 `json:"random_freq"`
+	Assumptions  []int   `json:"assumptions,omitempty"`
+}
+
+// dimacsModel returns the model as a list of DIMACS literals.
+func dimacsModel(model []bool) []int {
+	lits := make([]int, len(model))
+	for i, v := range model {
+		lits[i] = i + 1
+		if !v {
+			lits[i] = -lits[i]
+		}
+	}
+	return lits
+}
+
+// namedModel returns the value of the named variables in the model.
+func namedModel(s *sat.Solver, model []bool) map[string]bool {
+	named := map[string]bool{}
+	for _, v := range s.NamedVariables() {
+		if v < len(model) {
+			named[s.VariableName(v)] = model[v]
+		}
+	}
+	return named
+}
+
+// printJSON prints the result of solving the instance as a single JSON object
+// and returns the exit code corresponding to the status.
+func printJSON(w io.Writer, cfg *config, s *sat.Solver, status sat.LBool, readTime, solveTime float64) (int, error) {
+	res := newJSONResult(cfg, s, status, readTime, solveTime)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		return exitUnknown, err
+	}
+	return exitCode(status), nil
+}
+
+// newJSONResult returns the result of solving the instance.
+func newJSONResult(cfg *config, s *sat.Solver, status sat.LBool, readTime, solveTime float64) jsonResult {
+	timeout := "none"
+	if cfg.timeout >= 0 {
+		timeout = cfg.timeout.String()
+	}
+
+	res := jsonResult{
+		Status: statusName(status),
+		Statistics: jsonStatistics{
+			Conflicts:    s.Statistics.Conflicts,
+			Propagations: s.Statistics.Propagations,
+			Decisions:    s.Statistics.Decisions,
+			Restarts:     s.Statistics.Restarts,
+			Iterations:   s.Statistics.Iterations,
+			Variables:    s.NumVariables(),
+			Clauses:      s.NumConstraints(),
+			Learnts:      s.NumLearnts(),
+			Averages: jsonAverages{
+				ConflictLevel: s.Statistics.AvgConflictLevel,
+				SlowLBD:       s.Statistics.AvgLearntLBD,
+				FastLBD:       s.Statistics.AvgFastLBD,
+				Trail:         s.Statistics.AvgTrail,
+			},
+			Tiers: tierStatistics(s),
+		},
+		ReadTime:  readTime,
+		SolveTime: solveTime,
+		Config: jsonConfig{
+			Instance:     cfg.instanceFile,
+			Compression:  cfg.compression.String(),
+			Strict:       cfg.strict,
+			MaxConflicts: cfg.maxConflicts,
+			Timeout:      timeout,
+			MaxMemoryMB:  cfg.maxMemory,
+			Preset:       cfg.presetName,
+			PhaseSaving:  cfg.phaseSaving,
+			Seed:         cfg.seed,
+			RandomFreq:   cfg.randomFreq,
+			Assumptions:  cfg.assumptions,
+		},
+	}
+	if status == sat.Unknown {
+		res.StopReason = s.StopReason().String()
+	}
+	if status == sat.True {
+		res.Model = dimacsModel(lastModel(s))
+		if cfg.printNames {
+			res.NamedModel = namedModel(s, lastModel(s))
+		}
+	}
+	if status == sat.False {
+		res.Failed = sat.LiteralsToDIMACS(s.FailedAssumptions())
+		if i, ok := s.UnsatClause(); ok {
+			res.UnsatAt = i + 1
+		}
+		if line, ok := s.UnsatClauseLine(); ok {
+			res.UnsatLine = line
+		}
+	}
+	return res
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+
+	"github.com/rhartert/yass/sat"
+)
+
+// learntWriter writes the learnt clauses exported by the solver to a file, as
+// 0-terminated lines of DIMACS literals.
+type learntWriter struct {
+	f      *os.File
+	bw     *bufio.Writer
+	buf    []byte
+	err    error // first write error, if any
+	closed bool
+}
+
+func createLearntWriter(filename string) (*learntWriter, error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &learntWriter{f: f, bw: bufio.NewWriter(f)}, nil
+}
+
+// write writes the learnt clause. It is meant to be used as the solver's
+// learnt clause export callback.
+func (lw *learntWriter) write(lits []sat.Literal, _ int) {
+	if lw.err != nil {
+		return
+	}
+	lw.buf = lw.buf[:0]
+	for _, l := range lits {
+		lw.buf = strconv.AppendInt(lw.buf, int64(l.ToDIMACS()), 10)
+		lw.buf = append(lw.buf, ' ')
+	}
+	lw.buf = append(lw.buf, '0', '\n')
+	_, lw.err = lw.bw.Write(lw.buf)
+}
+
+// Close flushes the learnt clauses and closes the file. It returns the first
+// error that occurred while writing them. Calling Close again has no effect.
+func (lw *learntWriter) Close() error {
+	if lw.closed {
+		return lw.err
+	}
+	lw.closed = true
+	if lw.err == nil {
+		lw.err = lw.bw.Flush()
+	}
+	if err := lw.f.Close(); lw.err == nil {
+		lw.err = err
+	}
+	return lw.err
+}
@@ -1,11 +1,15 @@
 package main
 
 import (
+	"bufio"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"runtime/pprof"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/rhartert/yass/parsers"
@@ -24,6 +28,18 @@ var flagMemProfile = flag.Bool(
 	"save pprof memory profile in memprof",
 )
 
+var flagMemProfileEvery = flag.Duration(
+	"memprof_every",
+	0,
+	"with -memprof, also save numbered heap profiles (memprof.1, memprof.2, ...) at this interval (0 = disabled)",
+)
+
+var flagMemProfileReduce = flag.Bool(
+	"memprof_reduce",
+	false,
+	"with -memprof, also save a numbered heap profile after each reduction of the learnt clause DB",
+)
+
 var flagMaxConflict = flag.Int64(
 	"max_conflicts",
 	-1,
@@ -33,7 +49,7 @@ var flagMaxConflict = flag.Int64(
 var flagTimeout = flag.Duration(
 	"timeout",
 	-1,
-	"search timeout (-1 = no timeout)",
+	"search timeout, which bounds the whole enumeration with -all_models or the compilation with compile (-1 = no timeout, or 1m per request with serve)",
 )
 
 var flagPhaseSaving = flag.Bool(
@@ -42,61 +58,894 @@ var flagPhaseSaving = flag.Bool(
 	"enable phase saving in search strategy",
 )
 
+var flagPolarity = flag.Bool(
+	"polarity",
+	true,
+	"value assigned by decisions on variables without a saved phase; false favors models with few true variables",
+)
+
+var flagPoolLearnts = flag.Bool(
+	"pool_learnts",
+	true,
+	"store learnt clauses in pooled memory recycled across clause DB reductions",
+)
+
+var flagReasonBump = flag.Bool(
+	"reason_bump",
+	false,
+	"also bump the variables of the reasons of the learnt clauses' literals",
+)
+
+var flagBinaryStrengthen = flag.Int(
+	"binary_strengthen",
+	0,
+	"strengthen the learnt clauses with an LBD of at most this value by resolution with binary clauses (0 disables it)",
+)
+
+var flagProbe = flag.Bool(
+	"probe",
+	false,
+	"probe failed literals along the binary implication graph before searching",
+)
+
+var flagTernary = flag.Int(
+	"ternary",
+	0,
+	"maximum number of resolvents of ternary clauses added before searching (0 disables ternary resolution)",
+)
+
+var flagLearning = flag.String(
+	"learning",
+	"first-uip",
+	"scheme used to learn a clause from each conflict: first-uip, last-uip, all-uip or dip",
+)
+
+var flagMinimize = flag.Bool(
+	"minimize",
+	false,
+	"reduce the model found to one whose set of true variables is minimal",
+)
+
+var flagSeed = flag.Int64(
+	"seed",
+	0,
+	"seed of the solver's pseudo-random generator",
+)
+
+var flagRandomFreq = flag.Float64(
+	"random_freq",
+	0,
+	"probability of making a random decision",
+)
+
+var flagPreset = flag.String(
+	"preset",
+	"default",
+	"bundle of search options tuned for a family of instances: "+strings.Join(sat.PresetNames(), ", ")+"; explicitly set flags override the preset",
+)
+
 var flagGzipInput = flag.Bool(
 	"gzip",
 	false,
-	"gzipped input DIMACS file",
+	"force gzip decompression of the input (same as -compression=gzip); gzipped inputs are otherwise detected automatically",
+)
+
+var flagStrict = flag.Bool(
+	"strict",
+	false,
+	"require DIMACS instances to match their \"p cnf\" header exactly",
+)
+
+var flagCompression = flag.String(
+	"compression",
+	"auto",
+	"compression of the input file: auto, none, gzip, bzip2, xz, or zstd",
+)
+
+var flagMmap = flag.Bool(
+	"mmap",
+	false,
+	"memory-map the instance file instead of reading it through buffers",
+)
+
+var flagJSON = flag.Bool(
+	"json",
+	false,
+	"print the result as a single JSON object instead of the SAT competition format",
+)
+
+var flagStatsLog = flag.String(
+	"stats_log",
+	"",
+	"append the search progress to this file, as CSV if its extension is .csv or as JSON lines otherwise",
+)
+
+var flagStatsInterval = flag.Uint64(
+	"stats_interval",
+	1000,
+	"number of conflicts between two records of the stats log (or two updates of the debug statistics)",
+)
+
+var flagModelsOut = flag.String(
+	"models_out",
+	"",
+	"write the models found to this file, in the format of .models test files",
+)
+
+var flagPrintModel = flag.Bool(
+	"print_model",
+	true,
+	"print the model found as \"v\" lines of DIMACS literals",
+)
+
+var flagPrintNames = flag.Bool(
+	"print_names",
+	false,
+	"also print the value of the variables named by \"c <id> <name>\" comments in the instance (or the constants and signals of SMT-LIB2 and ISCAS instances)",
+)
+
+var flagModelOut = flag.String(
+	"model_out",
+	"",
+	"write the status and model found to this file, in the SAT competition output format",
+)
+
+var flagAllModels = flag.Bool(
+	"all_models",
+	false,
+	"enumerate the models of the instance instead of stopping at the first one",
+)
+
+var flagMaxModels = flag.Int(
+	"max_models",
+	0,
+	"maximum number of models enumerated with -all_models (0 = no maximum)",
+)
+
+var flagAssume = flag.String(
+	"assume",
+	"",
+	"DIMACS literals assumed to be true while solving, e.g. \"1 -5 7\"",
+)
+
+var flagAssumeFile = flag.String(
+	"assume_file",
+	"",
+	"file containing DIMACS literals assumed to be true while solving",
+)
+
+var flagProof = flag.String(
+	"proof",
+	"",
+	"write a DRAT proof of unsatisfiability to this file",
+)
+
+var flagProofFormat = flag.String(
+	"proof_format",
+	"text",
+	"format of the DRAT proof written with -proof: text or binary (LRAT is not supported, see sat.ProofFormat)",
+)
+
+var flagLearntsOut = flag.String(
+	"learnts_out",
+	"",
+	"write the learnt clauses whose LBD is at most -learnts_max_lbd to this file",
+)
+
+var flagLearntsMaxLBD = flag.Int(
+	"learnts_max_lbd",
+	2,
+	"maximum LBD of the learnt clauses written with -learnts_out",
+)
+
+var flagConflictGraphs = flag.String(
+	"conflict_graphs",
+	"",
+	"write the implication graphs analyzed by the first -conflict_graphs_max conflicts to this file, in DOT format",
+)
+
+var flagConflictGraphsMax = flag.Int(
+	"conflict_graphs_max",
+	10,
+	"number of conflicts whose graph is written with -conflict_graphs",
+)
+
+var flagConflictTrace = flag.String(
+	"conflict_trace",
+	"",
+	"write the analysis of the first -conflict_trace_max conflicts to this file, as JSON lines",
+)
+
+var flagConflictTraceMax = flag.Int(
+	"conflict_trace_max",
+	100,
+	"number of conflicts whose analysis is written with -conflict_trace",
+)
+
+var flagListen = flag.String(
+	"listen",
+	"localhost:8080",
+	"address on which the serve command listens",
+)
+
+var flagVerbose = flag.Int(
+	"verbose",
+	1,
+	"verbosity of the search logs: 0 (none), 1 (search statistics), or 2 (also inprocessing simplifications)",
+)
+
+var flagQuiet = flag.Bool(
+	"quiet",
+	false,
+	"disable the search logs (same as -verbose=0)",
+)
+
+var flagMaxMemory = flag.Int64(
+	"max_memory",
+	-1,
+	"memory limit in megabytes above which the search is stopped (-1 = no limit)",
+)
+
+var flagDebugAddr = flag.String(
+	"debug_addr",
+	"",
+	"serve net/http/pprof and the live search statistics (/debug/stats) on this address, e.g. :6060",
 )
 
-func parseConfig() (*config, error) {
-	flag.Parse()
+// stdinFile is the instance name used to read the instance from stdin.
+const stdinFile = "-"
+
+// parseConfig returns the configuration defined by the parsed flags and the
+// positional arguments of the given flag set.
+func parseConfig(fs *flag.FlagSet) (*config, error) {
+	instanceFile := stdinFile
+	if fs.NArg() > 0 && fs.Arg(0) != "" {
+		instanceFile = fs.Arg(0)
+	}
+	compression, err := parsers.ParseCompression(*flagCompression)
+	if err != nil {
+		return nil, err
+	}
+	if *flagGzipInput {
+		compression = parsers.Gzip
+	}
+	assumptions, err := parseLiterals(*flagAssume)
+	if err != nil {
+		return nil, fmt.Errorf("invalid assumptions: %s", err)
+	}
+	if *flagAssumeFile != "" {
+		content, err := os.ReadFile(*flagAssumeFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read assumptions: %s", err)
+		}
+		lits, err := parseLiterals(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("invalid assumptions in %q: %s", *flagAssumeFile, err)
+		}
+		assumptions = append(assumptions, lits...)
+	}
+	project, err := parseLiterals(*flagProject)
+	if err != nil {
+		return nil, fmt.Errorf("invalid projection: %s", err)
+	}
+	verbosity := *flagVerbose
+	if *flagQuiet {
+		verbosity = 0
+	}
+	proofFormat, err := parseProofFormat(*flagProofFormat)
+	if err != nil {
+		return nil, err
+	}
+	learning, err := parseLearningScheme(*flagLearning)
+	if err != nil {
+		return nil, err
+	}
 
-	if flag.NArg() == 0 || flag.Arg(0) == "" {
-		return nil, fmt.Errorf("missing instance file")
+	// Flags set on the command line take precedence over the preset.
+	preset, err := sat.Preset(*flagPreset)
+	if err != nil {
+		return nil, err
 	}
+	presetOptions := sat.DefaultOptions
+	preset(&presetOptions)
+	phaseSaving := presetOptions.PhaseSaving
+	randomFreq := presetOptions.RandomDecisionFreq
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "phase":
+			phaseSaving = *flagPhaseSaving
+		case "random_freq":
+			randomFreq = *flagRandomFreq
+		}
+	})
+
 	return &config{
-		instanceFile: flag.Arg(0),
-		gzippedFile:  *flagGzipInput,
-		memProfile:   *flagMemProfile,
-		cpuProfile:   *flagCPUProfile,
-		maxConflicts: *flagMaxConflict,
-		timeout:      *flagTimeout,
-		phaseSaving:  *flagPhaseSaving,
+		args:          fs.Args(),
+		instanceFile:  instanceFile,
+		compression:   compression,
+		strict:        *flagStrict,
+		mmap:          *flagMmap,
+		json:          *flagJSON,
+		statsLog:      *flagStatsLog,
+		statsInterval: *flagStatsInterval,
+		modelsOut:     *flagModelsOut,
+		printModel:    *flagPrintModel,
+		printNames:    *flagPrintNames,
+		modelOut:      *flagModelOut,
+		allModels:     *flagAllModels,
+		maxModels:     *flagMaxModels,
+		assumptions:   assumptions,
+		proofFile:     *flagProof,
+		proofFormat:   proofFormat,
+		learntsOut:    *flagLearntsOut,
+		learntsMaxLBD: *flagLearntsMaxLBD,
+		graphsOut:     *flagConflictGraphs,
+		graphsMax:     *flagConflictGraphsMax,
+		traceOut:      *flagConflictTrace,
+		traceMax:      *flagConflictTraceMax,
+		varMap:        *flagVarMap,
+		project:       project,
+		mapOut:        *flagMapOut,
+		verbosity:     verbosity,
+		workers:       *flagWorkers,
+		iterations:    *flagIterations,
+		shrinkOut:     *flagShrinkOut,
+		predTimeout:   *flagPredicateTimeout,
+		maxNodes:      *flagMaxNodes,
+		debugAddr:     *flagDebugAddr,
+		listenAddr:    *flagListen,
+		parallel:      *flagParallel,
+		memProfile:    *flagMemProfile,
+		memProfEvery:  *flagMemProfileEvery,
+		memProfReduce: *flagMemProfileReduce,
+		cpuProfile:    *flagCPUProfile,
+		maxConflicts:  *flagMaxConflict,
+		maxMemory:     *flagMaxMemory,
+		timeout:       *flagTimeout,
+		presetName:    *flagPreset,
+		preset:        preset,
+		phaseSaving:   phaseSaving,
+		polarity:      *flagPolarity,
+		poolLearnts:   *flagPoolLearnts,
+		learning:      learning,
+		reasonBump:    *flagReasonBump,
+		binStrengthen: *flagBinaryStrengthen,
+		probe:         *flagProbe,
+		ternary:       *flagTernary,
+		minimize:      *flagMinimize,
+		seed:          *flagSeed,
+		randomFreq:    randomFreq,
 	}, nil
 }
 
+// parseProofFormat returns the proof format with the given name.
+func parseProofFormat(name string) (sat.ProofFormat, error) {
+	switch name {
+	case "text":
+		return sat.ProofText, nil
+	case "binary":
+		return sat.ProofBinary, nil
+	case "lrat":
+		// The solver does not record the antecedents of the derived clauses,
+		// which LRAT proofs require (see sat.ProofFormat).
+		return 0, fmt.Errorf("LRAT proofs are not supported, use text or binary DRAT proofs")
+	default:
+		return 0, fmt.Errorf("unknown proof format %q", name)
+	}
+}
+
+// parseLearningScheme returns the learning scheme with the given name.
+func parseLearningScheme(name string) (sat.LearningScheme, error) {
+	for _, ls := range []sat.LearningScheme{sat.LearnFirstUIP, sat.LearnLastUIP, sat.LearnAllUIP, sat.LearnDIP} {
+		if ls.String() == name {
+			return ls, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown learning scheme %q", name)
+}
+
+// parseLiterals parses a whitespace separated list of DIMACS literals. Zeros are
+// ignored so that 0-terminated lists are accepted.
+func parseLiterals(text string) ([]int, error) {
+	lits := []int{}
+	for _, f := range strings.Fields(text) {
+		l, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid literal %q", f)
+		}
+		if l != 0 {
+			lits = append(lits, l)
+		}
+	}
+	return lits, nil
+}
+
+// toLiterals converts DIMACS literals into literals of the solver's variables.
+func toLiterals(s *sat.Solver, dimacs []int) ([]sat.Literal, error) {
+	lits := make([]sat.Literal, len(dimacs))
+	for i, l := range dimacs {
+		v := l
+		if v < 0 {
+			v = -v
+		}
+		if v > s.NumVariables() {
+			return nil, fmt.Errorf("unknown variable %d", v)
+		}
+		lits[i] = sat.LiteralFromDIMACS(l)
+	}
+	return lits, nil
+}
+
+// formatLiterals returns the DIMACS literals separated by spaces.
+func formatLiterals(lits []int) string {
+	sb := strings.Builder{}
+	for i, l := range lits {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(strconv.Itoa(l))
+	}
+	return sb.String()
+}
+
 type config struct {
-	instanceFile string
-	gzippedFile  bool
-	memProfile   bool
-	cpuProfile   bool
-	maxConflicts int64
-	timeout      time.Duration
-	phaseSaving  bool
+	args          []string // positional arguments
+	instanceFile  string
+	compression   parsers.Compression
+	strict        bool
+	mmap          bool
+	json          bool
+	statsLog      string
+	statsInterval uint64
+	modelsOut     string
+	printModel    bool
+	printNames    bool
+	modelOut      string
+	allModels     bool
+	maxModels     int
+	assumptions   []int // DIMACS literals
+	proofFile     string
+	proofFormat   sat.ProofFormat
+	learntsOut    string
+	learntsMaxLBD int
+	graphsOut     string
+	graphsMax     int
+	traceOut      string
+	traceMax      int
+	varMap        string
+	project       []int // DIMACS variables
+	mapOut        string
+	verbosity     int
+	workers       int
+	iterations    int
+	shrinkOut     string
+	predTimeout   time.Duration
+	maxNodes      int
+	debugAddr     string
+	listenAddr    string
+	parallel      int
+	memProfile    bool
+	memProfEvery  time.Duration
+	memProfReduce bool
+	heapProfiler  *heapProfiler // writes numbered heap profiles
+	cpuProfile    bool
+	maxConflicts  int64
+	maxMemory     int64 // in megabytes
+	timeout       time.Duration
+	presetName    string
+	preset        sat.Option // applied before the other solver options
+	phaseSaving   bool
+	polarity      bool
+	poolLearnts   bool
+	learning      sat.LearningScheme
+	reasonBump    bool
+	binStrengthen int
+	probe         bool
+	ternary       int
+	minimize      bool
+	seed          int64
+	randomFreq    float64
 }
 
 func solverOptions(cfg *config) sat.Options {
 	options := sat.DefaultOptions
+	cfg.preset(&options)
 	options.PhaseSaving = cfg.phaseSaving
+	options.DefaultPolarity = cfg.polarity
+	options.PoolLearnts = cfg.poolLearnts
+	options.Learning = cfg.learning
+	options.ReasonSideBumping = cfg.reasonBump
+	options.BinaryStrengthenLBD = cfg.binStrengthen
+	options.Probing = cfg.probe
+	options.TernaryResolvents = cfg.ternary
+	options.Seed = cfg.seed
+	options.RandomDecisionFreq = cfg.randomFreq
+	options.Verbosity = cfg.verbosity
+	if !cfg.json { // the JSON output must not be mixed with logs
+		options.Logger = log.New(os.Stdout, "", 0)
+	}
 	if cfg.maxConflicts >= 0 {
 		options.MaxConflicts = cfg.maxConflicts
 	}
 	if cfg.timeout >= 0 {
 		options.Timeout = cfg.timeout
 	}
+	if cfg.maxMemory >= 0 {
+		options.MaxMemoryMB = cfg.maxMemory
+	}
+	if cfg.memProfile && cfg.memProfReduce {
+		options.OnReduce = cfg.heapProfiler.write
+	}
 	return options
 }
 
-func run(cfg *config) error {
-	s := sat.NewSolver(solverOptions(cfg))
+// compressionExts are the extensions of compressed files.
+var compressionExts = []string{".gz", ".bz2", ".xz", ".zst"}
+
+// hasExt returns true if the file has the given extension, ignoring the
+// extension of its compression format (if any).
+func hasExt(filename string, ext string) bool {
+	for _, ce := range compressionExts {
+		filename = strings.TrimSuffix(filename, ce)
+	}
+	return strings.HasSuffix(filename, ext)
+}
+
+// isOPBFile returns true if the file has the extension of pseudo-Boolean
+// problems (i.e. ".opb").
+func isOPBFile(filename string) bool {
+	return hasExt(filename, ".opb")
+}
+
+// isSMT2File returns true if the file has the extension of SMT-LIB2 scripts
+// (i.e. ".smt2").
+func isSMT2File(filename string) bool {
+	return hasExt(filename, ".smt2")
+}
+
+// isISCASFile returns true if the file has the extension of ISCAS circuits
+// (i.e. ".bench").
+func isISCASFile(filename string) bool {
+	return hasExt(filename, ".bench")
+}
+
+// isICNFFile returns true if the file has the extension of incremental CNF
+// problems (i.e. ".icnf").
+func isICNFFile(filename string) bool {
+	return hasExt(filename, ".icnf")
+}
+
+// openInstance returns a reader of the decompressed content of the instance
+// file (or stdin).
+func openInstance(cfg *config) (io.ReadCloser, error) {
+	var r io.Reader = os.Stdin
+	if cfg.instanceFile != stdinFile {
+		open := func(name string) (io.ReadCloser, error) { return os.Open(name) }
+		if cfg.mmap {
+			open = parsers.OpenMapped
+		}
+		f, err := open(cfg.instanceFile)
+		if err != nil {
+			return nil, err
+		}
+		r = f
+	}
+	return parsers.DecompressAs(r, cfg.compression)
+}
+
+// runIncremental solves the incremental CNF problem read from r by solving
+// each of its queries with its assumptions.
+func runIncremental(r io.Reader, s *sat.Solver) error {
+	tStart := time.Now()
+	nQueries := 0
+	err := parsers.LoadICNFReader(r, s, func(assumptions []sat.Literal) error {
+		nQueries++
+		status := s.SolveWithAssumptions(assumptions).Status
+		fmt.Printf("c query %d: %s\n", nQueries, status.String())
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("c\n")
+	fmt.Printf("c queries:      %d\n", nQueries)
+	fmt.Printf("c total time:   %.3f sec\n", time.Since(tStart).Seconds())
+	return nil
+}
+
+// loadInstance loads the instance file (or stdin) into the given solver.
+func loadInstance(cfg *config, s parsers.SATSolver) error {
+	r, err := openInstance(cfg)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return loadInstanceReader(cfg, r, s)
+}
+
+// loadInstanceReader loads the instance read from r into the given solver,
+// according to the format of the instance file.
+func loadInstanceReader(cfg *config, r io.Reader, s parsers.SATSolver) error {
+	if isOPBFile(cfg.instanceFile) {
+		// The objective function (if any) is ignored as only the feasibility
+		// of the problem is decided.
+		_, err := parsers.LoadOPBReader(r, s)
+		return err
+	}
+	if isSMT2File(cfg.instanceFile) {
+		return parsers.LoadSMT2Reader(r, s)
+	}
+	if isISCASFile(cfg.instanceFile) {
+		return parsers.LoadISCASReader(r, s)
+	}
+	return parsers.LoadDIMACSReaderWithOptions(r, s, parsers.DIMACSOptions{
+		Strict: cfg.strict,
+		Warn:   func(msg string) { log.Printf("warning: %s", msg) },
+	})
+}
+
+// writeModels writes the models to the file (see parsers.WriteModels).
+func writeModels(filename string, models [][]bool) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := parsers.WriteModels(f, models); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// lastModel returns the last model found by the solver. Contrary to Model, it
+// also returns the last model of an enumeration, which ends with an
+// unsatisfiable solve call.
+func lastModel(s *sat.Solver) []bool {
+	if len(s.Models) == 0 {
+		return nil
+	}
+	return s.Models[len(s.Models)-1]
+}
+
+// writeResult writes the status and model to the file (see printResult).
+func writeResult(filename string, status sat.LBool, model []bool) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	printResult(f, status, model)
+	return f.Close()
+}
+
+// Exit codes of the solver, following the SAT competition conventions.
+const (
+	exitUnknown       = 0
+	exitSatisfiable   = 10
+	exitUnsatisfiable = 20
+)
+
+// exitCode returns the exit code corresponding to the status.
+func exitCode(status sat.LBool) int {
+	switch status {
+	case sat.True:
+		return exitSatisfiable
+	case sat.False:
+		return exitUnsatisfiable
+	default:
+		return exitUnknown
+	}
+}
+
+// statusName returns the status as printed in the SAT competition format
+// (without the "s " prefix).
+func statusName(status sat.LBool) string {
+	switch status {
+	case sat.True:
+		return "SATISFIABLE"
+	case sat.False:
+		return "UNSATISFIABLE"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// printResult prints the status line of the SAT competition output format
+// along with the model's value lines if the problem is satisfiable and model
+// is not nil. It returns the exit code corresponding to the status.
+func printResult(w io.Writer, status sat.LBool, model []bool) int {
+	fmt.Fprintf(w, "s %s\n", statusName(status))
+	if status == sat.True && model != nil {
+		printModel(w, model)
+	}
+	return exitCode(status)
+}
+
+// printModel prints the model as "v" lines of DIMACS literals terminated by 0.
+// Lines are wrapped to remain readable on large models.
+func printModel(w io.Writer, model []bool) {
+	const maxLineLen = 78
+
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+
+	line := make([]byte, 0, maxLineLen+16)
+	line = append(line, 'v')
+	for i, v := range model {
+		lit := i + 1
+		if !v {
+			lit = -lit
+		}
+		if len(line) > maxLineLen {
+			bw.Write(append(line, '\n'))
+			line = append(line[:0], 'v')
+		}
+		line = append(line, ' ')
+		line = strconv.AppendInt(line, int64(lit), 10)
+	}
+	bw.Write(append(line, " 0\n"...))
+}
+
+// printNamedModel prints the value of the named variables in the model as
+// comment lines of the form "c <name> = <value>".
+func printNamedModel(w io.Writer, s *sat.Solver, model []bool) {
+	for _, v := range s.NamedVariables() {
+		if v < len(model) {
+			fmt.Fprintf(w, "c %s = %t\n", s.VariableName(v), model[v])
+		}
+	}
+}
+
+// runSolve decides the satisfiability of the instance.
+func runSolve(cfg *config) (int, error) {
+	instances, err := expandInstances(cfg.args)
+	if err != nil {
+		return exitUnknown, err
+	}
+	if len(instances) > 1 {
+		return runSolveMany(cfg, instances)
+	}
+	if len(instances) == 1 {
+		cfg.instanceFile = instances[0]
+	}
+
+	opts := []sat.Option{sat.WithOptions(solverOptions(cfg))}
+	onProgress := []func(sat.Progress){}
+	if cfg.statsLog != "" {
+		sl, err := openStatsLog(cfg.statsLog)
+		if err != nil {
+			return exitUnknown, fmt.Errorf("could not open stats log: %s", err)
+		}
+		defer func() {
+			if err := sl.Close(); err != nil {
+				log.Printf("could not write stats log: %s", err)
+			}
+		}()
+		onProgress = append(onProgress, sl.Write)
+	}
+	if cfg.debugAddr != "" {
+		ds, err := startDebugServer(cfg.debugAddr)
+		if err != nil {
+			return exitUnknown, fmt.Errorf("could not start debug server: %s", err)
+		}
+		defer ds.close()
+		onProgress = append(onProgress, ds.update)
+	}
+	if len(onProgress) > 0 {
+		opts = append(opts, sat.WithProgress(cfg.statsInterval, func(p sat.Progress) {
+			for _, f := range onProgress {
+				f(p)
+			}
+		}))
+	}
+	if cfg.proofFile != "" {
+		f, err := os.Create(cfg.proofFile)
+		if err != nil {
+			return exitUnknown, fmt.Errorf("could not create proof: %s", err)
+		}
+		defer f.Close()
+		opts = append(opts, sat.WithProof(f, cfg.proofFormat))
+	}
+	var learnts *learntWriter
+	if cfg.learntsOut != "" {
+		lw, err := createLearntWriter(cfg.learntsOut)
+		if err != nil {
+			return exitUnknown, fmt.Errorf("could not create learnt clauses file: %s", err)
+		}
+		defer lw.Close()
+		learnts = lw
+		opts = append(opts, sat.WithLearntExport(cfg.learntsMaxLBD, lw.write))
+	}
+	var graphs *bufio.Writer
+	if cfg.graphsOut != "" {
+		f, err := os.Create(cfg.graphsOut)
+		if err != nil {
+			return exitUnknown, fmt.Errorf("could not create conflict graphs file: %s", err)
+		}
+		defer f.Close()
+		graphs = bufio.NewWriter(f)
+		opts = append(opts, sat.WithConflictGraphs(graphs, cfg.graphsMax))
+	}
+	var trace *bufio.Writer
+	if cfg.traceOut != "" {
+		f, err := os.Create(cfg.traceOut)
+		if err != nil {
+			return exitUnknown, fmt.Errorf("could not create conflict trace file: %s", err)
+		}
+		defer f.Close()
+		trace = bufio.NewWriter(f)
+		opts = append(opts, sat.WithConflictTrace(trace, cfg.traceMax))
+	}
+
+	s, err := sat.NewSolver(opts...)
+	if err != nil {
+		return exitUnknown, fmt.Errorf("invalid solver configuration: %s", err)
+	}
+	defer interruptOnSignal(s)()
+	defer diagnoseOnSignal(s)()
 
 	tRead := time.Now()
-	if err := parsers.LoadDIMACS(cfg.instanceFile, cfg.gzippedFile, s); err != nil {
-		return fmt.Errorf("could not load instance: %s", err)
+	f, err := openInstance(cfg)
+	if err != nil {
+		return exitUnknown, fmt.Errorf("could not load instance: %s", err)
+	}
+
+	// Incremental problems are recognized by their extension or, for the other
+	// DIMACS files, by their "p inccnf" problem line.
+	var r io.Reader = f
+	incremental := isICNFFile(cfg.instanceFile)
+	if !incremental && !isOPBFile(cfg.instanceFile) && !isSMT2File(cfg.instanceFile) && !isISCASFile(cfg.instanceFile) {
+		r, incremental, err = parsers.DetectIncremental(f)
+	}
+	switch {
+	case err != nil:
+	case incremental && cfg.json:
+		f.Close()
+		return exitUnknown, fmt.Errorf("JSON output is not supported for incremental problems")
+	case incremental:
+		err = runIncremental(r, s)
+	default:
+		err = loadInstanceReader(cfg, r, s)
+	}
+	f.Close()
+	if err != nil {
+		return exitUnknown, fmt.Errorf("could not load instance: %s", err)
+	}
+	if incremental {
+		return exitUnknown, nil
 	}
 
+	assumptions, err := toLiterals(s, cfg.assumptions)
+	if err != nil {
+		return exitUnknown, fmt.Errorf("invalid assumptions: %s", err)
+	}
 	tSolve := time.Now()
-	status := s.Solve()
+	var status sat.LBool
+	var enumeration sat.Enumeration
+	if cfg.allModels {
+		enumeration = s.EnumerateModels(sat.EnumerateOptions{
+			Assumptions: assumptions,
+			MaxModels:   cfg.maxModels,
+			Timeout:     cfg.timeout,
+		})
+		switch {
+		case enumeration.Models > 0:
+			status = sat.True
+		case enumeration.Exhaustive:
+			status = sat.False
+		default:
+			status = sat.Unknown
+		}
+	} else if len(assumptions) > 0 {
+		status = s.SolveWithAssumptions(assumptions).Status
+	} else {
+		status = s.Solve().Status
+	}
+	if status == sat.True && cfg.minimize && !cfg.allModels {
+		if s.MinimizeModel(assumptions) != sat.True && !cfg.json {
+			fmt.Printf("c model minimization stopped: %s\n", s.StopReason())
+		}
+	}
 	tCompleted := time.Now()
 
 	stats := s.Statistics
@@ -105,18 +954,106 @@ func run(cfg *config) error {
 	propagationsFreq := float64(stats.Propagations) / solveDur
 	conflictsFreq := float64(stats.Conflicts) / solveDur
 
+	if err := s.ProofError(); err != nil {
+		return exitUnknown, fmt.Errorf("could not write proof: %s", err)
+	}
+	if learnts != nil {
+		if err := learnts.Close(); err != nil {
+			return exitUnknown, fmt.Errorf("could not write learnt clauses: %s", err)
+		}
+	}
+	if graphs != nil {
+		if err := graphs.Flush(); err != nil {
+			return exitUnknown, fmt.Errorf("could not write conflict graphs: %s", err)
+		}
+	}
+	if trace != nil {
+		if err := trace.Flush(); err != nil {
+			return exitUnknown, fmt.Errorf("could not write conflict trace: %s", err)
+		}
+	}
+
+	if cfg.modelsOut != "" {
+		if err := writeModels(cfg.modelsOut, s.Models); err != nil {
+			return exitUnknown, fmt.Errorf("could not write models: %s", err)
+		}
+	}
+	if cfg.modelOut != "" {
+		var model []bool
+		if status == sat.True {
+			model = lastModel(s)
+		}
+		if err := writeResult(cfg.modelOut, status, model); err != nil {
+			return exitUnknown, fmt.Errorf("could not write model: %s", err)
+		}
+	}
+
+	// Interrupted runs only report partial results, such as the models
+	// enumerated so far or a model that is not minimal: they exit as unknown
+	// even if a model was found.
+	exit := func(code int, err error) (int, error) {
+		if s.StopReason() == sat.StoppedByInterrupt {
+			code = exitUnknown
+		}
+		return code, err
+	}
+
+	if cfg.json {
+		return exit(printJSON(os.Stdout, cfg, s, status, readDur, solveDur))
+	}
+
 	fmt.Printf("c\n")
 	fmt.Printf("c read time:    %.3f sec\n", readDur)
 	fmt.Printf("c solve time:   %.3f sec\n", solveDur)
 	fmt.Printf("c conflicts:    %d (%.2f /sec)\n", stats.Conflicts, conflictsFreq)
 	fmt.Printf("c propagations: %d (%.2f M/sec)\n", stats.Propagations, propagationsFreq/1e6)
-	fmt.Printf("c status:       %s\n", status.String())
+	for t, ts := range stats.Tiers {
+		fmt.Printf("c %-14s%d visits, %d guard hits (%.1f%%), %d implied\n",
+			sat.Tier(t).String()+" tier:", ts.Visits, ts.Guards, 100*ts.GuardHitRate(), ts.Implied)
+	}
 
-	return nil
+	if cfg.allModels && !enumeration.Exhaustive {
+		fmt.Printf("c stopped:      %s\n", enumeration.StopReason)
+	} else if status == sat.Unknown {
+		fmt.Printf("c stopped:      %s\n", s.StopReason())
+	}
+	if status == sat.False && len(assumptions) > 0 {
+		fmt.Printf("c failed assumptions: %s\n", formatLiterals(sat.LiteralsToDIMACS(s.FailedAssumptions())))
+	}
+	if i, ok := s.UnsatClause(); ok && status == sat.False {
+		if line, ok := s.UnsatClauseLine(); ok {
+			fmt.Printf("c unsat clause: %d on line %d (falsified when added)\n", i+1, line)
+		} else {
+			fmt.Printf("c unsat clause: %d (falsified when added)\n", i+1)
+		}
+	}
+
+	if status == sat.True && cfg.printNames {
+		printNamedModel(os.Stdout, s, lastModel(s))
+	}
+	if cfg.allModels {
+		// The models are printed after the status line, as value lines of the
+		// SAT competition output format.
+		fmt.Printf("c models:       %d\n", enumeration.Models)
+		code := printResult(os.Stdout, status, nil)
+		if status == sat.True && cfg.printModel {
+			for _, m := range s.Models {
+				printModel(os.Stdout, m)
+			}
+		}
+		return exit(code, nil)
+	}
+
+	var model []bool
+	if status == sat.True && cfg.printModel {
+		model = s.Model()
+	}
+	return exit(printResult(os.Stdout, status, model), nil)
 }
 
 func main() {
-	cfg, err := parseConfig()
+	cmd, fs := parseCommand(os.Args[1:])
+	cfg, err := parseConfig(fs)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -127,19 +1064,31 @@ func main() {
 			log.Fatal(err)
 		}
 		pprof.StartCPUProfile(f)
-		defer pprof.StopCPUProfile()
 	}
 
-	if err := run(cfg); err != nil {
+	cfg.heapProfiler = &heapProfiler{}
+	if cfg.memProfile && cfg.memProfEvery > 0 {
+		cfg.heapProfiler.start(cfg.memProfEvery)
+	}
+
+	code, err := cmd.run(cfg)
+	cfg.heapProfiler.stop()
+	if err != nil {
 		log.Fatal(err)
 	}
 
+	if cfg.cpuProfile {
+		pprof.StopCPUProfile()
+	}
+
 	if cfg.memProfile {
 		f, err := os.Create("memprof")
 		if err != nil {
 			log.Fatal(err)
 		}
 		pprof.WriteHeapProfile(f)
-		defer f.Close()
+		f.Close()
 	}
+
+	os.Exit(code)
 }
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+var flagParallel = flag.Int(
+	"parallel",
+	1,
+	"number of instances solved concurrently when several instances are given",
+)
+
+// expandInstances returns the instance files designated by the arguments,
+// where arguments containing wildcards are expanded as glob patterns.
+func expandInstances(args []string) ([]string, error) {
+	instances := []string{}
+	for _, arg := range args {
+		if !strings.ContainsAny(arg, "*?[") {
+			instances = append(instances, arg)
+			continue
+		}
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %s", arg, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no instance matches %q", arg)
+		}
+		instances = append(instances, matches...)
+	}
+	return instances, nil
+}
+
+// runSolveMany solves several instances concurrently and prints their results
+// along with a summary, as the bench command does. Flags that only apply to a
+// single instance are rejected rather than ignored.
+func runSolveMany(cfg *config, instances []string) (int, error) {
+	if cfg.parallel < 1 {
+		return exitUnknown, fmt.Errorf("the number of parallel solves must be positive, got %d", cfg.parallel)
+	}
+	singleInstance := []struct {
+		flag string
+		set  bool
+	}{
+		{"json", cfg.json},
+		{"stats_log", cfg.statsLog != ""},
+		{"model_out", cfg.modelOut != ""},
+		{"models_out", cfg.modelsOut != ""},
+		{"all_models", cfg.allModels},
+		{"minimize", cfg.minimize},
+		{"assume (or -assume_file)", len(cfg.assumptions) > 0},
+		{"proof", cfg.proofFile != ""},
+		{"learnts_out", cfg.learntsOut != ""},
+		{"conflict_graphs", cfg.graphsOut != ""},
+		{"conflict_trace", cfg.traceOut != ""},
+		{"debug_addr", cfg.debugAddr != ""},
+	}
+	for _, f := range singleInstance {
+		if f.set {
+			return exitUnknown, fmt.Errorf("-%s is not supported when solving several instances", f.flag)
+		}
+	}
+	results := solveInstances(cfg, instances, cfg.parallel)
+	printBenchResults(cfg, results, func(instance string) string { return instance })
+	return exitUnknown, nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/rhartert/yass/parsers"
+	"github.com/rhartert/yass/sat"
+)
+
+// runMUS prints a minimal unsatisfiable subset (MUS) of the instance's clauses
+// in the DIMACS format, along with the indices of its clauses in the instance
+// (starting at 1).
+//
+// The MUS is computed with the deletion-based algorithm: each clause i is
+// extended with a selector literal ¬s_i and the solver is called under the
+// assumption that the selectors of the current core are true. Clauses whose
+// removal keeps the core unsatisfiable are removed, and the core is further
+// reduced to the failed assumptions of each unsatisfiable call.
+func runMUS(cfg *config) (int, error) {
+	cnf := &parsers.CNF{}
+	if err := loadInstance(cfg, cnf); err != nil {
+		return exitUnknown, fmt.Errorf("could not load instance: %s", err)
+	}
+
+	cfg.verbosity = 0 // logs would be repeated for each call to the solver
+	s, err := sat.NewSolver(sat.WithOptions(solverOptions(cfg)))
+	if err != nil {
+		return exitUnknown, fmt.Errorf("invalid solver configuration: %s", err)
+	}
+	for i := 0; i < cnf.NumVars+len(cnf.Clauses); i++ {
+		s.AddVariable()
+	}
+	selector := func(i int) sat.Literal { return sat.PositiveLiteral(cnf.NumVars + i) }
+	clause := []sat.Literal{}
+	for i, c := range cnf.Clauses {
+		clause = append(append(clause[:0], c...), selector(i).Opposite())
+		s.AddClause(clause)
+	}
+
+	tStart := time.Now()
+
+	core := make([]sat.Literal, len(cnf.Clauses))
+	for i := range core {
+		core[i] = selector(i)
+	}
+	switch s.SolveWithAssumptions(core).Status {
+	case sat.True:
+		fmt.Printf("s SATISFIABLE\n")
+		return exitSatisfiable, nil
+	case sat.Unknown:
+		fmt.Printf("s UNKNOWN\n")
+		return exitUnknown, nil
+	}
+	core = slices.Clone(s.FailedAssumptions())
+	slices.Sort(core)
+
+	minimal := true
+	for i := 0; i < len(core); {
+		candidate := slices.Delete(slices.Clone(core), i, i+1)
+		switch s.SolveWithAssumptions(candidate).Status {
+		case sat.False:
+			core = slices.Clone(s.FailedAssumptions())
+			slices.Sort(core)
+			// Clauses before index i are necessary and remain in the core.
+			// The failed assumptions are a subset of the candidate so the
+			// next clause to test is at index i.
+		case sat.Unknown:
+			minimal = false
+			i++
+		default:
+			i++ // the clause is necessary
+		}
+	}
+
+	mus := &parsers.CNF{NumVars: cnf.NumVars}
+	indices := []int{}
+	for _, sel := range core {
+		i := sel.VarID() - cnf.NumVars
+		mus.AddClause(cnf.Clauses[i])
+		indices = append(indices, i+1)
+	}
+
+	fmt.Printf("c mus time:     %.3f sec\n", time.Since(tStart).Seconds())
+	fmt.Printf("c mus size:     %d\n", len(mus.Clauses))
+	if !minimal {
+		fmt.Printf("c the subset may not be minimal (stopped early)\n")
+	}
+	fmt.Printf("c mus clauses:  %s\n", formatLiterals(indices))
+	return exitUnsatisfiable, parsers.WriteDIMACS(os.Stdout, mus)
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rhartert/yass/sat"
+)
+
+var flagProject = flag.String(
+	"project",
+	"",
+	"DIMACS variables onto which the instance is projected, from the root of the OBDD to its leaves, e.g. \"1 2 5\"",
+)
+
+// runOBDD compiles the instance projected onto the variables of -project into
+// a reduced ordered binary decision diagram (OBDD), printed in Graphviz DOT
+// format. The OBDD is built with repeated SAT calls (see buildOBDD), which
+// limits the projection to a few tens of variables at most.
+func runOBDD(cfg *config) (int, error) {
+	if len(cfg.project) == 0 {
+		return exitUnknown, fmt.Errorf("obdd requires the variables of the projection (see -project)")
+	}
+
+	cfg.verbosity = 0 // logs would be repeated for each call to the solver
+	s, err := sat.NewSolver(sat.WithOptions(solverOptions(cfg)))
+	if err != nil {
+		return exitUnknown, fmt.Errorf("invalid solver configuration: %s", err)
+	}
+	defer interruptOnSignal(s)()
+	if err := loadInstance(cfg, s); err != nil {
+		return exitUnknown, fmt.Errorf("could not load instance: %s", err)
+	}
+
+	seen := map[int]bool{}
+	vars := make([]int, len(cfg.project))
+	for i, v := range cfg.project {
+		if v <= 0 || v > s.NumVariables() || seen[v] {
+			return exitUnknown, fmt.Errorf("invalid projection: unknown or repeated variable %d", v)
+		}
+		seen[v] = true
+		vars[i] = v - 1
+	}
+
+	b, ok := buildOBDD(s, vars)
+	if !ok {
+		return exitUnknown, fmt.Errorf("OBDD construction stopped: %s", s.StopReason())
+	}
+	if err := b.writeDOT(os.Stdout, s); err != nil {
+		return exitUnknown, err
+	}
+	if b.root == obddFalse {
+		return exitUnsatisfiable, nil
+	}
+	return exitSatisfiable, nil
+}
+
+// Indices of the terminal nodes of an OBDD.
+const (
+	obddFalse = 0
+	obddTrue  = 1
+)
+
+// obddNode is a decision on the variable of its level: its low (resp. high)
+// child is the function of the node when the variable is false (resp. true).
+type obddNode struct {
+	level int
+	low   int
+	high  int
+}
+
+// obdd is a reduced OBDD, whose nodes are indexed by the order in which they
+// were built: children always come before their parents.
+type obdd struct {
+	vars   []int // variable of each level
+	nodes  []obddNode
+	unique map[obddNode]int // index of each node, by content
+	root   int
+	calls  int // number of SAT calls made to build the OBDD
+}
+
+// buildOBDD returns the OBDD of the solver's problem projected onto the given
+// variables, ordered from the root to the leaves. Each node is built by calling
+// the solver with the assignment of its path as assumptions: the path leads to
+// the false terminal if the solver proves it unsatisfiable, and to the true
+// terminal once all the variables are assigned. Equivalent nodes are merged,
+// but they are built once for each of their paths so that the construction
+// makes up to 2^(n+1) SAT calls on n variables. It returns false if a SAT call
+// is stopped before deciding its problem.
+func buildOBDD(s *sat.Solver, vars []int) (*obdd, bool) {
+	b := &obdd{
+		vars:   vars,
+		nodes:  []obddNode{{level: len(vars)}, {level: len(vars)}}, // terminals
+		unique: map[obddNode]int{},
+	}
+	root, ok := b.build(s, make([]sat.Literal, 0, len(vars)))
+	b.root = root
+	return b, ok
+}
+
+// build returns the node of the function reached by the path of assumptions,
+// which assigns the variables of the levels above the node.
+func (b *obdd) build(s *sat.Solver, path []sat.Literal) (int, bool) {
+	b.calls++
+	switch s.SolveWithAssumptions(path).Status {
+	case sat.Unknown:
+		return 0, false
+	case sat.False:
+		return obddFalse, true
+	}
+	level := len(path)
+	if level == len(b.vars) {
+		return obddTrue, true
+	}
+
+	low, ok := b.build(s, append(path, sat.NegativeLiteral(b.vars[level])))
+	if !ok {
+		return 0, false
+	}
+	high, ok := b.build(s, append(path, sat.PositiveLiteral(b.vars[level])))
+	if !ok {
+		return 0, false
+	}
+	if low == high {
+		return low, true // the variable does not matter
+	}
+	n := obddNode{level: level, low: low, high: high}
+	if i, ok := b.unique[n]; ok {
+		return i, true
+	}
+	b.nodes = append(b.nodes, n)
+	b.unique[n] = len(b.nodes) - 1
+	return len(b.nodes) - 1, true
+}
+
+// writeDOT writes the OBDD as a DOT digraph where each decision node is
+// labeled with its variable (its name if it has one) and its edges to its low
+// and high children are respectively dashed and solid.
+func (b *obdd) writeDOT(w io.Writer, s *sat.Solver) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "digraph obdd {\n")
+	fmt.Fprintf(bw, "  // variables: %d, nodes: %d, SAT calls: %d\n", len(b.vars), len(b.nodes), b.calls)
+	fmt.Fprintf(bw, "  n%d [shape=box, label=\"0\"];\n", obddFalse)
+	fmt.Fprintf(bw, "  n%d [shape=box, label=\"1\"];\n", obddTrue)
+	for i, n := range b.nodes[2:] {
+		i += 2
+		v := b.vars[n.level]
+		label := s.VariableName(v)
+		if label == "" {
+			label = fmt.Sprintf("x%d", v+1)
+		}
+		fmt.Fprintf(bw, "  n%d [label=%q];\n", i, label)
+		fmt.Fprintf(bw, "  n%d -> n%d [style=dashed];\n", i, n.low)
+		fmt.Fprintf(bw, "  n%d -> n%d;\n", i, n.high)
+	}
+	fmt.Fprintf(bw, "  root [shape=none, label=\"\"];\n")
+	fmt.Fprintf(bw, "  root -> n%d;\n", b.root)
+	fmt.Fprintf(bw, "}\n")
+	return bw.Flush()
+}
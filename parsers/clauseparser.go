@@ -0,0 +1,58 @@
+package parsers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/rhartert/yass/sat"
+)
+
+// clauseParser parses DIMACS clause lines. Unless fixedVars is true, variables
+// are added to the solver on demand for formats that do not declare the number
+// of variables upfront.
+type clauseParser struct {
+	solver    SATSolver
+	nVars     int
+	fixedVars bool
+	clause    []sat.Literal
+}
+
+// parseClause parses the 0-terminated literals in tokens into p.clause.
+func (p *clauseParser) parseClause(tokens []token) error {
+	p.clause = p.clause[:0]
+	if len(tokens) == 0 {
+		return errors.New("empty clause line: expected literals terminated by 0")
+	}
+	if last := tokens[len(tokens)-1]; last.text != "0" {
+		return tokenError(last, errors.New("clause line should be terminated by 0"))
+	}
+	for i, t := range tokens {
+		l, err := strconv.Atoi(t.text)
+		if err != nil {
+			return tokenError(t, errors.New("invalid literal"))
+		}
+		if l == 0 {
+			if i != len(tokens)-1 {
+				return tokenError(t, errors.New("zero found before end of clause line"))
+			}
+			break
+		}
+		if v := max(l, -l); v > p.nVars {
+			if p.fixedVars {
+				return tokenError(t, fmt.Errorf("undeclared variable %d", v))
+			}
+			p.growVars(v)
+		}
+		p.clause = append(p.clause, sat.LiteralFromDIMACS(l))
+	}
+	return nil
+}
+
+// growVars adds variables to the solver until it has at least n variables.
+func (p *clauseParser) growVars(n int) {
+	if n > p.nVars {
+		addVariables(p.solver, n-p.nVars)
+		p.nVars = n
+	}
+}
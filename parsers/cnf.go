@@ -0,0 +1,91 @@
+package parsers
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/rhartert/yass/sat"
+)
+
+// CNF is a CNF formula held in memory. It implements SATSolver so that
+// problems can be loaded with the package's parsers and processed before being
+// loaded in a solver (see Load).
+type CNF struct {
+	NumVars int
+	Clauses [][]sat.Literal
+	Names   map[int]string // names of the variables, if any
+}
+
+// NameVariable associates a name with variable v.
+func (cnf *CNF) NameVariable(v int, name string) {
+	if cnf.Names == nil {
+		cnf.Names = map[int]string{}
+	}
+	cnf.Names[v] = name
+}
+
+func (cnf *CNF) AddVariable() int {
+	cnf.NumVars++
+	return cnf.NumVars - 1
+}
+
+func (cnf *CNF) AddClause(tmpClause []sat.Literal) error {
+	clause := make([]sat.Literal, len(tmpClause))
+	copy(clause, tmpClause)
+	cnf.Clauses = append(cnf.Clauses, clause)
+	return nil
+}
+
+// Load loads the formula in the given solver. Clauses are passed to the solver
+// through a temporary buffer so that the formula is left untouched.
+func (cnf *CNF) Load(solver SATSolver) error {
+	if r, ok := solver.(reserver); ok {
+		r.Reserve(cnf.NumVars, len(cnf.Clauses))
+	}
+	addVariables(solver, cnf.NumVars)
+	if n, ok := solver.(namer); ok {
+		for v, name := range cnf.Names {
+			n.NameVariable(v, name)
+		}
+	}
+	tmpClause := []sat.Literal{}
+	for _, c := range cnf.Clauses {
+		tmpClause = append(tmpClause[:0], c...)
+		if err := solver.AddClause(tmpClause); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteDIMACS writes the formula in the DIMACS CNF format. The names of the
+// variables are written as "c <id> <name>" comments.
+func WriteDIMACS(w io.Writer, cnf *CNF) error {
+	bw := bufio.NewWriter(w)
+	vars := make([]int, 0, len(cnf.Names))
+	for v := range cnf.Names {
+		vars = append(vars, v)
+	}
+	sort.Ints(vars)
+	for _, v := range vars {
+		fmt.Fprintf(bw, "c %d %s\n", v+1, cnf.Names[v])
+	}
+	fmt.Fprintf(bw, "p cnf %d %d\n", cnf.NumVars, len(cnf.Clauses))
+
+	line := []byte{}
+	for _, c := range cnf.Clauses {
+		line = line[:0]
+		for _, l := range c {
+			line = strconv.AppendInt(line, int64(l.ToDIMACS()), 10)
+			line = append(line, ' ')
+		}
+		line = append(line, '0', '\n')
+		if _, err := bw.Write(line); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
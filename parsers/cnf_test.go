@@ -0,0 +1,58 @@
+package parsers
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteDIMACS(t *testing.T) {
+	cnf := &CNF{}
+	if err := LoadDIMACS("testdata/test_instance.cnf", false, cnf); err != nil {
+		t.Fatalf("LoadDIMACS(): want no error, got %s", err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := WriteDIMACS(buf, cnf); err != nil {
+		t.Fatalf("WriteDIMACS(): want no error, got %s", err)
+	}
+	got := &CNF{}
+	if err := LoadDIMACSReaderWithOptions(buf, got, DIMACSOptions{Strict: true}); err != nil {
+		t.Fatalf("LoadDIMACSReader(): want no error, got %s", err)
+	}
+
+	if diff := cmp.Diff(cnf, got); diff != "" {
+		t.Errorf("WriteDIMACS(): mismatch (+want, -got):\n%s", diff)
+	}
+}
+
+func TestLoadDIMACSReader_names(t *testing.T) {
+	r := strings.NewReader(`c 1 alpha
+c 3 gamma
+c 2 is not a name
+p cnf 3 1
+1 -3 0
+`)
+	cnf := &CNF{}
+	if err := LoadDIMACSReader(r, cnf); err != nil {
+		t.Fatalf("LoadDIMACSReader(): want no error, got %s", err)
+	}
+	want := map[int]string{0: "alpha", 2: "gamma"}
+	if diff := cmp.Diff(want, cnf.Names); diff != "" {
+		t.Errorf("LoadDIMACSReader(): names mismatch (+want, -got):\n%s", diff)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := WriteDIMACS(buf, cnf); err != nil {
+		t.Fatalf("WriteDIMACS(): want no error, got %s", err)
+	}
+	got := &CNF{}
+	if err := LoadDIMACSReader(buf, got); err != nil {
+		t.Fatalf("LoadDIMACSReader(): want no error, got %s", err)
+	}
+	if diff := cmp.Diff(cnf, got); diff != "" {
+		t.Errorf("WriteDIMACS(): mismatch (+want, -got):\n%s", diff)
+	}
+}
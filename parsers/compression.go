@@ -0,0 +1,142 @@
+package parsers
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// Compression identifies the compression format of an input.
+type Compression uint8
+
+const (
+	Auto Compression = iota // detected from the input's first bytes
+	None
+	Gzip
+	Bzip2
+	XZ
+	Zstd
+)
+
+var compressionNames = []string{"auto", "none", "gzip", "bzip2", "xz", "zstd"}
+
+func (c Compression) String() string {
+	if int(c) < len(compressionNames) {
+		return compressionNames[c]
+	}
+	return fmt.Sprintf("Compression(%d)", c)
+}
+
+// ParseCompression returns the compression format with the given name (e.g.
+// "gzip" or "xz").
+func ParseCompression(name string) (Compression, error) {
+	for i, n := range compressionNames {
+		if n == name {
+			return Compression(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown compression %q (valid: %s)", name, strings.Join(compressionNames, ", "))
+}
+
+// Magic bytes that start the streams of each compression format.
+var magics = []struct {
+	c     Compression
+	magic []byte
+}{
+	{Gzip, []byte{0x1f, 0x8b}},
+	{Bzip2, []byte("BZh")},
+	{XZ, []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}},
+	{Zstd, []byte{0x28, 0xb5, 0x2f, 0xfd}},
+}
+
+// detectCompression returns the compression format of the stream starting
+// with the given bytes.
+func detectCompression(head []byte) Compression {
+	for _, m := range magics {
+		if bytes.HasPrefix(head, m.magic) {
+			return m.c
+		}
+	}
+	return None
+}
+
+// Decompress returns a reader of the decompressed content of r. The compression
+// format (if any) is detected from the first bytes of the stream.
+func Decompress(r io.Reader) (io.Reader, error) {
+	return DecompressAs(r, Auto)
+}
+
+// DecompressAs returns a reader of the content of r decompressed according to
+// the given compression format. Closing the returned reader also closes r if r
+// is an io.Closer.
+func DecompressAs(r io.Reader, c Compression) (io.ReadCloser, error) {
+	// Uncompressed mapped files are read in place (see OpenMapped).
+	if m, ok := r.(*mappedFile); ok {
+		if c == Auto {
+			c = detectCompression(m.unread())
+		}
+		if c == None {
+			return m, nil
+		}
+	}
+
+	br := bufio.NewReader(r)
+	if c == Auto {
+		head, err := br.Peek(6) // longest magic
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		c = detectCompression(head)
+	}
+
+	var dr io.Reader
+	var err error
+	switch c {
+	case None:
+		dr = br
+	case Gzip:
+		dr, err = gzip.NewReader(br)
+	case Bzip2:
+		dr = bzip2.NewReader(br)
+	case XZ:
+		dr, err = xz.NewReader(br)
+	case Zstd:
+		var zr *zstd.Decoder
+		if zr, err = zstd.NewReader(br); err == nil {
+			dr = zr.IOReadCloser()
+		}
+	default:
+		err = fmt.Errorf("unsupported compression %s", c)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &decompressReader{Reader: dr, src: r}, nil
+}
+
+// decompressReader closes the decompressor (if needed) and the source reader.
+type decompressReader struct {
+	io.Reader
+	src io.Reader
+}
+
+func (dr *decompressReader) Close() error {
+	var err error
+	if c, ok := dr.Reader.(io.Closer); ok {
+		err = c.Close()
+	}
+	if c, ok := dr.src.(io.Closer); ok {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
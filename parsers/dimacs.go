@@ -0,0 +1,310 @@
+package parsers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/rhartert/yass/sat"
+)
+
+// dimacsBuilder receives the content of a DIMACS file.
+type dimacsBuilder interface {
+	// Problem processes the problem line.
+	Problem(problem string, nVars int, nClauses int) error
+
+	// Clause processes a clause starting on the given line. Implementations
+	// must consider tmpClause as a shared buffer and only read from it without
+	// retaining it.
+	Clause(tmpClause []sat.Literal, line int) error
+
+	// Comment processes a comment line (including its "c" prefix).
+	Comment(line string) error
+}
+
+// readDIMACS reads a DIMACS file from r and populates the given builder with
+// its content, in order. Errors are reported as *ParseError.
+//
+// Clauses are sequences of literals terminated by 0 and can thus span several
+// lines or share the same line. For compatibility with files that do not
+// terminate their clauses, a comment or problem line also terminates the
+// current clause.
+//
+// The file is read with a hand-rolled byte-level scanner. Apart from comments
+// and the problem line, the content is parsed without allocating memory so
+// that large files are read quickly and with a memory footprint close to the
+// one required to store the problem in the builder.
+func readDIMACS(r io.Reader, b dimacsBuilder) error {
+	sc := newDIMACSScanner(r)
+	clause := make([]sat.Literal, 0, 32)
+	clauseLine := 0 // line of the clause's first literal
+
+	for {
+		c, ok := sc.skipSpaces()
+		if !ok {
+			break
+		}
+
+		switch c {
+		case 'c', 'p', '%':
+			if len(clause) > 0 {
+				if err := b.Clause(clause, clauseLine); err != nil {
+					return sc.errorAt(err, 0)
+				}
+				clause = clause[:0]
+			}
+			text := sc.currentLine()
+			line := strings.TrimSpace(text)
+			var err error
+			switch {
+			case line == "%":
+				return nil // end of file marker
+			case c == 'c':
+				err = b.Comment(line)
+			case c == 'p':
+				err = readProblem(tokenize(text), b)
+			default:
+				err = tokenError(token{line, sc.column()}, errors.New("invalid literal"))
+			}
+			if err != nil {
+				return atLine(err, sc.line, text)
+			}
+			sc.skipLine()
+		default:
+			col := sc.column()
+			l, ok := sc.readInt()
+			if !ok {
+				return sc.errorAt(errors.New("invalid literal"), col)
+			}
+			if len(clause) == 0 {
+				clauseLine = sc.line
+			}
+			if l != 0 {
+				clause = append(clause, sat.LiteralFromDIMACS(l))
+				continue
+			}
+			if err := b.Clause(clause, clauseLine); err != nil {
+				return sc.errorAt(err, 0)
+			}
+			clause = clause[:0]
+		}
+	}
+
+	if sc.err != io.EOF {
+		return sc.err
+	}
+	if len(clause) > 0 {
+		if err := b.Clause(clause, clauseLine); err != nil {
+			return sc.errorAt(err, 0)
+		}
+	}
+	return nil
+}
+
+func readProblem(tokens []token, b dimacsBuilder) error {
+	if len(tokens) == 2 && tokens[1].text == "inccnf" {
+		return tokenError(tokens[1], ErrIncremental)
+	}
+	if len(tokens) != 4 {
+		return fmt.Errorf("problem line should have 4 parts, got %d", len(tokens))
+	}
+	nVars, err := strconv.Atoi(tokens[2].text)
+	if err != nil || nVars < 0 {
+		return tokenError(tokens[2], errors.New("invalid number of variables"))
+	}
+	nClauses, err := strconv.Atoi(tokens[3].text)
+	if err != nil || nClauses < 0 {
+		return tokenError(tokens[3], errors.New("invalid number of clauses"))
+	}
+	if err := b.Problem(tokens[1].text, nVars, nClauses); err != nil {
+		return tokenError(tokens[1], err)
+	}
+	return nil
+}
+
+// dimacsScanner reads a DIMACS file byte by byte from an internal buffer. The
+// buffer always contains the current line from its start so that errors can be
+// reported with their context.
+type dimacsScanner struct {
+	r   io.Reader
+	err error // first read error (io.EOF once the input is exhausted)
+
+	buf       []byte
+	pos       int // position of the next byte to read
+	end       int // end of the valid data
+	lineStart int // position of the current line's first byte
+	line      int // number of the current line, starting at 1
+}
+
+func newDIMACSScanner(r io.Reader) *dimacsScanner {
+	if m, ok := r.(*mappedFile); ok {
+		// The mapped content is scanned in place.
+		data := m.unread()
+		m.Seek(0, io.SeekEnd)
+		return &dimacsScanner{
+			r:    m,
+			err:  io.EOF,
+			buf:  data,
+			end:  len(data),
+			line: 1,
+		}
+	}
+	return &dimacsScanner{
+		r:    r,
+		buf:  make([]byte, 64*1024),
+		line: 1,
+	}
+}
+
+// fill reads more data in the buffer. It returns false if no data could be
+// read, in which case sc.err is set.
+func (sc *dimacsScanner) fill() bool {
+	if sc.err != nil {
+		return false
+	}
+
+	// Discard the previous lines and grow the buffer if the current line
+	// does not leave enough room to read more data.
+	if sc.lineStart > 0 {
+		copy(sc.buf, sc.buf[sc.lineStart:sc.end])
+		sc.pos -= sc.lineStart
+		sc.end -= sc.lineStart
+		sc.lineStart = 0
+	}
+	if sc.end == len(sc.buf) {
+		sc.buf = append(sc.buf, make([]byte, len(sc.buf))...)
+	}
+
+	for {
+		n, err := sc.r.Read(sc.buf[sc.end:])
+		sc.end += n
+		if err != nil {
+			sc.err = err
+		}
+		if n > 0 {
+			return true
+		}
+		if err != nil {
+			return false
+		}
+	}
+}
+
+// skipSpaces skips whitespaces and returns the next byte without consuming it.
+// It returns false if the end of the input is reached.
+func (sc *dimacsScanner) skipSpaces() (byte, bool) {
+	for {
+		for sc.pos < sc.end {
+			c := sc.buf[sc.pos]
+			if !isSpace(c) {
+				return c, true
+			}
+			sc.pos++
+			if c == '\n' {
+				sc.line++
+				sc.lineStart = sc.pos
+			}
+		}
+		if !sc.fill() {
+			return 0, false
+		}
+	}
+}
+
+// skipLine consumes the rest of the current line, including its newline.
+func (sc *dimacsScanner) skipLine() {
+	for {
+		if i := bytes.IndexByte(sc.buf[sc.pos:sc.end], '\n'); i >= 0 {
+			sc.pos += i + 1
+			sc.line++
+			sc.lineStart = sc.pos
+			return
+		}
+		sc.pos = sc.end
+		if !sc.fill() {
+			return
+		}
+	}
+}
+
+// readInt consumes the next token and parses it as a signed decimal integer.
+// It returns false if the token is not a valid integer or if it overflows.
+func (sc *dimacsScanner) readInt() (int, bool) {
+	neg := false
+	ok := true
+	n := 0
+	digits := 0
+
+	for i := 0; ; i++ {
+		if sc.pos == sc.end && !sc.fill() {
+			break
+		}
+		c := sc.buf[sc.pos]
+		if isSpace(c) {
+			break
+		}
+		sc.pos++
+		switch {
+		case i == 0 && (c == '-' || c == '+'):
+			neg = c == '-'
+		case c >= '0' && c <= '9' && ok:
+			if n > (math.MaxInt32-int(c-'0'))/10 {
+				ok = false // literals must fit in 32 bits
+			}
+			n = n*10 + int(c-'0')
+			digits++
+		default:
+			ok = false // keep consuming the token
+		}
+	}
+
+	if !ok || digits == 0 {
+		return 0, false
+	}
+	if neg {
+		n = -n
+	}
+	return n, true
+}
+
+// column returns the column of the next byte to read, starting at 1.
+func (sc *dimacsScanner) column() int {
+	return sc.pos - sc.lineStart + 1
+}
+
+// errorAt returns err as a *ParseError located on the current line. If column
+// is positive, the error refers to the token starting at that column.
+func (sc *dimacsScanner) errorAt(err error, column int) *ParseError {
+	text := sc.currentLine()
+	pe := atLine(err, sc.line, text)
+	if column > 0 && pe.Column == 0 {
+		end := column - 1
+		for end < len(text) && !isSpace(text[end]) {
+			end++
+		}
+		pe.Column = column
+		pe.Token = text[column-1 : end]
+	}
+	return pe
+}
+
+// currentLine returns the content of the current line, reading the input up
+// to the line's end if necessary.
+func (sc *dimacsScanner) currentLine() string {
+	for {
+		if i := bytes.IndexByte(sc.buf[sc.lineStart:sc.end], '\n'); i >= 0 {
+			return string(bytes.TrimRight(sc.buf[sc.lineStart:sc.lineStart+i], "\r"))
+		}
+		if !sc.fill() {
+			return string(sc.buf[sc.lineStart:sc.end])
+		}
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\v' || c == '\f'
+}
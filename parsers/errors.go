@@ -0,0 +1,95 @@
+package parsers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrIncremental is reported when an incremental CNF problem (i.e. "p inccnf")
+// is loaded as a plain CNF problem. Such problems and their assumption cubes
+// must be loaded with LoadICNFReader (see DetectIncremental).
+var ErrIncremental = errors.New("incremental CNF problem")
+
+// ParseError reports a malformed line in a parsed file.
+type ParseError struct {
+	Line   int    // line number, starting at 1
+	Column int    // column of the offending token, starting at 1 (0 if unknown)
+	Token  string // offending token (empty if unknown)
+	Text   string // content of the line
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	sb := strings.Builder{}
+	fmt.Fprintf(&sb, "line %d", e.Line)
+	if e.Column > 0 {
+		fmt.Fprintf(&sb, ", column %d", e.Column)
+	}
+	if e.Token != "" {
+		fmt.Fprintf(&sb, ", token %q", e.Token)
+	}
+	fmt.Fprintf(&sb, ": %s", e.Err)
+
+	// Show the line with a caret under the offending token.
+	if e.Text != "" {
+		sb.WriteString("\n\t")
+		sb.WriteString(e.Text)
+		if e.Column > 0 && e.Column <= len(e.Text) {
+			sb.WriteString("\n\t")
+			for _, c := range e.Text[:e.Column-1] {
+				if c == '\t' {
+					sb.WriteByte('\t')
+				} else {
+					sb.WriteByte(' ')
+				}
+			}
+			sb.WriteByte('^')
+		}
+	}
+
+	return sb.String()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// atLine returns err as a *ParseError located at the given line.
+func atLine(err error, lineNum int, text string) *ParseError {
+	pe, ok := err.(*ParseError)
+	if !ok {
+		pe = &ParseError{Err: err}
+	}
+	pe.Line = lineNum
+	pe.Text = text
+	return pe
+}
+
+// tokenError returns a *ParseError for the given token.
+func tokenError(t token, err error) *ParseError {
+	return &ParseError{Column: t.column, Token: t.text, Err: err}
+}
+
+// token is a whitespace separated token of a line.
+type token struct {
+	text   string
+	column int // starting at 1
+}
+
+// tokenize splits the line around whitespaces and returns its tokens.
+func tokenize(line string) []token {
+	var tokens []token
+	start := -1
+	for i := 0; i <= len(line); i++ {
+		if i == len(line) || line[i] == ' ' || line[i] == '\t' || line[i] == '\r' {
+			if start >= 0 {
+				tokens = append(tokens, token{line[start:i], start + 1})
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,122 @@
+package parsers
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/rhartert/yass/sat"
+)
+
+// LoadGCNF parses the group CNF file. See LoadGCNFReader.
+func LoadGCNF(filename string, gzipped bool, solver SATSolver) ([]sat.Literal, error) {
+	reader, err := reader(filename, gzipped)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %q: %s", filename, err)
+	}
+	defer reader.Close()
+
+	return LoadGCNFReader(reader, solver)
+}
+
+// LoadGCNFReader parses the group CNF problem (i.e. "p gcnf") read from r and
+// loads its clauses in the given solver. Each group g > 0 is mapped to a fresh
+// selector variable so that the clauses of g are only enforced if its selector
+// literal is assumed to be true. Clauses of group 0 are always enforced. The
+// selectors are returned such that selectors[g-1] is the selector of group g.
+func LoadGCNFReader(r io.Reader, solver SATSolver) ([]sat.Literal, error) {
+	p := clauseParser{solver: solver, fixedVars: true}
+	var selectors []sat.Literal
+	hasProblem := false
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, math.MaxInt32)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		text := scanner.Text()
+		line := strings.TrimSpace(text)
+		if line == "" || line[0] == 'c' {
+			continue
+		}
+
+		tokens := tokenize(text)
+		if tokens[0].text == "p" {
+			if hasProblem {
+				return nil, atLine(fmt.Errorf("duplicate problem line"), lineNum, text)
+			}
+			nVars, nGroups, err := parseGCNFProblem(tokens)
+			if err != nil {
+				return nil, atLine(err, lineNum, text)
+			}
+			p.growVars(nVars)
+			for i := 0; i < nGroups; i++ {
+				selectors = append(selectors, sat.PositiveLiteral(solver.AddVariable()))
+			}
+			hasProblem = true
+			continue
+		}
+
+		if !hasProblem {
+			return nil, atLine(fmt.Errorf("clause found before problem line"), lineNum, text)
+		}
+		if err := p.parseGroupClause(tokens, selectors); err != nil {
+			return nil, atLine(err, lineNum, text)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return selectors, nil
+}
+
+// parseGroupClause parses the clause "{g} <literals> 0" into p.clause and loads
+// it in the solver.
+func (p *clauseParser) parseGroupClause(tokens []token, selectors []sat.Literal) error {
+	g, err := parseGroup(tokens[0].text)
+	if err != nil {
+		return tokenError(tokens[0], err)
+	}
+	if g > len(selectors) {
+		return tokenError(tokens[0], fmt.Errorf("group %d exceeds the number of groups %d", g, len(selectors)))
+	}
+	if err := p.parseClause(tokens[1:]); err != nil {
+		return err
+	}
+	if g > 0 {
+		p.clause = append(p.clause, selectors[g-1].Opposite())
+	}
+	return p.solver.AddClause(p.clause)
+}
+
+// parseGCNFProblem returns the number of variables and groups declared in the
+// problem line "p gcnf <variables> <clauses> <groups>".
+func parseGCNFProblem(tokens []token) (int, int, error) {
+	if len(tokens) != 5 || tokens[1].text != "gcnf" {
+		return 0, 0, fmt.Errorf("not a group CNF problem")
+	}
+	nVars, err := strconv.Atoi(tokens[2].text)
+	if err != nil {
+		return 0, 0, tokenError(tokens[2], errors.New("invalid number of variables"))
+	}
+	nGroups, err := strconv.Atoi(tokens[4].text)
+	if err != nil {
+		return 0, 0, tokenError(tokens[4], errors.New("invalid number of groups"))
+	}
+	return nVars, nGroups, nil
+}
+
+// parseGroup parses a group identifier of the form "{g}".
+func parseGroup(s string) (int, error) {
+	if !strings.HasPrefix(s, "{") || !strings.HasSuffix(s, "}") {
+		return 0, fmt.Errorf("invalid group")
+	}
+	g, err := strconv.Atoi(s[1 : len(s)-1])
+	if err != nil || g < 0 {
+		return 0, fmt.Errorf("invalid group")
+	}
+	return g, nil
+}
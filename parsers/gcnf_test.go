@@ -0,0 +1,51 @@
+package parsers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rhartert/yass/sat"
+)
+
+func TestLoadGCNFReader(t *testing.T) {
+	r := strings.NewReader(`c group instance
+p gcnf 2 4 2
+{0} 1 2 0
+{1} -1 0
+{2} -2 0
+{1} 1 -2 0
+`)
+	wantSelectors := []sat.Literal{4, 6} // variables 2 and 3
+	wantInstance := instance{
+		Variables: 4,
+		Clauses: [][]sat.Literal{
+			{0, 2},
+			{1, 5},
+			{3, 7},
+			{0, 3, 5},
+		},
+	}
+
+	got := instance{}
+	gotSelectors, gotErr := LoadGCNFReader(r, &got)
+
+	if gotErr != nil {
+		t.Errorf("LoadGCNFReader(): want no error, got %s", gotErr)
+	}
+	if diff := cmp.Diff(wantInstance, got); diff != "" {
+		t.Errorf("LoadGCNFReader(): instance mismatch (+want, -got):\n%s", diff)
+	}
+	if diff := cmp.Diff(wantSelectors, gotSelectors); diff != "" {
+		t.Errorf("LoadGCNFReader(): selectors mismatch (+want, -got):\n%s", diff)
+	}
+}
+
+func TestLoadGCNFReader_undeclaredVariable(t *testing.T) {
+	r := strings.NewReader("p gcnf 2 1 1\n{1} 1 3 0\n")
+	_, gotErr := LoadGCNFReader(r, &instance{})
+
+	if gotErr == nil {
+		t.Errorf("LoadGCNFReader(): want error, got none")
+	}
+}
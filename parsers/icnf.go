@@ -0,0 +1,117 @@
+package parsers
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/rhartert/yass/sat"
+)
+
+// QueryFunc is called on each assumption cube of an incremental CNF problem.
+// The slice of assumptions is only valid for the duration of the call.
+type QueryFunc func(assumptions []sat.Literal) error
+
+// LoadICNF parses the incremental CNF file. See LoadICNFReader.
+func LoadICNF(filename string, gzipped bool, solver SATSolver, query QueryFunc) error {
+	reader, err := reader(filename, gzipped)
+	if err != nil {
+		return fmt.Errorf("error reading file %q: %s", filename, err)
+	}
+	defer reader.Close()
+
+	return LoadICNFReader(reader, solver, query)
+}
+
+// LoadICNFReader parses the incremental CNF problem (i.e. "p inccnf") read from
+// r. Clauses are loaded in the given solver as they are read and the query
+// function is called on each assumption cube (i.e. "a" lines) once all the
+// clauses that precede it have been loaded.
+func LoadICNFReader(r io.Reader, solver SATSolver, query QueryFunc) error {
+	p := clauseParser{solver: solver}
+	hasProblem := false
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, math.MaxInt32)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		text := scanner.Text()
+		line := strings.TrimSpace(text)
+		if line == "" || line[0] == 'c' {
+			continue
+		}
+
+		var err error
+		tokens := tokenize(text)
+		switch tokens[0].text {
+		case "p":
+			if hasProblem {
+				err = fmt.Errorf("duplicate problem line")
+			} else if len(tokens) != 2 || tokens[1].text != "inccnf" {
+				err = fmt.Errorf("not an incremental CNF problem")
+			}
+			hasProblem = true
+		case "a":
+			if err = p.parseClause(tokens[1:]); err == nil {
+				err = query(p.clause)
+			}
+		default:
+			if err = p.parseClause(tokens); err == nil {
+				err = solver.AddClause(p.clause)
+			}
+		}
+		if err != nil {
+			return atLine(err, lineNum, text)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// DetectIncremental returns true if the DIMACS problem read from r is an
+// incremental CNF problem, that is if its problem line is "p inccnf", so that
+// files can be loaded according to their header rather than their extension.
+// The returned reader yields the whole content of r and must be read instead
+// of r, whose beginning has been consumed.
+func DetectIncremental(r io.Reader) (io.Reader, bool, error) {
+	if m, ok := r.(*mappedFile); ok {
+		// The mapped content is inspected in place.
+		data := m.unread()
+		for len(data) > 0 {
+			var line []byte
+			line, data, _ = bytes.Cut(data, []byte{'\n'})
+			if header, ok := problemHeader(line); ok {
+				return m, header, nil
+			}
+		}
+		return m, false, nil
+	}
+
+	br := bufio.NewReader(r)
+	head := []byte{}
+	for {
+		line, err := br.ReadBytes('\n')
+		head = append(head, line...)
+		header, ok := problemHeader(line)
+		if ok || err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return io.MultiReader(bytes.NewReader(head), br), header, err
+		}
+	}
+}
+
+// problemHeader returns true if line is the problem line of an incremental CNF
+// problem. The second value is false if line is a comment or an empty line,
+// which do not tell whether the problem is incremental.
+func problemHeader(line []byte) (bool, bool) {
+	fields := bytes.Fields(line)
+	if len(fields) == 0 || fields[0][0] == 'c' {
+		return false, false
+	}
+	incremental := len(fields) == 2 && string(fields[0]) == "p" && string(fields[1]) == "inccnf"
+	return incremental, true
+}
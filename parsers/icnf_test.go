@@ -0,0 +1,108 @@
+package parsers
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rhartert/yass/sat"
+)
+
+// query records the assumptions of a query and the number of clauses loaded
+// when the query was made.
+type query struct {
+	Clauses     int
+	Assumptions []sat.Literal
+}
+
+func TestLoadICNFReader(t *testing.T) {
+	r := strings.NewReader(`c incremental instance
+p inccnf
+1 2 0
+a -1 0
+-2 3 0
+a -3 1 0
+`)
+	wantQueries := []query{
+		{Clauses: 1, Assumptions: []sat.Literal{1}},
+		{Clauses: 2, Assumptions: []sat.Literal{5, 0}},
+	}
+	wantInstance := instance{
+		Variables: 3,
+		Clauses:   [][]sat.Literal{{0, 2}, {3, 4}},
+	}
+
+	got := instance{}
+	gotQueries := []query{}
+	gotErr := LoadICNFReader(r, &got, func(assumptions []sat.Literal) error {
+		gotQueries = append(gotQueries, query{
+			Clauses:     len(got.Clauses),
+			Assumptions: append([]sat.Literal{}, assumptions...),
+		})
+		return nil
+	})
+
+	if gotErr != nil {
+		t.Errorf("LoadICNFReader(): want no error, got %s", gotErr)
+	}
+	if diff := cmp.Diff(wantInstance, got); diff != "" {
+		t.Errorf("LoadICNFReader(): instance mismatch (+want, -got):\n%s", diff)
+	}
+	if diff := cmp.Diff(wantQueries, gotQueries); diff != "" {
+		t.Errorf("LoadICNFReader(): queries mismatch (+want, -got):\n%s", diff)
+	}
+}
+
+func TestLoadICNFReader_notIncremental(t *testing.T) {
+	r := strings.NewReader("p cnf 2 1\n1 2 0\n")
+	gotErr := LoadICNFReader(r, &instance{}, func([]sat.Literal) error { return nil })
+
+	if gotErr == nil {
+		t.Errorf("LoadICNFReader(): want error, got none")
+	}
+}
+
+func TestDetectIncremental(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		content string
+		want    bool
+	}{
+		{"incremental", "c comment\n\np inccnf\n1 2 0\na -1 0\n", true},
+		{"plain", "c comment\np cnf 2 1\n1 2 0\n", false},
+		{"no header", "1 2 0\n", false},
+		{"comments only", "c comment\n", false},
+		{"empty", "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "instance.cnf")
+			if err := os.WriteFile(filename, []byte(tc.content), 0o644); err != nil {
+				t.Fatalf("WriteFile(): %s", err)
+			}
+			mapped, err := OpenMapped(filename)
+			if err != nil {
+				t.Fatalf("OpenMapped(): want no error, got %s", err)
+			}
+			defer mapped.Close()
+
+			for _, r := range []io.Reader{strings.NewReader(tc.content), mapped} {
+				gotReader, got, err := DetectIncremental(r)
+				if err != nil {
+					t.Fatalf("DetectIncremental(%T): want no error, got %s", r, err)
+				}
+				if got != tc.want {
+					t.Errorf("DetectIncremental(%T): want %t, got %t", r, tc.want, got)
+				}
+				content, _ := io.ReadAll(gotReader)
+				if diff := cmp.Diff(tc.content, string(content)); diff != "" {
+					t.Errorf("DetectIncremental(%T): content mismatch (+want, -got):\n%s", r, diff)
+				}
+			}
+		})
+	}
+}
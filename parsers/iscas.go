@@ -0,0 +1,248 @@
+package parsers
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/rhartert/yass/sat"
+)
+
+// LoadISCAS parses the ISCAS bench file and loads its circuit in the given SAT
+// solver. See LoadISCASReader.
+func LoadISCAS(filename string, gzipped bool, solver SATSolver) error {
+	reader, err := reader(filename, gzipped)
+	if err != nil {
+		return fmt.Errorf("error reading file %q: %s", filename, err)
+	}
+	defer reader.Close()
+
+	return LoadISCASReader(reader, solver)
+}
+
+// LoadISCASReader parses the combinational circuit read from r in the ISCAS
+// bench format and loads its CNF encoding in the given solver. The circuit is
+// made of input and output declarations, and of gate definitions:
+//
+//	# comment
+//	INPUT(a)
+//	INPUT(b)
+//	OUTPUT(out)
+//	out = NAND(a, b)
+//
+// The supported gates are AND, NAND, OR, NOR, XOR, XNOR, NOT and BUF (or BUFF).
+// Sequential elements (i.e. DFF) are not supported. Each output is constrained
+// to be true: the problem is satisfiable if and only if some input vector sets
+// all the outputs of the circuit to 1 (e.g. to find a difference between the
+// two circuits of a miter).
+//
+// Each signal is a variable of the solver, added in the order in which the
+// signals first appear and named after them if the solver supports names. The
+// Tseitin encoding of the gates adds auxiliary variables after them.
+func LoadISCASReader(r io.Reader, solver SATSolver) error {
+	p := iscasParser{
+		tseitin: tseitin{solver: solver},
+		signals: map[string]*iscasSignal{},
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, math.MaxInt32)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		text := scanner.Text()
+		if err := p.parseLine(text, lineNum); err != nil {
+			return atLine(err, lineNum, text)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	for _, name := range p.order {
+		if s := p.signals[name]; !s.defined {
+			return &ParseError{Line: s.line, Token: name, Err: errors.New("undefined signal")}
+		}
+	}
+
+	// Gates are only encoded once all the signals are known so that the
+	// encoding's auxiliary variables come after them.
+	for _, g := range p.gates {
+		p.encode(g)
+	}
+	for _, out := range p.outputs {
+		p.addClause(out)
+	}
+	return p.err
+}
+
+// iscasSignal is a signal of the circuit.
+type iscasSignal struct {
+	lit     sat.Literal
+	line    int  // line on which the signal first appears
+	defined bool // whether the signal is an input or the output of a gate
+}
+
+// iscasGate is a gate whose output is equivalent to its function of its
+// inputs.
+type iscasGate struct {
+	kind   string
+	output sat.Literal
+	inputs []sat.Literal
+}
+
+// iscasArity is the minimum and maximum number of inputs of each gate, where a
+// negative maximum means no maximum.
+var iscasArity = map[string][2]int{
+	"AND":  {1, -1},
+	"NAND": {1, -1},
+	"OR":   {1, -1},
+	"NOR":  {1, -1},
+	"XOR":  {2, -1},
+	"XNOR": {2, -1},
+	"NOT":  {1, 1},
+	"BUF":  {1, 1},
+	"BUFF": {1, 1},
+}
+
+type iscasParser struct {
+	tseitin
+
+	signals map[string]*iscasSignal
+	order   []string // names of the signals, by first appearance
+	gates   []iscasGate
+	outputs []sat.Literal
+}
+
+func (p *iscasParser) parseLine(text string, lineNum int) error {
+	line, _, _ := strings.Cut(text, "#")
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return nil
+	}
+
+	lhs, rhs, isGate := strings.Cut(line, "=")
+	if !isGate {
+		fn, args, err := parseCall(line)
+		if err != nil {
+			return err
+		}
+		if len(args) != 1 {
+			return fmt.Errorf("%s: expected 1 signal, got %d", fn, len(args))
+		}
+		switch strings.ToUpper(fn) {
+		case "INPUT":
+			return p.define(args[0], lineNum)
+		case "OUTPUT":
+			p.outputs = append(p.outputs, p.signal(args[0], lineNum))
+			return nil
+		default:
+			return fmt.Errorf("unknown declaration %q", fn)
+		}
+	}
+
+	output := strings.TrimSpace(lhs)
+	if !isSignalName(output) {
+		return fmt.Errorf("invalid signal name %q", output)
+	}
+	fn, args, err := parseCall(rhs)
+	if err != nil {
+		return err
+	}
+	kind := strings.ToUpper(fn)
+	arity, ok := iscasArity[kind]
+	if !ok {
+		return fmt.Errorf("unsupported gate %q", fn)
+	}
+	if len(args) < arity[0] || (arity[1] >= 0 && len(args) > arity[1]) {
+		return fmt.Errorf("%s: invalid number of inputs %d", fn, len(args))
+	}
+	if err := p.define(output, lineNum); err != nil {
+		return err
+	}
+	g := iscasGate{kind: kind, output: p.signals[output].lit}
+	for _, a := range args {
+		g.inputs = append(g.inputs, p.signal(a, lineNum))
+	}
+	p.gates = append(p.gates, g)
+	return nil
+}
+
+// parseCall parses text of the form "name(arg1, arg2, ...)".
+func parseCall(text string) (string, []string, error) {
+	text = strings.TrimSpace(text)
+	open := strings.IndexByte(text, '(')
+	if open < 0 || !strings.HasSuffix(text, ")") {
+		return "", nil, fmt.Errorf("expected name(signals...), got %q", text)
+	}
+	name := strings.TrimSpace(text[:open])
+	inner := strings.TrimSpace(text[open+1 : len(text)-1])
+	if inner == "" {
+		return name, nil, nil
+	}
+	args := strings.Split(inner, ",")
+	for i, a := range args {
+		args[i] = strings.TrimSpace(a)
+		if !isSignalName(args[i]) {
+			return "", nil, fmt.Errorf("invalid signal name %q", args[i])
+		}
+	}
+	return name, args, nil
+}
+
+// isSignalName returns true if name can be the name of a signal.
+func isSignalName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, " \t(),=")
+}
+
+// signal returns the literal of the named signal, which is added to the solver
+// on its first appearance.
+func (p *iscasParser) signal(name string, lineNum int) sat.Literal {
+	if s, ok := p.signals[name]; ok {
+		return s.lit
+	}
+	v := p.solver.AddVariable()
+	if n, ok := p.solver.(namer); ok {
+		n.NameVariable(v, name)
+	}
+	p.signals[name] = &iscasSignal{lit: sat.PositiveLiteral(v), line: lineNum}
+	p.order = append(p.order, name)
+	return sat.PositiveLiteral(v)
+}
+
+// define marks the named signal as defined by an input declaration or a gate.
+func (p *iscasParser) define(name string, lineNum int) error {
+	p.signal(name, lineNum)
+	s := p.signals[name]
+	if s.defined {
+		return fmt.Errorf("signal %q defined twice", name)
+	}
+	s.defined = true
+	return nil
+}
+
+// encode adds the clauses of gate g.
+func (p *iscasParser) encode(g iscasGate) {
+	switch g.kind {
+	case "AND", "BUF", "BUFF":
+		p.defineAnd(g.output, g.inputs)
+	case "NAND", "NOT":
+		p.defineAnd(g.output.Opposite(), g.inputs)
+	case "OR":
+		p.defineAnd(g.output.Opposite(), negated(g.inputs))
+	case "NOR":
+		p.defineAnd(g.output, negated(g.inputs))
+	case "XOR", "XNOR":
+		n := len(g.inputs)
+		x := g.inputs[0]
+		for _, in := range g.inputs[1 : n-1] {
+			x = p.xor(x, in)
+		}
+		out := g.output
+		if g.kind == "XNOR" {
+			out = out.Opposite()
+		}
+		p.defineXor(out, x, g.inputs[n-1])
+	}
+}
@@ -0,0 +1,106 @@
+package parsers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/rhartert/yass/sat"
+)
+
+// TestLoadISCASReader_gates verifies that the encoding of each gate, with its
+// output constrained to be true, accepts exactly the inputs for which the gate
+// outputs 1.
+func TestLoadISCASReader_gates(t *testing.T) {
+	testCases := []struct {
+		gate   string
+		inputs int
+		eval   func(x []bool) bool
+	}{
+		{"AND", 3, func(x []bool) bool { return x[0] && x[1] && x[2] }},
+		{"NAND", 3, func(x []bool) bool { return !(x[0] && x[1] && x[2]) }},
+		{"OR", 3, func(x []bool) bool { return x[0] || x[1] || x[2] }},
+		{"NOR", 3, func(x []bool) bool { return !(x[0] || x[1] || x[2]) }},
+		{"XOR", 3, func(x []bool) bool { return x[0] != x[1] != x[2] }},
+		{"XNOR", 3, func(x []bool) bool { return x[0] == (x[1] != x[2]) }},
+		{"XOR", 2, func(x []bool) bool { return x[0] != x[1] }},
+		{"NOT", 1, func(x []bool) bool { return !x[0] }},
+		{"BUFF", 1, func(x []bool) bool { return x[0] }},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s/%d", tc.gate, tc.inputs), func(t *testing.T) {
+			sb := strings.Builder{}
+			names := []string{}
+			for i := 0; i < tc.inputs; i++ {
+				names = append(names, fmt.Sprintf("in%d", i))
+				fmt.Fprintf(&sb, "INPUT(in%d)\n", i)
+			}
+			fmt.Fprintf(&sb, "OUTPUT(out)\nout = %s(%s) # gate\n", tc.gate, strings.Join(names, ", "))
+
+			for mask := 0; mask < 1<<tc.inputs; mask++ {
+				x := make([]bool, tc.inputs)
+				for i := range x {
+					x[i] = mask&(1<<i) != 0
+				}
+
+				s := sat.NewDefaultSolver()
+				if err := LoadISCASReader(strings.NewReader(sb.String()), s); err != nil {
+					t.Fatalf("LoadISCASReader(): want no error, got %s", err)
+				}
+				for i, v := range x {
+					if v {
+						s.AddClause([]sat.Literal{sat.PositiveLiteral(i)})
+					} else {
+						s.AddClause([]sat.Literal{sat.NegativeLiteral(i)})
+					}
+				}
+
+				want := sat.Lift(tc.eval(x))
+				if got := s.Solve().Status; got != want {
+					t.Errorf("Solve() with inputs %v: want %s, got %s", x, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadISCASReader_names(t *testing.T) {
+	r := strings.NewReader("OUTPUT(z)\nz = AND(x, y)\nINPUT(x)\nINPUT(y)\n")
+
+	s := sat.NewDefaultSolver()
+	if err := LoadISCASReader(r, s); err != nil {
+		t.Fatalf("LoadISCASReader(): want no error, got %s", err)
+	}
+
+	for v, want := range []string{"z", "x", "y"} {
+		if got := s.VariableName(v); got != want {
+			t.Errorf("VariableName(%d): want %q, got %q", v, want, got)
+		}
+	}
+}
+
+func TestLoadISCASReader_errors(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		content string
+	}{
+		{"undefined signal", "OUTPUT(z)\nz = NOT(x)\n"},
+		{"defined twice", "INPUT(x)\nx = NOT(x)\n"},
+		{"sequential", "INPUT(x)\ny = DFF(x)\n"},
+		{"arity", "INPUT(x)\nINPUT(y)\nz = NOT(x, y)\n"},
+		{"unknown declaration", "WIRE(x)\n"},
+		{"malformed", "INPUT x\n"},
+		{"invalid name", "INPUT(x)\ny = NOT(x y)\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			gotErr := LoadISCASReader(strings.NewReader(tc.content), &instance{})
+
+			if gotErr == nil {
+				t.Errorf("LoadISCASReader(): want error, got none")
+			}
+		})
+	}
+}
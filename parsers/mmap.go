@@ -0,0 +1,50 @@
+package parsers
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+// OpenMapped opens the file for reading by mapping it in memory when the
+// platform supports it. Reading a mapped file avoids copying its content
+// through intermediate buffers and benefits from the OS page cache when the
+// same file is read repeatedly (e.g. in benchmarks). The file is opened as a
+// regular file if it cannot be mapped (e.g. empty files, pipes).
+//
+// The content of a mapped file must not be modified while it is being read.
+func OpenMapped(filename string) (io.ReadCloser, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	data, err := mmap(f)
+	if err != nil || data == nil {
+		return f, nil // fallback to a regular file
+	}
+	f.Close() // the mapping remains valid after the file is closed
+	m := &mappedFile{data: data}
+	m.Reset(data)
+	return m, nil
+}
+
+// mappedFile is a reader of a file mapped in memory.
+type mappedFile struct {
+	bytes.Reader
+	data []byte
+}
+
+func (m *mappedFile) Close() error {
+	if m.data == nil {
+		return nil
+	}
+	data := m.data
+	m.data = nil
+	m.Reset(nil)
+	return munmap(data)
+}
+
+// unread returns the content of the file that has not been read yet.
+func (m *mappedFile) unread() []byte {
+	return m.data[len(m.data)-m.Len():]
+}
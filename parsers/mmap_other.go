@@ -0,0 +1,14 @@
+//go:build !unix
+
+package parsers
+
+import "os"
+
+// mmap is not supported on this platform: files are read as regular files.
+func mmap(f *os.File) ([]byte, error) {
+	return nil, nil
+}
+
+func munmap(data []byte) error {
+	return nil
+}
@@ -0,0 +1,25 @@
+//go:build unix
+
+package parsers
+
+import (
+	"os"
+	"syscall"
+)
+
+// mmap maps the content of f in memory. It returns nil if f is empty.
+func mmap(f *os.File) ([]byte, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := fi.Size()
+	if !fi.Mode().IsRegular() || size == 0 || int64(int(size)) != size {
+		return nil, nil
+	}
+	return syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+}
+
+func munmap(data []byte) error {
+	return syscall.Munmap(data)
+}
@@ -0,0 +1,184 @@
+package parsers
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/rhartert/yass/sat"
+)
+
+// PBTerm is a weighted literal of a linear pseudo-Boolean expression.
+type PBTerm struct {
+	Coef int64
+	Lit  sat.Literal
+}
+
+// LoadOPB parses the OPB file and loads its pseudo-Boolean constraints in the
+// given SAT solver. The terms of the objective function to minimize are
+// returned (nil if the problem has no objective).
+func LoadOPB(filename string, gzipped bool, solver SATSolver) ([]PBTerm, error) {
+	reader, err := reader(filename, gzipped)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %q: %s", filename, err)
+	}
+	defer reader.Close()
+
+	return LoadOPBReader(reader, solver)
+}
+
+// LoadOPBReader parses the linear OPB problem read from r (as defined by the
+// Pseudo-Boolean Competition) and loads its constraints in the given solver.
+// Constraints are translated to CNF which might require additional variables
+// to be added to the solver. The terms of the objective function to minimize
+// are returned (nil if the problem has no objective).
+func LoadOPBReader(r io.Reader, solver SATSolver) ([]PBTerm, error) {
+	p := opbParser{solver: solver}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, math.MaxInt32)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		text := scanner.Text()
+		line := strings.TrimSpace(text)
+		if line == "" || line[0] == '*' {
+			continue
+		}
+		for _, tok := range tokenize(text) {
+			if err := p.parseToken(tok.text); err != nil {
+				return nil, atLine(err, lineNum, text)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(p.tokens) != 0 {
+		return nil, fmt.Errorf("missing ';' at the end of the last statement")
+	}
+
+	// Constraints are only encoded once all the problem's variables have been
+	// declared so that the encoding's auxiliary variables come after them.
+	enc := pbEncoder{}
+	for _, c := range p.constraints {
+		if err := enc.encodeAtLeast(solver, c.terms, c.atLeast); err != nil {
+			return nil, err
+		}
+	}
+
+	return p.objective, nil
+}
+
+type opbParser struct {
+	solver      SATSolver
+	nVars       int
+	tokens      []string // tokens of the current statement
+	objective   []PBTerm
+	constraints []pbConstraint
+}
+
+// pbConstraint represents constraint sum(terms) >= atLeast.
+type pbConstraint struct {
+	terms   []PBTerm
+	atLeast int64
+}
+
+func (p *opbParser) parseToken(tok string) error {
+	if !strings.HasSuffix(tok, ";") {
+		p.tokens = append(p.tokens, tok)
+		return nil
+	}
+	if tok = strings.TrimSuffix(tok, ";"); tok != "" {
+		p.tokens = append(p.tokens, tok)
+	}
+	err := p.parseStatement(p.tokens)
+	p.tokens = p.tokens[:0]
+	return err
+}
+
+func (p *opbParser) parseStatement(tokens []string) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+	if tokens[0] == "min:" {
+		terms, err := p.parseTerms(tokens[1:])
+		if err != nil {
+			return err
+		}
+		p.objective = terms
+		return nil
+	}
+
+	n := len(tokens)
+	if n < 2 {
+		return fmt.Errorf("invalid constraint %q", strings.Join(tokens, " "))
+	}
+	terms, err := p.parseTerms(tokens[:n-2])
+	if err != nil {
+		return err
+	}
+	rhs, err := strconv.ParseInt(tokens[n-1], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid right-hand side: %w", err)
+	}
+
+	switch tokens[n-2] {
+	case ">=":
+		p.constraints = append(p.constraints, pbConstraint{terms, rhs})
+	case "<=":
+		p.constraints = append(p.constraints, pbConstraint{negate(terms), -rhs})
+	case "=":
+		p.constraints = append(p.constraints, pbConstraint{terms, rhs})
+		p.constraints = append(p.constraints, pbConstraint{negate(terms), -rhs})
+	default:
+		return fmt.Errorf("invalid relational operator %q", tokens[n-2])
+	}
+	return nil
+}
+
+// parseTerms parses a sequence of "coefficient literal" pairs.
+func (p *opbParser) parseTerms(tokens []string) ([]PBTerm, error) {
+	if len(tokens)%2 != 0 {
+		return nil, fmt.Errorf("non-linear or malformed terms %q", strings.Join(tokens, " "))
+	}
+	terms := make([]PBTerm, 0, len(tokens)/2)
+	for i := 0; i < len(tokens); i += 2 {
+		coef, err := strconv.ParseInt(tokens[i], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid coefficient: %w", err)
+		}
+		lit, err := p.parseLiteral(tokens[i+1])
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, PBTerm{Coef: coef, Lit: lit})
+	}
+	return terms, nil
+}
+
+// parseLiteral parses a literal of the form "x<id>" or "~x<id>".
+func (p *opbParser) parseLiteral(tok string) (sat.Literal, error) {
+	name, negated := strings.CutPrefix(tok, "~")
+	id, err := strconv.Atoi(strings.TrimPrefix(name, "x"))
+	if !strings.HasPrefix(name, "x") || err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid literal %q", tok)
+	}
+	for ; p.nVars < id; p.nVars++ {
+		p.solver.AddVariable()
+	}
+	if negated {
+		return sat.NegativeLiteral(id - 1), nil
+	}
+	return sat.PositiveLiteral(id - 1), nil
+}
+
+// negate returns the terms with their coefficient negated.
+func negate(terms []PBTerm) []PBTerm {
+	neg := make([]PBTerm, len(terms))
+	for i, t := range terms {
+		neg[i] = PBTerm{Coef: -t.Coef, Lit: t.Lit}
+	}
+	return neg
+}
@@ -0,0 +1,83 @@
+package parsers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rhartert/yass/sat"
+)
+
+const testOPB = `* #variable= 4 #constraint= 3
+min: +1 x1 +2 x2 -1 ~x4 ;
++2 x1 +3 x2 -1 x3 >= 2 ;
++1 x1 +1 x2 +1 x3 +1 x4 <= 2 ;
++1 ~x1 +1 x4 = 1 ;
+`
+
+// satisfiesTestOPB returns true if the assignment satisfies testOPB.
+func satisfiesTestOPB(x []bool) bool {
+	b := func(v bool) int {
+		if v {
+			return 1
+		}
+		return 0
+	}
+	return 2*b(x[0])+3*b(x[1])-b(x[2]) >= 2 &&
+		b(x[0])+b(x[1])+b(x[2])+b(x[3]) <= 2 &&
+		b(!x[0])+b(x[3]) == 1
+}
+
+func TestLoadOPBReader_objective(t *testing.T) {
+	want := []PBTerm{
+		{Coef: 1, Lit: sat.PositiveLiteral(0)},
+		{Coef: 2, Lit: sat.PositiveLiteral(1)},
+		{Coef: -1, Lit: sat.NegativeLiteral(3)},
+	}
+
+	got, gotErr := LoadOPBReader(strings.NewReader(testOPB), &instance{})
+
+	if gotErr != nil {
+		t.Errorf("LoadOPBReader(): want no error, got %s", gotErr)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("LoadOPBReader(): mismatch (+want, -got):\n%s", diff)
+	}
+}
+
+// TestLoadOPBReader_encoding verifies that the CNF encoding of the constraints
+// accepts exactly the assignments that satisfy them.
+func TestLoadOPBReader_encoding(t *testing.T) {
+	for mask := 0; mask < 16; mask++ {
+		x := make([]bool, 4)
+		for i := range x {
+			x[i] = mask&(1<<i) != 0
+		}
+
+		s := sat.NewDefaultSolver()
+		if _, err := LoadOPBReader(strings.NewReader(testOPB), s); err != nil {
+			t.Fatalf("LoadOPBReader(): want no error, got %s", err)
+		}
+		for i, v := range x {
+			if v {
+				s.AddClause([]sat.Literal{sat.PositiveLiteral(i)})
+			} else {
+				s.AddClause([]sat.Literal{sat.NegativeLiteral(i)})
+			}
+		}
+
+		want := sat.Lift(satisfiesTestOPB(x))
+		if got := s.Solve().Status; got != want {
+			t.Errorf("Solve() with assignment %v: want %s, got %s", x, want, got)
+		}
+	}
+}
+
+func TestLoadOPBReader_nonLinear(t *testing.T) {
+	r := strings.NewReader("+1 x1 x2 >= 1 ;\n")
+	_, gotErr := LoadOPBReader(r, &instance{})
+
+	if gotErr == nil {
+		t.Errorf("LoadOPBReader(): want error, got none")
+	}
+}
@@ -1,37 +1,85 @@
 package parsers
 
 import (
-	"compress/gzip"
+	"bufio"
 	"fmt"
 	"io"
 	"os"
+	"strconv"
+	"strings"
 
-	"github.com/rhartert/dimacs"
 	"github.com/rhartert/yass/sat"
 )
 
+// SATSolver is the interface through which problems are loaded in a solver.
+// AddClause must not retain the slice of literals it is given as parsers reuse
+// it between calls.
 type SATSolver interface {
 	AddVariable() int
 	AddClause([]sat.Literal) error
 }
 
+// reserver is implemented by solvers that can pre-allocate their internal
+// structures for a problem of known size (e.g. *sat.Solver).
+type reserver interface {
+	Reserve(nVars int, nClauses int)
+}
+
+// namer is implemented by solvers that can name their variables (e.g.
+// *sat.Solver).
+type namer interface {
+	NameVariable(v int, name string)
+}
+
+// bulkAdder is implemented by solvers that can add several variables at once
+// (e.g. *sat.Solver).
+type bulkAdder interface {
+	AddVariables(n int) int
+}
+
+// addVariables adds n variables to the solver, at once if it supports it.
+func addVariables(solver SATSolver, n int) {
+	if b, ok := solver.(bulkAdder); ok {
+		if n > 0 {
+			b.AddVariables(n)
+		}
+		return
+	}
+	for i := 0; i < n; i++ {
+		solver.AddVariable()
+	}
+}
+
+// liner is implemented by solvers that can report the source line of the
+// clause that made the problem unsatisfiable (e.g. *sat.Solver).
+type liner interface {
+	SetClauseLine(line int)
+}
+
+// reader returns a reader of the file's content. The content is decompressed
+// as gzip if gzipped is true, or according to the compression format detected
+// from its first bytes otherwise.
 func reader(filename string, gzipped bool) (io.ReadCloser, error) {
 	file, err := os.Open(filename)
 	if err != nil {
 		return nil, err
 	}
-	rc := io.ReadCloser(file)
+	c := Auto
 	if gzipped {
-		rc, err = gzip.NewReader(rc)
-		if err != nil {
-			return nil, err
-		}
+		c = Gzip
 	}
-	return rc, nil
+	r, err := DecompressAs(file, c)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return r, nil
 }
 
 // LoadDIMACS parses the DIMACS CNF file and loads its CNF formula in the
-// given SAT solver.
+// given SAT solver. Compressed files are detected from their first bytes and
+// decompressed transparently. Setting gzipped forces the file to be read as
+// gzip regardless of its content.
 func LoadDIMACS(filename string, gzipped bool, solver SATSolver) error {
 	reader, err := reader(filename, gzipped)
 	if err != nil {
@@ -39,40 +87,188 @@ func LoadDIMACS(filename string, gzipped bool, solver SATSolver) error {
 	}
 	defer reader.Close()
 
-	b := &builder{solver}
-	return dimacs.ReadBuilder(reader, b)
+	return LoadDIMACSReader(reader, solver)
 }
 
-// builder wraps the solver to implement dimacs.Builder.
+// DIMACSOptions configures how DIMACS CNF problems are parsed.
+type DIMACSOptions struct {
+	// Strict requires the problem to comply with its "p cnf" header: clauses
+	// must come after the header, refer to declared variables only, and match
+	// the declared number of clauses. Otherwise, variables are added as needed
+	// and count mismatches are ignored, which is what most real-world files
+	// need.
+	Strict bool
+
+	// Warn, if not nil, is called once the problem has been read leniently if
+	// it does not match its "p cnf" header: a missing header, more variables
+	// than declared (with the highest literal read), or a different number of
+	// clauses.
+	Warn func(msg string)
+}
+
+// LoadDIMACSReader parses the DIMACS CNF formula read from r and loads it in
+// the given SAT solver. The formula is parsed leniently (see DIMACSOptions).
+func LoadDIMACSReader(r io.Reader, solver SATSolver) error {
+	return LoadDIMACSReaderWithOptions(r, solver, DIMACSOptions{})
+}
+
+// LoadDIMACSReaderWithOptions parses the DIMACS CNF formula read from r with
+// the given options and loads it in the given SAT solver.
+func LoadDIMACSReaderWithOptions(r io.Reader, solver SATSolver, opts DIMACSOptions) error {
+	b := &builder{solver: solver, strict: opts.Strict}
+	b.liner, _ = solver.(liner)
+	if err := readDIMACS(r, b); err != nil {
+		return err
+	}
+	if err := b.finish(); err != nil {
+		return err
+	}
+	if opts.Warn != nil {
+		if msg := b.headerMismatch(); msg != "" {
+			opts.Warn(msg)
+		}
+	}
+	return nil
+}
+
+// builder wraps the solver to implement dimacsBuilder.
 type builder struct {
 	solver SATSolver
+	liner  liner // nil if the solver does not implement it
+	strict bool
+
+	hasProblem bool
+	nVars      int // number of variables in the solver
+	nDeclared  int // declared number of variables
+	nClauses   int // declared number of clauses
+	clauses    int // number of clauses read
+	maxLit     int // DIMACS literal of the highest variable read
 }
 
 func (b *builder) Problem(problem string, nVars int, nClauses int) error {
 	if problem != "cnf" {
 		return fmt.Errorf("not a CNF problem")
 	}
-	for i := 0; i < nVars; i++ {
-		b.solver.AddVariable()
+	if b.hasProblem {
+		return fmt.Errorf("duplicate problem line")
+	}
+	if b.strict && b.clauses > 0 {
+		return fmt.Errorf("clause found before problem line")
 	}
+	b.hasProblem = true
+	b.nDeclared = nVars
+	b.nClauses = nClauses
+	if r, ok := b.solver.(reserver); ok {
+		r.Reserve(nVars, nClauses)
+	}
+	b.growVars(nVars)
 	return nil
 }
 
-func (b *builder) Clause(tmpClause []int) error {
-	clause := make([]sat.Literal, len(tmpClause))
-	for i, l := range tmpClause {
-		if l < 0 {
-			clause[i] = sat.NegativeLiteral(-l - 1)
-		} else {
-			clause[i] = sat.PositiveLiteral(l - 1)
+func (b *builder) Clause(tmpClause []sat.Literal, line int) error {
+	if b.strict && !b.hasProblem {
+		return fmt.Errorf("clause found before problem line")
+	}
+	b.clauses++
+	if b.strict && b.clauses > b.nClauses {
+		return fmt.Errorf("too many clauses: expected %d", b.nClauses)
+	}
+
+	for _, l := range tmpClause {
+		v := l.VarID() + 1
+		if v > abs(b.maxLit) {
+			b.maxLit = l.ToDIMACS()
+		}
+		if v > b.nVars {
+			if b.strict {
+				return fmt.Errorf("undeclared variable %d in literal %d: expected at most %d variables", v, l.ToDIMACS(), b.nVars)
+			}
+			b.growVars(v)
 		}
 	}
-	b.solver.AddClause(clause)
+	if b.liner != nil {
+		b.liner.SetClauseLine(line)
+	}
+	b.solver.AddClause(tmpClause)
 	return nil
 }
 
-func (b *builder) Comment(_ string) error {
-	return nil // ignore comments
+// finish verifies the problem once all its lines have been read.
+func (b *builder) finish() error {
+	if !b.strict {
+		return nil
+	}
+	if !b.hasProblem {
+		return fmt.Errorf("missing problem line")
+	}
+	if b.clauses != b.nClauses {
+		return fmt.Errorf("missing clauses: expected %d, got %d", b.nClauses, b.clauses)
+	}
+	return nil
+}
+
+// headerMismatch describes how the problem read differs from its problem line,
+// or returns an empty string if it matches. Declaring more variables than the
+// clauses use is not a mismatch.
+func (b *builder) headerMismatch() string {
+	if !b.hasProblem {
+		return "missing problem line"
+	}
+	mismatches := []string{}
+	if v := abs(b.maxLit); v > b.nDeclared {
+		mismatches = append(mismatches, fmt.Sprintf(
+			"%d variables declared, got %d (highest literal %d)", b.nDeclared, v, b.maxLit))
+	}
+	if b.clauses != b.nClauses {
+		mismatches = append(mismatches, fmt.Sprintf(
+			"%d clauses declared, got %d", b.nClauses, b.clauses))
+	}
+	if len(mismatches) == 0 {
+		return ""
+	}
+	return "problem line mismatch: " + strings.Join(mismatches, ", ")
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// growVars adds variables to the solver until it has at least n variables.
+func (b *builder) growVars(n int) {
+	if n > b.nVars {
+		addVariables(b.solver, n-b.nVars)
+		b.nVars = n
+	}
+}
+
+// Comment names a variable if the comment follows the "c <id> <name>"
+// convention, where id is the DIMACS variable. Other comments are ignored.
+func (b *builder) Comment(line string) error {
+	n, ok := b.solver.(namer)
+	if !ok {
+		return nil
+	}
+	if v, name, ok := parseVariableName(line); ok {
+		n.NameVariable(v, name)
+	}
+	return nil
+}
+
+// parseVariableName returns the variable and name declared by a comment line
+// of the form "c <id> <name>", if any.
+func parseVariableName(line string) (int, string, bool) {
+	fields := strings.Fields(line)
+	if len(fields) != 3 || fields[0] != "c" {
+		return 0, "", false
+	}
+	id, err := strconv.Atoi(fields[1])
+	if err != nil || id <= 0 {
+		return 0, "", false
+	}
+	return id - 1, fields[2], true
 }
 
 // ReadModels returns the list of models (if any) contained in the given file.
@@ -84,14 +280,14 @@ func ReadModels(filename string) ([][]bool, error) {
 	defer reader.Close()
 
 	b := &modelBuilder{}
-	if err := dimacs.ReadBuilder(reader, b); err != nil {
+	if err := readDIMACS(reader, b); err != nil {
 		return nil, err
 	}
 
 	return b.models, nil
 }
 
-// builder wraps the solver to implement dimacs.Builder.
+// modelBuilder implements dimacsBuilder to collect models.
 type modelBuilder struct {
 	models [][]bool
 }
@@ -104,11 +300,34 @@ func (b *modelBuilder) Comment(_ string) error {
 	return nil // ignore comments
 }
 
-func (b *modelBuilder) Clause(tmpClause []int) error {
+func (b *modelBuilder) Clause(tmpClause []sat.Literal, _ int) error {
 	model := make([]bool, len(tmpClause))
 	for i, l := range tmpClause {
-		model[i] = l > 0
+		model[i] = l.IsPositive()
 	}
 	b.models = append(b.models, model)
 	return nil
 }
+
+// WriteModels writes the models in the format read by ReadModels, that is one
+// model per line where each model is a 0-terminated list of DIMACS literals.
+func WriteModels(w io.Writer, models [][]bool) error {
+	bw := bufio.NewWriter(w)
+	line := []byte{}
+	for _, model := range models {
+		line = line[:0]
+		for i, v := range model {
+			lit := int64(i + 1)
+			if !v {
+				lit = -lit
+			}
+			line = strconv.AppendInt(line, lit, 10)
+			line = append(line, ' ')
+		}
+		line = append(line, '0', '\n')
+		if _, err := bw.Write(line); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
@@ -1,8 +1,16 @@
 package parsers
 
 import (
+	"bytes"
 	_ "embed"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/rhartert/yass/sat"
@@ -25,6 +33,18 @@ func (i *instance) AddClause(tmpClause []sat.Literal) error {
 	return nil
 }
 
+// bulkInstance is an instance that records the variables added at once.
+type bulkInstance struct {
+	instance
+	bulks []int
+}
+
+func (i *bulkInstance) AddVariables(n int) int {
+	i.bulks = append(i.bulks, n)
+	i.Variables += n
+	return i.Variables - n
+}
+
 var want = instance{
 	Variables: 3,
 	Clauses: [][]sat.Literal{
@@ -63,6 +83,41 @@ func TestParseDIMACS_gzip(t *testing.T) {
 	}
 }
 
+func TestParseDIMACSReader(t *testing.T) {
+	r := strings.NewReader(`c test instance
+p cnf 3 8
+1 2 3 0
+1 2 -3 0
+1 -2 3 0
+-1 2 3 0
+-1 -2 3 0
+-1 2 -3 0
+1 -2 -3 0
+-1 -2 -3 0
+`)
+	got := instance{}
+	gotErr := LoadDIMACSReader(r, &got)
+
+	if gotErr != nil {
+		t.Errorf("LoadDIMACSReader(): want no error, got %s", gotErr)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("LoadDIMACSReader(): mismatch (+want, -got):\n%s", diff)
+	}
+}
+
+func TestParseDIMACS_gzipDetected(t *testing.T) {
+	got := instance{}
+	gotErr := LoadDIMACS("testdata/test_instance.cnf.gz", false, &got)
+
+	if gotErr != nil {
+		t.Errorf("ParseDIMACS(): want no error, got %s", gotErr)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ParseDIMACS(): mismatch (+want, -got):\n%s", diff)
+	}
+}
+
 func TestParseDIMACS_noFile(t *testing.T) {
 	got := instance{}
 	gotErr := LoadDIMACS("", false, &got)
@@ -80,3 +135,435 @@ func TestParseDIMACS_gzip_notGzipFile(t *testing.T) {
 		t.Errorf("ParseDIMACS(): want error, got none")
 	}
 }
+
+func TestDecompress(t *testing.T) {
+	for _, file := range []string{"testdata/test_instance.cnf", "testdata/test_instance.cnf.gz"} {
+		t.Run(file, func(t *testing.T) {
+			f, err := os.Open(file)
+			if err != nil {
+				t.Fatalf("Open(): %s", err)
+			}
+			defer f.Close()
+
+			r, gotErr := Decompress(f)
+			if gotErr != nil {
+				t.Fatalf("Decompress(): want no error, got %s", gotErr)
+			}
+
+			got := instance{}
+			if err := LoadDIMACSReader(r, &got); err != nil {
+				t.Errorf("LoadDIMACSReader(): want no error, got %s", err)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("LoadDIMACSReader(): mismatch (+want, -got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseDIMACS_compressed(t *testing.T) {
+	testCases := []string{
+		"testdata/test_instance.cnf.bz2",
+		"testdata/test_instance.cnf.xz",
+		"testdata/test_instance.cnf.zst",
+	}
+
+	for _, file := range testCases {
+		t.Run(file, func(t *testing.T) {
+			got := instance{}
+			gotErr := LoadDIMACS(file, false, &got)
+
+			if gotErr != nil {
+				t.Errorf("ParseDIMACS(): want no error, got %s", gotErr)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("ParseDIMACS(): mismatch (+want, -got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseCompression(t *testing.T) {
+	for _, c := range []Compression{Auto, None, Gzip, Bzip2, XZ, Zstd} {
+		got, gotErr := ParseCompression(c.String())
+		if gotErr != nil {
+			t.Errorf("ParseCompression(%q): want no error, got %s", c, gotErr)
+		}
+		if got != c {
+			t.Errorf("ParseCompression(%q): want %s, got %s", c, c, got)
+		}
+	}
+	if _, gotErr := ParseCompression("rar"); gotErr == nil {
+		t.Errorf("ParseCompression(\"rar\"): want error, got none")
+	}
+}
+
+func TestLoadDIMACSReaderWithOptions_lenient(t *testing.T) {
+	r := strings.NewReader(`p cnf 1 1
+1 -2 0
+2 3 0
+`)
+	want := instance{
+		Variables: 3,
+		Clauses:   [][]sat.Literal{{0, 3}, {2, 4}},
+	}
+
+	got := instance{}
+	gotErr := LoadDIMACSReaderWithOptions(r, &got, DIMACSOptions{Strict: false})
+
+	if gotErr != nil {
+		t.Errorf("LoadDIMACSReaderWithOptions(): want no error, got %s", gotErr)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("LoadDIMACSReaderWithOptions(): mismatch (+want, -got):\n%s", diff)
+	}
+}
+
+func TestLoadDIMACSReaderWithOptions_warn(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		content string
+		want    string
+	}{
+		{"valid", "p cnf 3 1\n1 -2 0\n", ""},
+		{"missing header", "1 -2 0\n", "missing problem line"},
+		{"undeclared variable", "p cnf 1 2\n1 -2 0\n-3 0\n", "problem line mismatch: 1 variables declared, got 3 (highest literal -3)"},
+		{"clauses mismatch", "p cnf 2 2\n1 -2 0\n", "problem line mismatch: 2 clauses declared, got 1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := ""
+			r := strings.NewReader(tc.content)
+			err := LoadDIMACSReaderWithOptions(r, &instance{}, DIMACSOptions{
+				Warn: func(msg string) { got = msg },
+			})
+
+			if err != nil {
+				t.Errorf("LoadDIMACSReaderWithOptions(): want no error, got %s", err)
+			}
+			if got != tc.want {
+				t.Errorf("LoadDIMACSReaderWithOptions(): want warning %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestLoadDIMACSReaderWithOptions_strict(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		content string
+		wantErr bool
+	}{
+		{"valid", "p cnf 2 1\n1 -2 0\n", false},
+		{"missing header", "1 -2 0\n", true},
+		{"undeclared variable", "p cnf 1 1\n1 -2 0\n", true},
+		{"too many clauses", "p cnf 2 1\n1 -2 0\n2 0\n", true},
+		{"missing clauses", "p cnf 2 2\n1 -2 0\n", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			r := strings.NewReader(tc.content)
+			gotErr := LoadDIMACSReaderWithOptions(r, &instance{}, DIMACSOptions{Strict: true})
+
+			if tc.wantErr && gotErr == nil {
+				t.Errorf("LoadDIMACSReaderWithOptions(): want error, got none")
+			}
+			if !tc.wantErr && gotErr != nil {
+				t.Errorf("LoadDIMACSReaderWithOptions(): want no error, got %s", gotErr)
+			}
+		})
+	}
+}
+
+func TestLoadDIMACSReader_incremental(t *testing.T) {
+	r := strings.NewReader("p inccnf\n1 2 0\na -1 0\n")
+
+	gotErr := LoadDIMACSReader(r, &instance{})
+
+	if !errors.Is(gotErr, ErrIncremental) {
+		t.Errorf("LoadDIMACSReader(): want ErrIncremental, got %v", gotErr)
+	}
+}
+
+func TestLoadDIMACSReader_parseError(t *testing.T) {
+	r := strings.NewReader("c comment\np cnf 3 2\n1 2 0\n-1  x3 0\n")
+	want := &ParseError{Line: 4, Column: 5, Token: "x3", Text: "-1  x3 0"}
+
+	gotErr := LoadDIMACSReader(r, &instance{})
+
+	var got *ParseError
+	if !errors.As(gotErr, &got) {
+		t.Fatalf("LoadDIMACSReader(): want *ParseError, got %v", gotErr)
+	}
+	if got.Line != want.Line || got.Column != want.Column || got.Token != want.Token || got.Text != want.Text {
+		t.Errorf("LoadDIMACSReader(): want error at %d:%d (%q in %q), got %d:%d (%q in %q)",
+			want.Line, want.Column, want.Token, want.Text,
+			got.Line, got.Column, got.Token, got.Text)
+	}
+}
+
+func TestLoadDIMACSReader_clauseLayout(t *testing.T) {
+	// Clauses span several lines or share the same line, and the input is
+	// read one byte at a time to exercise the scanner's buffer refills.
+	r := iotest.OneByteReader(strings.NewReader(`c test instance
+p cnf 3 8
+1 2 3 0 1 2 -3 0
+1 -2
+3 0
+-1 2 3 0 -1 -2 3
+0 -1 2 -3 0
+1 -2 -3 0
+-1 -2 -3
+%
+0
+`))
+	got := instance{}
+	gotErr := LoadDIMACSReader(r, &got)
+
+	if gotErr != nil {
+		t.Errorf("LoadDIMACSReader(): want no error, got %s", gotErr)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("LoadDIMACSReader(): mismatch (+want, -got):\n%s", diff)
+	}
+}
+
+func TestLoadDIMACSReader_unsatClauseLine(t *testing.T) {
+	r := strings.NewReader("c unsatisfiable\np cnf 2 3\n1 2 0 -1\n0\n-2 0\n")
+	s := sat.NewDefaultSolver()
+
+	if err := LoadDIMACSReader(r, s); err != nil {
+		t.Fatalf("LoadDIMACSReader(): want no error, got %s", err)
+	}
+	gotClause, _ := s.UnsatClause()
+	gotLine, ok := s.UnsatClauseLine()
+	if !ok || gotClause != 2 || gotLine != 5 {
+		t.Errorf("LoadDIMACSReader(): want clause 2 on line 5, got clause %d on line %d (%t)", gotClause, gotLine, ok)
+	}
+}
+
+func TestLoadDIMACSReader_bulkVariables(t *testing.T) {
+	r := strings.NewReader("p cnf 3 2\n1 -2 0\n3 5 0\n")
+	got := &bulkInstance{}
+
+	if err := LoadDIMACSReader(r, got); err != nil {
+		t.Fatalf("LoadDIMACSReader(): want no error, got %s", err)
+	}
+	if diff := cmp.Diff([]int{3, 2}, got.bulks); diff != "" {
+		t.Errorf("AddVariables(): mismatch (+want, -got):\n%s", diff)
+	}
+	if got.Variables != 5 {
+		t.Errorf("variables: want 5, got %d", got.Variables)
+	}
+}
+
+func TestDIMACSScanner_readInt(t *testing.T) {
+	testCases := []struct {
+		input  string
+		want   int
+		wantOK bool
+	}{
+		{"42 ", 42, true},
+		{"-7", -7, true},
+		{"+1", 1, true},
+		{"-0", 0, true},
+		{"2147483647", 2147483647, true},
+		{"-2147483647", -2147483647, true},
+		{"2147483648", 0, false},
+		{"99999999999999999999", 0, false},
+		{"-", 0, false},
+		{"+", 0, false},
+		{"1-2", 0, false},
+		{"x3", 0, false},
+	}
+
+	for _, tc := range testCases {
+		sc := newDIMACSScanner(iotest.OneByteReader(strings.NewReader(tc.input)))
+		got, gotOK := sc.readInt()
+
+		if got != tc.want || gotOK != tc.wantOK {
+			t.Errorf("readInt(%q): want (%d, %t), got (%d, %t)", tc.input, tc.want, tc.wantOK, got, gotOK)
+		}
+	}
+}
+
+func TestLoadDIMACSReader_literals(t *testing.T) {
+	// A zero in the middle of a line ends the clause and "-0" is a zero.
+	r := strings.NewReader("p cnf 3 4\n+1 -2 0 +3 -0\n1 0 -3 0\n")
+	want := instance{
+		Variables: 3,
+		Clauses:   [][]sat.Literal{{0, 3}, {4}, {0}, {5}},
+	}
+
+	got := instance{}
+	if err := LoadDIMACSReader(r, &got); err != nil {
+		t.Fatalf("LoadDIMACSReader(): want no error, got %s", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("LoadDIMACSReader(): mismatch (+want, -got):\n%s", diff)
+	}
+}
+
+func TestLoadDIMACSReader_overflow(t *testing.T) {
+	r := strings.NewReader("p cnf 1 1\n1 2147483648 0\n")
+
+	gotErr := LoadDIMACSReader(r, &instance{})
+
+	var got *ParseError
+	if !errors.As(gotErr, &got) {
+		t.Fatalf("LoadDIMACSReader(): want *ParseError, got %v", gotErr)
+	}
+	if got.Line != 2 || got.Token != "2147483648" {
+		t.Errorf("LoadDIMACSReader(): want error on %q at line 2, got %q at line %d", "2147483648", got.Token, got.Line)
+	}
+}
+
+func TestLoadDIMACSReader_allocs(t *testing.T) {
+	// Loading a clause in the solver should only allocate the clause itself
+	// (and amortized slice growth), whatever the number of clauses. Clauses
+	// are long enough for a per-clause map to be allocated on the heap.
+	allocsPerClause := func(nClauses int) float64 {
+		rng := rand.New(rand.NewSource(1))
+		sb := strings.Builder{}
+		fmt.Fprintf(&sb, "p cnf 1000 %d\n", nClauses)
+		for i := 0; i < nClauses; i++ {
+			for j := 0; j < 16; j++ {
+				fmt.Fprintf(&sb, "%d ", (rng.Intn(1000)+1)*(1-2*rng.Intn(2)))
+			}
+			sb.WriteString("0\n")
+		}
+		data := sb.String()
+
+		allocs := testing.AllocsPerRun(3, func() {
+			if err := LoadDIMACSReader(strings.NewReader(data), sat.NewDefaultSolver()); err != nil {
+				t.Fatalf("LoadDIMACSReader(): want no error, got %s", err)
+			}
+		})
+		return allocs / float64(nClauses)
+	}
+
+	small := allocsPerClause(10_000)
+	large := allocsPerClause(40_000)
+
+	if large > 3 {
+		t.Errorf("LoadDIMACSReader(): want at most 3 allocations per clause, got %.2f", large)
+	}
+	if large > small {
+		t.Errorf("LoadDIMACSReader(): allocations per clause grow with the instance: %.2f then %.2f", small, large)
+	}
+}
+
+func TestOpenMapped(t *testing.T) {
+	for _, filename := range []string{"testdata/test_instance.cnf", "testdata/test_instance.cnf.gz"} {
+		f, err := OpenMapped(filename)
+		if err != nil {
+			t.Fatalf("OpenMapped(%q): want no error, got %s", filename, err)
+		}
+		r, err := Decompress(f)
+		if err != nil {
+			t.Fatalf("Decompress(%q): want no error, got %s", filename, err)
+		}
+
+		got := instance{}
+		gotErr := LoadDIMACSReader(r, &got)
+		f.Close()
+
+		if gotErr != nil {
+			t.Errorf("LoadDIMACSReader(%q): want no error, got %s", filename, gotErr)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("LoadDIMACSReader(%q): mismatch (+want, -got):\n%s", filename, diff)
+		}
+	}
+}
+
+func TestWriteModels(t *testing.T) {
+	models := [][]bool{
+		{true, false, true},
+		{false, false, false},
+	}
+	filename := filepath.Join(t.TempDir(), "test.cnf.models")
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteModels(f, models); err != nil {
+		t.Fatalf("WriteModels(): want no error, got %s", err)
+	}
+	f.Close()
+
+	got, err := ReadModels(filename)
+	if err != nil {
+		t.Fatalf("ReadModels(): want no error, got %s", err)
+	}
+	if diff := cmp.Diff(models, got); diff != "" {
+		t.Errorf("ReadModels(): mismatch (+want, -got):\n%s", diff)
+	}
+}
+
+func TestReadSolution(t *testing.T) {
+	r := strings.NewReader(`c solved by some solver
+s SATISFIABLE
+v 1 -2
+v 4 0
+`)
+	sol, err := ReadSolution(r)
+	if err != nil {
+		t.Fatalf("ReadSolution(): want no error, got %s", err)
+	}
+	want := &Solution{Status: "SATISFIABLE", Values: []int{1, -2, 4}}
+	if diff := cmp.Diff(want, sol); diff != "" {
+		t.Errorf("ReadSolution(): mismatch (+want, -got):\n%s", diff)
+	}
+
+	model, unassigned, err := sol.Model(5)
+	if err != nil {
+		t.Fatalf("Model(): want no error, got %s", err)
+	}
+	if diff := cmp.Diff([]bool{true, false, false, true, false}, model); diff != "" {
+		t.Errorf("Model(): mismatch (+want, -got):\n%s", diff)
+	}
+	if unassigned != 2 {
+		t.Errorf("Model(): want 2 unassigned variables, got %d", unassigned)
+	}
+
+	sol = &Solution{Values: []int{1, -1}}
+	if _, _, err := sol.Model(1); err == nil {
+		t.Errorf("Model(): want error on conflicting literals, got none")
+	}
+}
+
+//go:embed testdata/random-3sat-2000.cnf
+var benchInstance []byte
+
+// clauseCounter is a SATSolver that only counts the clauses loaded in it.
+type clauseCounter struct {
+	variables int
+	clauses   int
+}
+
+func (cc *clauseCounter) AddVariable() int {
+	cc.variables++
+	return cc.variables - 1
+}
+
+func (cc *clauseCounter) AddClause([]sat.Literal) error {
+	cc.clauses++
+	return nil
+}
+
+func BenchmarkLoadDIMACSReader(b *testing.B) {
+	b.SetBytes(int64(len(benchInstance)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		cc := clauseCounter{}
+		if err := LoadDIMACSReader(bytes.NewReader(benchInstance), &cc); err != nil {
+			b.Fatalf("LoadDIMACSReader(): want no error, got %s", err)
+		}
+		if cc.clauses != 8520 {
+			b.Fatalf("LoadDIMACSReader(): want 8520 clauses, got %d", cc.clauses)
+		}
+	}
+}
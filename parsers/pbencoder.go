@@ -0,0 +1,116 @@
+package parsers
+
+import (
+	"sort"
+
+	"github.com/rhartert/yass/sat"
+)
+
+// pbEncoder translates pseudo-Boolean constraints into CNF using the BDD-based
+// encoding described in "Translating Pseudo-Boolean Constraints into SAT" by
+// Eén and Sörensson (2006).
+type pbEncoder struct {
+	// Normalized terms of the constraint being encoded and the suffix sums of
+	// their coefficients (i.e. suffix[i] is the sum of terms[i:]).
+	terms  []PBTerm
+	suffix []int64
+
+	// BDD nodes already encoded for the current constraint.
+	nodes map[bddKey]bddNode
+}
+
+type bddKey struct {
+	index   int
+	atLeast int64
+}
+
+// bddNode represents the output of a BDD node, either a constant or a literal
+// that is implied to be true if the node's constraint is required to be true.
+type bddNode struct {
+	isConst bool
+	value   bool
+	lit     sat.Literal
+}
+
+var (
+	bddTrue  = bddNode{isConst: true, value: true}
+	bddFalse = bddNode{isConst: true, value: false}
+)
+
+// encodeAtLeast adds the clauses of constraint sum(terms) >= k to the solver.
+func (e *pbEncoder) encodeAtLeast(solver SATSolver, terms []PBTerm, k int64) error {
+	// Normalize the constraint to only have positive coefficients by replacing
+	// each term -a*l by a*(~l) - a.
+	e.terms = e.terms[:0]
+	for _, t := range terms {
+		switch {
+		case t.Coef > 0:
+			e.terms = append(e.terms, t)
+		case t.Coef < 0:
+			k -= t.Coef
+			e.terms = append(e.terms, PBTerm{Coef: -t.Coef, Lit: t.Lit.Opposite()})
+		}
+	}
+
+	// Larger coefficients first tend to produce smaller BDDs.
+	sort.SliceStable(e.terms, func(i, j int) bool {
+		return e.terms[i].Coef > e.terms[j].Coef
+	})
+
+	e.suffix = append(e.suffix[:0], make([]int64, len(e.terms)+1)...)
+	for i := len(e.terms) - 1; i >= 0; i-- {
+		e.suffix[i] = e.suffix[i+1] + e.terms[i].Coef
+	}
+
+	e.nodes = map[bddKey]bddNode{}
+	root := e.encodeNode(solver, 0, k)
+
+	switch {
+	case root == bddTrue:
+		return nil
+	case root == bddFalse:
+		return solver.AddClause([]sat.Literal{})
+	default:
+		return solver.AddClause([]sat.Literal{root.lit})
+	}
+}
+
+// encodeNode returns the node representing constraint sum(terms[i:]) >= k.
+func (e *pbEncoder) encodeNode(solver SATSolver, i int, k int64) bddNode {
+	if k <= 0 {
+		return bddTrue
+	}
+	if e.suffix[i] < k {
+		return bddFalse
+	}
+
+	key := bddKey{i, k}
+	if n, ok := e.nodes[key]; ok {
+		return n
+	}
+
+	t := e.terms[i]
+	hi := e.encodeNode(solver, i+1, k-t.Coef) // t.Lit is true
+	lo := e.encodeNode(solver, i+1, k)        // t.Lit is false
+	if hi == lo {
+		e.nodes[key] = hi
+		return hi
+	}
+
+	// Node o is such that o => ite(t.Lit, hi, lo). As lo implies hi, this is
+	// equivalent to (o => hi) and (o => t.Lit v lo).
+	o := sat.PositiveLiteral(solver.AddVariable())
+	if !hi.isConst {
+		solver.AddClause([]sat.Literal{o.Opposite(), hi.lit})
+	}
+	switch {
+	case lo == bddFalse:
+		solver.AddClause([]sat.Literal{o.Opposite(), t.Lit})
+	case !lo.isConst:
+		solver.AddClause([]sat.Literal{o.Opposite(), t.Lit, lo.lit})
+	}
+
+	n := bddNode{lit: o}
+	e.nodes[key] = n
+	return n
+}
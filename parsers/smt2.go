@@ -0,0 +1,489 @@
+package parsers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/rhartert/yass/sat"
+)
+
+// LoadSMT2 parses the SMT-LIB2 file and loads its assertions in the given SAT
+// solver. See LoadSMT2Reader.
+func LoadSMT2(filename string, gzipped bool, solver SATSolver) error {
+	reader, err := reader(filename, gzipped)
+	if err != nil {
+		return fmt.Errorf("error reading file %q: %s", filename, err)
+	}
+	defer reader.Close()
+
+	return LoadSMT2Reader(reader, solver)
+}
+
+// LoadSMT2Reader parses the SMT-LIB2 script read from r and loads its
+// assertions in the given solver. Only the propositional fragment of the
+// language is supported: Bool constants (declare-const, or declare-fun and
+// define-fun without arguments) and the core operators not, and, or, xor, =>,
+// =, distinct and ite, along with let bindings and annotations.
+//
+// Each declared constant is a variable of the solver, added in the order of
+// the declarations and named after its symbol if the solver supports names.
+// The assertions are Tseitin-encoded, which adds auxiliary variables after the
+// declared ones. Commands that do not change the problem (e.g. set-logic,
+// check-sat, get-model) are ignored while those that require incremental
+// solving (e.g. push, pop) are rejected.
+func LoadSMT2Reader(r io.Reader, solver SATSolver) error {
+	// Scripts are read at once as, contrary to CNF files, they are small in
+	// practice and their expressions can span any number of lines.
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	p := smt2Parser{
+		tseitin:   tseitin{solver: solver},
+		vars:      map[string]sat.Literal{},
+		defs:      map[string]sexpr{},
+		expanding: map[string]bool{},
+	}
+	sc := &smt2Scanner{data: data, line: 1}
+	for {
+		cmd, err := sc.readExpr()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := p.command(cmd); err != nil {
+			return err
+		}
+	}
+
+	// Assertions are only encoded once all the problem's constants have been
+	// declared so that the encoding's auxiliary variables come after them.
+	for _, a := range p.asserts {
+		if err := p.assert(a); err != nil {
+			return err
+		}
+		if p.err != nil {
+			return p.err
+		}
+	}
+	return nil
+}
+
+// sexpr is an S-expression: either an atom (i.e. a symbol, keyword or literal)
+// or a list of S-expressions.
+type sexpr struct {
+	atom string
+	list []sexpr
+	line int // line on which the expression starts
+}
+
+func (e sexpr) isAtom() bool {
+	return e.list == nil
+}
+
+// head returns the atom at the start of the list, if any.
+func (e sexpr) head() string {
+	if e.isAtom() || len(e.list) == 0 || !e.list[0].isAtom() {
+		return ""
+	}
+	return e.list[0].atom
+}
+
+// errorf returns a *ParseError located at the expression.
+func (e sexpr) errorf(format string, args ...any) error {
+	return &ParseError{Line: e.line, Token: e.head(), Err: fmt.Errorf(format, args...)}
+}
+
+// smt2Scanner splits an SMT-LIB2 script into S-expressions.
+type smt2Scanner struct {
+	data []byte
+	pos  int
+	line int
+}
+
+// readExpr reads the next S-expression. It returns io.EOF if there is none.
+func (sc *smt2Scanner) readExpr() (sexpr, error) {
+	tok, line, err := sc.readToken()
+	if err != nil {
+		return sexpr{}, err
+	}
+	return sc.exprFrom(tok, line)
+}
+
+// exprFrom reads the S-expression that starts with the given token.
+func (sc *smt2Scanner) exprFrom(tok string, line int) (sexpr, error) {
+	switch tok {
+	case ")":
+		return sexpr{}, &ParseError{Line: line, Token: tok, Err: errors.New("unexpected closing parenthesis")}
+	case "(":
+		e := sexpr{list: []sexpr{}, line: line}
+		for {
+			tok, subLine, err := sc.readToken()
+			if err == io.EOF {
+				return sexpr{}, &ParseError{Line: line, Err: errors.New("missing closing parenthesis")}
+			}
+			if err != nil {
+				return sexpr{}, err
+			}
+			if tok == ")" {
+				return e, nil
+			}
+			sub, err := sc.exprFrom(tok, subLine)
+			if err != nil {
+				return sexpr{}, err
+			}
+			e.list = append(e.list, sub)
+		}
+	default:
+		// Quoted symbols are the same as their unquoted counterparts.
+		if len(tok) >= 2 && tok[0] == '|' {
+			tok = tok[1 : len(tok)-1]
+		}
+		return sexpr{atom: tok, line: line}, nil
+	}
+}
+
+// readToken reads the next parenthesis or atom, along with the line it starts
+// on. It returns io.EOF if there are no more tokens.
+func (sc *smt2Scanner) readToken() (string, int, error) {
+	for sc.pos < len(sc.data) {
+		switch c := sc.data[sc.pos]; {
+		case c == '\n':
+			sc.line++
+			sc.pos++
+		case c == ' ' || c == '\t' || c == '\r':
+			sc.pos++
+		case c == ';': // comment up to the end of the line
+			for sc.pos < len(sc.data) && sc.data[sc.pos] != '\n' {
+				sc.pos++
+			}
+		default:
+			return sc.readAtom()
+		}
+	}
+	return "", sc.line, io.EOF
+}
+
+// readAtom reads the token starting at the current position, which is not a
+// whitespace nor a comment.
+func (sc *smt2Scanner) readAtom() (string, int, error) {
+	line := sc.line
+	start := sc.pos
+	switch sc.data[start] {
+	case '(', ')':
+		sc.pos++
+		return string(sc.data[start:sc.pos]), line, nil
+	case '|', '"':
+		// Quoted symbols and strings can span several lines. Quotes are
+		// escaped by doubling them in strings.
+		quote := sc.data[start]
+		for sc.pos++; sc.pos < len(sc.data); sc.pos++ {
+			switch c := sc.data[sc.pos]; {
+			case c == '\n':
+				sc.line++
+			case c == quote && quote == '"' && sc.pos+1 < len(sc.data) && sc.data[sc.pos+1] == '"':
+				sc.pos++
+			case c == quote:
+				sc.pos++
+				return string(sc.data[start:sc.pos]), line, nil
+			}
+		}
+		return "", line, &ParseError{Line: line, Err: fmt.Errorf("unterminated %c", quote)}
+	}
+	for sc.pos < len(sc.data) {
+		switch sc.data[sc.pos] {
+		case ' ', '\t', '\r', '\n', '(', ')', ';', '|', '"':
+			return string(sc.data[start:sc.pos]), line, nil
+		}
+		sc.pos++
+	}
+	return string(sc.data[start:]), line, nil
+}
+
+// smt2Parser processes the commands of an SMT-LIB2 script.
+type smt2Parser struct {
+	tseitin
+
+	vars      map[string]sat.Literal // declared constants
+	defs      map[string]sexpr       // bodies of the defined constants
+	expanding map[string]bool        // definitions being encoded
+	asserts   []sexpr
+
+	// Literals bound by the enclosing let expressions, innermost last.
+	scopes []map[string]sat.Literal
+}
+
+func (p *smt2Parser) command(cmd sexpr) error {
+	if cmd.isAtom() {
+		return cmd.errorf("expected a command, got %q", cmd.atom)
+	}
+	if len(cmd.list) == 0 {
+		return cmd.errorf("expected a command")
+	}
+	args := cmd.list[1:]
+	switch name := cmd.head(); name {
+	case "set-logic", "set-info", "set-option", "get-info", "check-sat", "get-model", "get-value", "get-assignment", "echo", "exit":
+		return nil
+	case "declare-const":
+		if len(args) != 2 {
+			return cmd.errorf("expected a symbol and a sort")
+		}
+		return p.declare(cmd, args[0], args[1])
+	case "declare-fun":
+		if len(args) != 3 || args[1].isAtom() {
+			return cmd.errorf("expected a symbol, a list of argument sorts and a sort")
+		}
+		if len(args[1].list) != 0 {
+			return cmd.errorf("functions with arguments are not supported")
+		}
+		return p.declare(cmd, args[0], args[2])
+	case "define-fun":
+		if len(args) != 4 || args[1].isAtom() {
+			return cmd.errorf("expected a symbol, a list of arguments, a sort and a term")
+		}
+		if len(args[1].list) != 0 {
+			return cmd.errorf("functions with arguments are not supported")
+		}
+		if err := p.checkSymbol(cmd, args[0], args[2]); err != nil {
+			return err
+		}
+		p.defs[args[0].atom] = args[3]
+		return nil
+	case "assert":
+		if len(args) != 1 {
+			return cmd.errorf("expected a single term")
+		}
+		p.asserts = append(p.asserts, args[0])
+		return nil
+	case "":
+		return cmd.errorf("expected a command name")
+	default:
+		return cmd.errorf("unsupported command %q", name)
+	}
+}
+
+// checkSymbol returns an error if the symbol cannot be declared with the given
+// sort.
+func (p *smt2Parser) checkSymbol(cmd sexpr, symbol sexpr, sort sexpr) error {
+	if !symbol.isAtom() {
+		return cmd.errorf("invalid symbol")
+	}
+	if !sort.isAtom() || sort.atom != "Bool" {
+		return cmd.errorf("unsupported sort of %q: only Bool is supported", symbol.atom)
+	}
+	_, declared := p.vars[symbol.atom]
+	_, defined := p.defs[symbol.atom]
+	if declared || defined {
+		return cmd.errorf("symbol %q already declared", symbol.atom)
+	}
+	return nil
+}
+
+// declare adds a variable to the solver for the constant symbol.
+func (p *smt2Parser) declare(cmd sexpr, symbol sexpr, sort sexpr) error {
+	if err := p.checkSymbol(cmd, symbol, sort); err != nil {
+		return err
+	}
+	v := p.solver.AddVariable()
+	p.vars[symbol.atom] = sat.PositiveLiteral(v)
+	if n, ok := p.solver.(namer); ok {
+		n.NameVariable(v, symbol.atom)
+	}
+	return nil
+}
+
+// assert adds the clauses that force term e to be true. Conjunctions and
+// disjunctions are asserted directly instead of through an auxiliary literal.
+func (p *smt2Parser) assert(e sexpr) error {
+	switch e.head() {
+	case "and":
+		for _, a := range e.list[1:] {
+			if err := p.assert(a); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "or":
+		lits, err := p.terms(e.list[1:])
+		if err != nil {
+			return err
+		}
+		p.addClause(lits...)
+		return nil
+	}
+	l, err := p.term(e)
+	if err != nil {
+		return err
+	}
+	p.addClause(l)
+	return nil
+}
+
+// terms returns the literals of the terms.
+func (p *smt2Parser) terms(es []sexpr) ([]sat.Literal, error) {
+	lits := make([]sat.Literal, len(es))
+	for i, e := range es {
+		l, err := p.term(e)
+		if err != nil {
+			return nil, err
+		}
+		lits[i] = l
+	}
+	return lits, nil
+}
+
+// term returns a literal that is equivalent to term e.
+func (p *smt2Parser) term(e sexpr) (sat.Literal, error) {
+	if e.isAtom() {
+		return p.symbol(e)
+	}
+	if len(e.list) == 0 {
+		return 0, e.errorf("expected a term")
+	}
+
+	name := e.head()
+	if name == "let" {
+		return p.let(e)
+	}
+	if name == "!" { // annotated term
+		if len(e.list) < 2 {
+			return 0, e.errorf("missing annotated term")
+		}
+		return p.term(e.list[1])
+	}
+
+	lits, err := p.terms(e.list[1:])
+	if err != nil {
+		return 0, err
+	}
+	arity := func(min int) error {
+		if len(lits) < min {
+			return e.errorf("expected at least %d arguments, got %d", min, len(lits))
+		}
+		return nil
+	}
+
+	switch name {
+	case "not":
+		if len(lits) != 1 {
+			return 0, e.errorf("expected 1 argument, got %d", len(lits))
+		}
+		return lits[0].Opposite(), nil
+	case "and":
+		return p.and(lits), nil
+	case "or":
+		return p.or(lits), nil
+	case "xor":
+		if err := arity(2); err != nil {
+			return 0, err
+		}
+		l := lits[0]
+		for _, m := range lits[1:] {
+			l = p.xor(l, m)
+		}
+		return l, nil
+	case "=>": // right associative
+		if err := arity(2); err != nil {
+			return 0, err
+		}
+		l := lits[len(lits)-1]
+		for i := len(lits) - 2; i >= 0; i-- {
+			l = p.or([]sat.Literal{lits[i].Opposite(), l})
+		}
+		return l, nil
+	case "=": // chainable
+		if err := arity(2); err != nil {
+			return 0, err
+		}
+		eqs := make([]sat.Literal, len(lits)-1)
+		for i := range eqs {
+			eqs[i] = p.xor(lits[i], lits[i+1]).Opposite()
+		}
+		return p.and(eqs), nil
+	case "distinct":
+		if err := arity(2); err != nil {
+			return 0, err
+		}
+		if len(lits) > 2 { // three Booleans cannot be pairwise distinct
+			return p.constant(false), nil
+		}
+		return p.xor(lits[0], lits[1]), nil
+	case "ite":
+		if len(lits) != 3 {
+			return 0, e.errorf("expected 3 arguments, got %d", len(lits))
+		}
+		return p.ite(lits[0], lits[1], lits[2]), nil
+	case "":
+		return 0, e.errorf("expected an operator")
+	default:
+		return 0, e.errorf("unsupported operator %q", name)
+	}
+}
+
+// symbol returns the literal of a constant symbol, either bound by a let
+// expression, declared, or defined.
+func (p *smt2Parser) symbol(e sexpr) (sat.Literal, error) {
+	switch e.atom {
+	case "true":
+		return p.constant(true), nil
+	case "false":
+		return p.constant(false), nil
+	}
+	for i := len(p.scopes) - 1; i >= 0; i-- {
+		if l, ok := p.scopes[i][e.atom]; ok {
+			return l, nil
+		}
+	}
+	if l, ok := p.vars[e.atom]; ok {
+		return l, nil
+	}
+	if body, ok := p.defs[e.atom]; ok {
+		// Definitions are encoded on their first use, outside of the scope of
+		// the let expressions in which they are used. As bodies can refer to
+		// symbols defined later, a definition that is used while it is being
+		// encoded is cyclic.
+		if p.expanding[e.atom] {
+			return 0, &ParseError{Line: e.line, Token: e.atom, Err: errors.New("cyclic definition")}
+		}
+		p.expanding[e.atom] = true
+		scopes := p.scopes
+		p.scopes = nil
+		l, err := p.term(body)
+		p.scopes = scopes
+		delete(p.expanding, e.atom)
+		if err != nil {
+			return 0, err
+		}
+		delete(p.defs, e.atom)
+		p.vars[e.atom] = l
+		return l, nil
+	}
+	return 0, &ParseError{Line: e.line, Token: e.atom, Err: errors.New("unknown symbol")}
+}
+
+// let returns the literal of let expression e. Its bindings are parallel: the
+// terms they bind are all evaluated in the enclosing scope.
+func (p *smt2Parser) let(e sexpr) (sat.Literal, error) {
+	if len(e.list) != 3 || e.list[1].isAtom() {
+		return 0, e.errorf("expected a list of bindings and a term")
+	}
+	scope := map[string]sat.Literal{}
+	for _, b := range e.list[1].list {
+		if b.isAtom() || len(b.list) != 2 || !b.list[0].isAtom() {
+			return 0, e.errorf("invalid binding")
+		}
+		l, err := p.term(b.list[1])
+		if err != nil {
+			return 0, err
+		}
+		scope[b.list[0].atom] = l
+	}
+	p.scopes = append(p.scopes, scope)
+	l, err := p.term(e.list[2])
+	p.scopes = p.scopes[:len(p.scopes)-1]
+	return l, err
+}
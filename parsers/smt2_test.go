@@ -0,0 +1,107 @@
+package parsers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rhartert/yass/sat"
+)
+
+const testSMT2 = `; propositional SMT-LIB2 script
+(set-logic QF_UF)
+(declare-const a Bool)
+(declare-fun |b| () Bool)
+(declare-const c Bool)
+(define-fun ab () Bool (xor a b))
+(assert (or ab c))
+(assert (=> a b (not c)))
+(assert (let ((x (ite a b c)) (y (distinct a c)))
+  (! (= x y (not (and a b c))) :named eq)))
+(check-sat)
+(get-model)
+(exit)
+`
+
+// satisfiesTestSMT2 returns true if the assignment satisfies testSMT2.
+func satisfiesTestSMT2(x []bool) bool {
+	a, b, c := x[0], x[1], x[2]
+	ite := c
+	if a {
+		ite = b
+	}
+	return ((a != b) || c) &&
+		(!a || !b || !c) &&
+		ite == (a != c) && (a != c) == !(a && b && c)
+}
+
+// TestLoadSMT2Reader_encoding verifies that the CNF encoding of the assertions
+// accepts exactly the assignments that satisfy them.
+func TestLoadSMT2Reader_encoding(t *testing.T) {
+	for mask := 0; mask < 8; mask++ {
+		x := make([]bool, 3)
+		for i := range x {
+			x[i] = mask&(1<<i) != 0
+		}
+
+		s := sat.NewDefaultSolver()
+		if err := LoadSMT2Reader(strings.NewReader(testSMT2), s); err != nil {
+			t.Fatalf("LoadSMT2Reader(): want no error, got %s", err)
+		}
+		for i, v := range x {
+			if v {
+				s.AddClause([]sat.Literal{sat.PositiveLiteral(i)})
+			} else {
+				s.AddClause([]sat.Literal{sat.NegativeLiteral(i)})
+			}
+		}
+
+		want := sat.Lift(satisfiesTestSMT2(x))
+		if got := s.Solve().Status; got != want {
+			t.Errorf("Solve() with assignment %v: want %s, got %s", x, want, got)
+		}
+	}
+}
+
+func TestLoadSMT2Reader_names(t *testing.T) {
+	s := sat.NewDefaultSolver()
+	if err := LoadSMT2Reader(strings.NewReader(testSMT2), s); err != nil {
+		t.Fatalf("LoadSMT2Reader(): want no error, got %s", err)
+	}
+
+	for v, want := range []string{"a", "b", "c"} {
+		if got := s.VariableName(v); got != want {
+			t.Errorf("VariableName(%d): want %q, got %q", v, want, got)
+		}
+	}
+}
+
+func TestLoadSMT2Reader_errors(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		content string
+	}{
+		{"unknown symbol", "(assert x)"},
+		{"non-Bool sort", "(declare-const x Int)"},
+		{"function", "(declare-fun f (Bool) Bool)"},
+		{"redeclaration", "(declare-const x Bool)\n(declare-const x Bool)"},
+		{"unsupported operator", "(declare-const x Bool)\n(assert (bvand x x))"},
+		{"unsupported command", "(push 1)"},
+		{"arity", "(declare-const x Bool)\n(assert (not x x))"},
+		{"missing parenthesis", "(assert (and true"},
+		{"extra parenthesis", "(assert true))"},
+		{"empty command", "()"},
+		{"empty term", "(assert ())"},
+		{"self-referencing definition", "(define-fun x () Bool (not x))\n(assert x)"},
+		{"cyclic definitions", "(define-fun x () Bool y)\n(define-fun y () Bool (and x true))\n(assert x)"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			gotErr := LoadSMT2Reader(strings.NewReader(tc.content), &instance{})
+
+			if gotErr == nil {
+				t.Errorf("LoadSMT2Reader(): want error, got none")
+			}
+		})
+	}
+}
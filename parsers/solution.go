@@ -0,0 +1,85 @@
+package parsers
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Solution is the output of a SAT solver in the SAT competition format, that
+// is an "s" line with the solver's status followed by "v" lines listing the
+// model's literals.
+type Solution struct {
+	Status string // e.g. "SATISFIABLE", empty if there is no status line
+	Values []int  // DIMACS literals of the model, without the terminating 0
+}
+
+// ReadSolution reads the solution printed by a SAT solver. Comment lines are
+// ignored and lines of bare literals (e.g. from .models files) are read as
+// value lines.
+func ReadSolution(r io.Reader) (*Solution, error) {
+	sol := &Solution{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<30)
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "c":
+			continue
+		case "s":
+			if sol.Status != "" {
+				return nil, fmt.Errorf("line %d: duplicate status line", line)
+			}
+			sol.Status = strings.Join(fields[1:], " ")
+			continue
+		case "v":
+			fields = fields[1:]
+		}
+		for _, f := range fields {
+			lit, err := strconv.Atoi(f)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid literal %q", line, f)
+			}
+			if lit != 0 {
+				sol.Values = append(sol.Values, lit)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return sol, nil
+}
+
+// Model returns the model of the solution over n variables. Variables whose
+// value is not listed are false and are reported as unassigned.
+func (sol *Solution) Model(n int) (model []bool, unassigned int, err error) {
+	for _, lit := range sol.Values {
+		if v := max(lit, -lit); v > n {
+			n = v
+		}
+	}
+	model = make([]bool, n)
+	assigned := make([]bool, n)
+	for _, lit := range sol.Values {
+		v := max(lit, -lit) - 1
+		if assigned[v] && model[v] != (lit > 0) {
+			return nil, 0, fmt.Errorf("variable %d is both true and false", v+1)
+		}
+		model[v] = lit > 0
+		assigned[v] = true
+	}
+	for _, a := range assigned {
+		if !a {
+			unassigned++
+		}
+	}
+	return model, unassigned, nil
+}
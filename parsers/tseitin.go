@@ -0,0 +1,104 @@
+package parsers
+
+import "github.com/rhartert/yass/sat"
+
+// tseitin adds the clauses of the Tseitin encoding of Boolean gates to a
+// solver: each gate's output is a literal constrained to be equivalent to the
+// gate's function of its inputs.
+type tseitin struct {
+	solver SATSolver
+
+	// Literal of an auxiliary variable that is true, only added if needed.
+	trueLit sat.Literal
+	hasTrue bool
+
+	err error // first error returned by the solver
+}
+
+// constant returns a literal whose value is v.
+func (t *tseitin) constant(v bool) sat.Literal {
+	if !t.hasTrue {
+		t.trueLit = t.newLiteral()
+		t.hasTrue = true
+		t.addClause(t.trueLit)
+	}
+	if v {
+		return t.trueLit
+	}
+	return t.trueLit.Opposite()
+}
+
+// and returns a literal g such that g <=> (lits[0] ∧ lits[1] ∧ ...).
+func (t *tseitin) and(lits []sat.Literal) sat.Literal {
+	switch len(lits) {
+	case 0:
+		return t.constant(true)
+	case 1:
+		return lits[0]
+	}
+	g := t.newLiteral()
+	t.defineAnd(g, lits)
+	return g
+}
+
+// defineAnd adds the clauses of g <=> (lits[0] ∧ lits[1] ∧ ...).
+func (t *tseitin) defineAnd(g sat.Literal, lits []sat.Literal) {
+	clause := make([]sat.Literal, 0, len(lits)+1)
+	clause = append(clause, g)
+	for _, l := range lits {
+		t.addClause(g.Opposite(), l)
+		clause = append(clause, l.Opposite())
+	}
+	t.addClause(clause...)
+}
+
+// or returns a literal g such that g <=> (lits[0] ∨ lits[1] ∨ ...).
+func (t *tseitin) or(lits []sat.Literal) sat.Literal {
+	return t.and(negated(lits)).Opposite()
+}
+
+// xor returns a literal g such that g <=> (a ⊕ b).
+func (t *tseitin) xor(a, b sat.Literal) sat.Literal {
+	g := t.newLiteral()
+	t.defineXor(g, a, b)
+	return g
+}
+
+// defineXor adds the clauses of g <=> (a ⊕ b).
+func (t *tseitin) defineXor(g, a, b sat.Literal) {
+	t.addClause(g.Opposite(), a, b)
+	t.addClause(g.Opposite(), a.Opposite(), b.Opposite())
+	t.addClause(g, a.Opposite(), b)
+	t.addClause(g, a, b.Opposite())
+}
+
+// ite returns a literal g such that g <=> (c ? x : y).
+func (t *tseitin) ite(c, x, y sat.Literal) sat.Literal {
+	g := t.newLiteral()
+	t.addClause(c.Opposite(), x.Opposite(), g)
+	t.addClause(c.Opposite(), x, g.Opposite())
+	t.addClause(c, y.Opposite(), g)
+	t.addClause(c, y, g.Opposite())
+	return g
+}
+
+func (t *tseitin) newLiteral() sat.Literal {
+	return sat.PositiveLiteral(t.solver.AddVariable())
+}
+
+// addClause adds the clause to the solver. The first error returned by the
+// solver is kept in t.err.
+func (t *tseitin) addClause(lits ...sat.Literal) {
+	if err := t.solver.AddClause(lits); err != nil && t.err == nil {
+		t.err = err
+	}
+}
+
+// negated returns the negation of the literals.
+func negated(lits []sat.Literal) []sat.Literal {
+	neg := make([]sat.Literal, len(lits))
+	for i, l := range lits {
+		neg[i] = l.Opposite()
+	}
+	return neg
+}
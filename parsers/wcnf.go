@@ -0,0 +1,119 @@
+package parsers
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/rhartert/yass/sat"
+)
+
+// SoftClause is a weighted clause of a MaxSAT problem. Soft clauses do not have
+// to be satisfied but falsifying them costs their weight.
+type SoftClause struct {
+	Weight   uint64
+	Literals []sat.Literal
+}
+
+// LoadWCNF parses the WCNF file and loads the hard clauses of its MaxSAT
+// problem in the given solver. The soft clauses are returned in the order in
+// which they appear in the file.
+func LoadWCNF(filename string, gzipped bool, solver SATSolver) ([]SoftClause, error) {
+	reader, err := reader(filename, gzipped)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %q: %s", filename, err)
+	}
+	defer reader.Close()
+
+	return LoadWCNFReader(reader, solver)
+}
+
+// LoadWCNFReader parses the WCNF problem read from r. Both the classic format
+// (with a "p wcnf" problem line and hard clauses identified by the top weight)
+// and the 2022 MaxSAT Evaluation format (without problem line and with hard
+// clauses prefixed by "h") are supported. Hard clauses are loaded in the given
+// solver and soft clauses are returned.
+func LoadWCNFReader(r io.Reader, solver SATSolver) ([]SoftClause, error) {
+	p := wcnfParser{clauseParser: clauseParser{solver: solver}, top: math.MaxUint64}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, math.MaxInt32)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		text := scanner.Text()
+		line := strings.TrimSpace(text)
+		if line == "" || line[0] == 'c' {
+			continue
+		}
+		if err := p.parseLine(tokenize(text)); err != nil {
+			return nil, atLine(err, lineNum, text)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return p.softs, nil
+}
+
+type wcnfParser struct {
+	clauseParser
+	top        uint64 // weight from which clauses are hard
+	hasProblem bool
+	softs      []SoftClause
+}
+
+func (p *wcnfParser) parseLine(tokens []token) error {
+	if tokens[0].text == "p" {
+		return p.parseProblem(tokens)
+	}
+
+	if err := p.parseClause(tokens[1:]); err != nil {
+		return err
+	}
+	if tokens[0].text == "h" {
+		return p.solver.AddClause(p.clause)
+	}
+
+	w, err := strconv.ParseUint(tokens[0].text, 10, 64)
+	if err != nil {
+		return tokenError(tokens[0], errors.New("invalid weight"))
+	}
+	if w >= p.top {
+		return p.solver.AddClause(p.clause)
+	}
+
+	lits := make([]sat.Literal, len(p.clause))
+	copy(lits, p.clause)
+	p.softs = append(p.softs, SoftClause{Weight: w, Literals: lits})
+	return nil
+}
+
+func (p *wcnfParser) parseProblem(tokens []token) error {
+	if p.hasProblem {
+		return fmt.Errorf("duplicate problem line")
+	}
+	if len(tokens) != 4 && len(tokens) != 5 {
+		return fmt.Errorf("problem line should have 4 or 5 parts, got %d", len(tokens))
+	}
+	if tokens[1].text != "wcnf" {
+		return tokenError(tokens[1], errors.New("not a WCNF problem"))
+	}
+	nVars, err := strconv.Atoi(tokens[2].text)
+	if err != nil {
+		return tokenError(tokens[2], errors.New("invalid number of variables"))
+	}
+	if len(tokens) == 5 {
+		top, err := strconv.ParseUint(tokens[4].text, 10, 64)
+		if err != nil {
+			return tokenError(tokens[4], errors.New("invalid top weight"))
+		}
+		p.top = top
+	}
+	p.hasProblem = true
+	p.growVars(nVars)
+	return nil
+}
@@ -0,0 +1,84 @@
+package parsers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rhartert/yass/sat"
+)
+
+var wantHard = instance{
+	Variables: 3,
+	Clauses: [][]sat.Literal{
+		{0, 2},
+		{1, 4},
+	},
+}
+
+var wantSoft = []SoftClause{
+	{Weight: 3, Literals: []sat.Literal{1}},
+	{Weight: 1, Literals: []sat.Literal{3, 5}},
+}
+
+func TestLoadWCNFReader_classic(t *testing.T) {
+	r := strings.NewReader(`c classic format
+p wcnf 3 4 10
+10 1 2 0
+3 -1 0
+1 -2 -3 0
+10 -1 3 0
+`)
+	got := instance{}
+	gotSoft, gotErr := LoadWCNFReader(r, &got)
+
+	if gotErr != nil {
+		t.Errorf("LoadWCNFReader(): want no error, got %s", gotErr)
+	}
+	if diff := cmp.Diff(wantHard, got); diff != "" {
+		t.Errorf("LoadWCNFReader(): hard mismatch (+want, -got):\n%s", diff)
+	}
+	if diff := cmp.Diff(wantSoft, gotSoft); diff != "" {
+		t.Errorf("LoadWCNFReader(): soft mismatch (+want, -got):\n%s", diff)
+	}
+}
+
+func TestLoadWCNFReader_2022(t *testing.T) {
+	r := strings.NewReader(`c 2022 format
+h 1 2 0
+3 -1 0
+1 -2 -3 0
+h -1 3 0
+`)
+	got := instance{}
+	gotSoft, gotErr := LoadWCNFReader(r, &got)
+
+	if gotErr != nil {
+		t.Errorf("LoadWCNFReader(): want no error, got %s", gotErr)
+	}
+	if diff := cmp.Diff(wantHard, got); diff != "" {
+		t.Errorf("LoadWCNFReader(): hard mismatch (+want, -got):\n%s", diff)
+	}
+	if diff := cmp.Diff(wantSoft, gotSoft); diff != "" {
+		t.Errorf("LoadWCNFReader(): soft mismatch (+want, -got):\n%s", diff)
+	}
+}
+
+func TestLoadWCNFReader_invalidWeight(t *testing.T) {
+	r := strings.NewReader("x 1 2 0\n")
+	_, gotErr := LoadWCNFReader(r, &instance{})
+
+	if gotErr == nil {
+		t.Errorf("LoadWCNFReader(): want error, got none")
+	}
+}
+
+func TestLoadWCNFReader_unterminatedClause(t *testing.T) {
+	for _, text := range []string{"h 1 2\n", "3 -1\n", "p wcnf 3 1 10\n10 1 2\n", "3\n"} {
+		_, gotErr := LoadWCNFReader(strings.NewReader(text), &instance{})
+
+		if gotErr == nil {
+			t.Errorf("LoadWCNFReader(%q): want error, got none", text)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"runtime/pprof"
+	"sync"
+	"time"
+)
+
+// heapProfiler writes numbered heap profiles (memprof.1, memprof.2, ...) so
+// that the memory growth of a long run can be inspected.
+type heapProfiler struct {
+	mu sync.Mutex
+	n  int
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// write writes the next numbered heap profile. Errors are logged as profiles
+// are written in the background.
+func (hp *heapProfiler) write() {
+	hp.mu.Lock()
+	defer hp.mu.Unlock()
+
+	hp.n++
+	f, err := os.Create(fmt.Sprintf("memprof.%d", hp.n))
+	if err != nil {
+		log.Printf("could not write heap profile: %s", err)
+		return
+	}
+	defer f.Close()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		log.Printf("could not write heap profile: %s", err)
+	}
+}
+
+// start writes a heap profile every interval until stop is called.
+func (hp *heapProfiler) start(interval time.Duration) {
+	hp.done = make(chan struct{})
+	hp.wg.Add(1)
+	go func() {
+		defer hp.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				hp.write()
+			case <-hp.done:
+				return
+			}
+		}
+	}()
+}
+
+// stop stops writing periodic heap profiles (if started).
+func (hp *heapProfiler) stop() {
+	if hp.done != nil {
+		close(hp.done)
+		hp.wg.Wait()
+	}
+}
@@ -1,24 +1,73 @@
 package sat
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// EMA is an exponential moving average. Its value is bias-corrected as in
+// Kissat: the average starts from 0 and is divided by 1-decay^n after n
+// samples, which gives the first samples their actual weight instead of
+// either ignoring the initial 0 or over-weighting the first sample.
 type EMA struct {
-	decay float64
-	value float64
-	init  bool
+	decay  float64
+	biased float64 // uncorrected average
+	exp    float64 // decay^n, or 0 once the correction is negligible
+	value  float64
 }
 
 func NewEMA(decay float64) EMA {
-	return EMA{decay: decay}
+	return EMA{decay: decay, exp: 1}
 }
 
 func (ema *EMA) Add(x float64) {
-	if !ema.init {
-		ema.init = true
-		ema.value = x
-	} else {
-		ema.value = ema.decay*ema.value + x*(1-ema.decay)
+	ema.biased += (1 - ema.decay) * (x - ema.biased)
+	if ema.exp == 0 {
+		ema.value = ema.biased
+		return
+	}
+	ema.exp *= ema.decay
+	if ema.exp < 1e-12 {
+		ema.exp = 0 // stop correcting once 1-exp rounds to 1
+		ema.value = ema.biased
+		return
 	}
+	ema.value = ema.biased / (1 - ema.exp)
 }
 
 func (ema *EMA) Val() float64 {
 	return ema.value
 }
+
+// MarshalJSON encodes the average as its current value.
+func (ema EMA) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ema.value)
+}
+
+// Averages configures the decays of the exponential moving averages that the
+// solver maintains on conflicts. Smaller decays make the averages follow the
+// recent samples more closely.
+type Averages struct {
+	ConflictLevel float64 // see Statistics.AvgConflictLevel
+	SlowLBD       float64 // see Statistics.AvgLearntLBD
+	FastLBD       float64 // see Statistics.AvgFastLBD
+	Trail         float64 // see Statistics.AvgTrail
+}
+
+// validate returns an error if the averages configuration is invalid.
+func (a Averages) validate() error {
+	for _, d := range []struct {
+		name  string
+		decay float64
+	}{
+		{"conflict level", a.ConflictLevel},
+		{"slow LBD", a.SlowLBD},
+		{"fast LBD", a.FastLBD},
+		{"trail", a.Trail},
+	} {
+		if d.decay <= 0 || d.decay >= 1 {
+			return fmt.Errorf("%s average decay must be in (0, 1), got %v", d.name, d.decay)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package sat
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEMA(t *testing.T) {
+	ema := NewEMA(0.9)
+	ema.Add(10)
+	if got := ema.Val(); got != 10 {
+		t.Errorf("Val() after the first sample: got %v, want 10", got)
+	}
+
+	// The average of a constant stream is the constant, without bias towards
+	// the initial 0.
+	ema = NewEMA(0.99)
+	for i := 0; i < 5; i++ {
+		ema.Add(3)
+	}
+	if got := ema.Val(); math.Abs(got-3) > 1e-9 {
+		t.Errorf("Val() of a constant stream: got %v, want 3", got)
+	}
+
+	// The second sample weighs 1/(1+decay) of the corrected average.
+	ema = NewEMA(0.5)
+	ema.Add(0)
+	ema.Add(3)
+	if got := ema.Val(); math.Abs(got-2) > 1e-9 {
+		t.Errorf("Val() after two samples: got %v, want 2", got)
+	}
+
+	// The correction stops once negligible.
+	ema = NewEMA(0.5)
+	for i := 0; i < 100; i++ {
+		ema.Add(float64(i % 2))
+	}
+	if ema.exp != 0 || ema.Val() != ema.biased {
+		t.Errorf("correction still applied after 100 samples: exp = %v", ema.exp)
+	}
+}
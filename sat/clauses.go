@@ -28,6 +28,16 @@ type Clause struct {
 	// The literal block distance used to estimate the quality of the clause.
 	lbd uint32
 
+	// Tier of the clause in the clause DB (see Solver.setTier).
+	tier Tier
+
+	// Whether the clause propagated or took part in a conflict analysis since
+	// the last reduction of the clause DB, and the number of reductions since
+	// it last did (see Reductions.DemoteAfter). The count of a demoted clause
+	// is only reset once it is promoted again.
+	used bool
+	idle uint8
+
 	// If true, the clause will not be deleted in the next clause DB clean up.
 	// This is only relevant to learnt clauses.
 	statusMask status
@@ -53,22 +63,24 @@ func NewClause(s *Solver, tmpLiterals []Literal, learnt bool) (*Clause, bool) {
 	size := len(tmpLiterals)
 
 	if !learnt {
-		seen := map[Literal]struct{}{}
+		seen := &s.seenLit
+		seen.Clear()
 
 		for i := size - 1; i >= 0; i-- {
 			// If the opposite literal is in the clause, then the clause is
 			// always true.
-			if _, ok := seen[tmpLiterals[i].Opposite()]; ok {
+			if seen.Contains(int(tmpLiterals[i].Opposite())) {
 				return nil, true
 			}
 
 			// Remove the literal if it is already present.
-			if _, ok := seen[tmpLiterals[i]]; ok {
+			if seen.Contains(int(tmpLiterals[i])) {
 				size--
 				tmpLiterals[i], tmpLiterals[size] = tmpLiterals[size], tmpLiterals[i]
+				continue
 			}
 
-			seen[tmpLiterals[i]] = struct{}{}
+			seen.Add(int(tmpLiterals[i]))
 
 			switch s.LitValue(tmpLiterals[i]) {
 			case True:
@@ -79,6 +91,12 @@ func NewClause(s *Solver, tmpLiterals []Literal, learnt bool) (*Clause, bool) {
 			}
 		}
 
+		// Clauses simplified with root-level assignments are derived from the
+		// original clause and must be added to the proof.
+		if s.proof != nil && size < len(tmpLiterals) {
+			s.proof.add(tmpLiterals[:size])
+		}
+
 		tmpLiterals = tmpLiterals[:size]
 	}
 
@@ -90,26 +108,37 @@ func NewClause(s *Solver, tmpLiterals []Literal, learnt bool) (*Clause, bool) {
 		// Directly enqueue unit facts.
 		return nil, s.enqueue(tmpLiterals[0], nil)
 	default:
-		// Actually create the clause.
-		c := &Clause{
-			prevPos:  2, // no previous literal
-			literals: make([]Literal, size),
+		// Actually create the clause. Learnt clauses are recycled from the
+		// ones deleted by ReduceDB if possible.
+		var c *Clause
+		if learnt {
+			c = s.pool.get(size)
+		} else {
+			c = &Clause{literals: make([]Literal, size)}
 		}
+		c.prevPos = 2 // no previous literal
 
 		copy(c.literals, tmpLiterals)
+		s.memory.addClause(cap(c.literals))
 
 		if learnt {
 			c.statusMask |= statusLearnt
-
-			maxLevel := -1
-			wl := -1
-			for i, lit := range c.literals {
-				if level := s.assignLevels[lit.VarID()]; level > maxLevel {
-					maxLevel = level
-					wl = i
+			c.tier = TierLocal
+
+			// Watch the false literal of highest level, which is the first to
+			// become unassigned when backtracking. This is not needed if the
+			// clause starts with two unassigned literals (see ExtraLearnts).
+			if s.LitValue(c.literals[1]) == False {
+				maxLevel := -1
+				wl := -1
+				for i, lit := range c.literals {
+					if level := s.assignLevels[lit.VarID()]; level > maxLevel {
+						maxLevel = level
+						wl = i
+					}
 				}
+				c.literals[wl], c.literals[1] = c.literals[1], c.literals[wl]
 			}
-			c.literals[wl], c.literals[1] = c.literals[1], c.literals[wl]
 		}
 
 		s.Watch(c, c.literals[0].Opposite(), c.literals[1])
@@ -128,6 +157,10 @@ func (c *Clause) Delete(s *Solver) {
 
 	s.Unwatch(c, c.literals[0].Opposite())
 	s.Unwatch(c, c.literals[1].Opposite())
+	s.memory.removeClause(cap(c.literals))
+	if s.proof != nil {
+		s.proof.delete(c.literals)
+	}
 
 	// Cut the reference to the slice of literals so that it can be garbage
 	// collected even if the clause itself is still referenced.
@@ -135,6 +168,10 @@ func (c *Clause) Delete(s *Solver) {
 }
 
 func (c *Clause) Simplify(s *Solver) bool {
+	if s.proof != nil {
+		s.tmpReason = append(s.tmpReason[:0], c.literals...)
+	}
+
 	k := 0
 	for _, lit := range c.literals {
 		v := s.LitValue(lit)
@@ -143,16 +180,28 @@ func (c *Clause) Simplify(s *Solver) bool {
 			return true
 		case False:
 			// discard the literal.
+			if s.occurs != nil {
+				s.occurs.removeLiteral(c, lit)
+			}
 		case Unknown:
 			c.literals[k] = lit
 			k++
 		}
 	}
+	if s.proof != nil && k < len(c.literals) {
+		s.proof.add(c.literals[:k])
+		s.proof.delete(s.tmpReason)
+	}
 	c.literals = c.literals[:k]
 	return false
 }
 
-func (c *Clause) Propagate(s *Solver, l Literal) bool {
+// Propagate updates the clause after the opposite of its literal l became
+// false (i.e. l became true). It returns false if the clause is conflicting.
+// The clause either moves its watch to another literal, in which case it is
+// added to the corresponding watch list and keep is false, or remains in the
+// watch list of l with c.literals[0] as guard, in which case keep is true.
+func (c *Clause) Propagate(s *Solver, l Literal) (keep bool, ok bool) {
 	// Make sure that the triggering literal is c.literals[1]. This simplifies
 	// the rest of this function as c.literals[0] is always the literal to be
 	// potentially enqueued (if all other literals are false).
@@ -164,8 +213,7 @@ func (c *Clause) Propagate(s *Solver, l Literal) bool {
 
 	// If c.literals[0] is True, then the clause is already true.
 	if s.LitValue(c.literals[0]) == True {
-		s.Watch(c, l, c.literals[0])
-		return true
+		return true, true
 	}
 
 	// Look for a new literal to watch, starting from the position of the
@@ -184,7 +232,7 @@ func (c *Clause) Propagate(s *Solver, l Literal) bool {
 			c.literals[1] = lit
 			c.literals[c.prevPos] = l.Opposite()
 			s.Watch(c, lit.Opposite(), c.literals[0])
-			return true
+			return false, true
 		}
 	}
 	for i, lit := range c.literals[2:c.prevPos] {
@@ -193,14 +241,13 @@ func (c *Clause) Propagate(s *Solver, l Literal) bool {
 			c.literals[1] = lit
 			c.literals[c.prevPos] = l.Opposite()
 			s.Watch(c, lit.Opposite(), c.literals[0])
-			return true
+			return false, true
 		}
 	}
 
 	// Attempt to assign the first literal to True to satisfy the clause as all
 	// other literals in literals[1:] are False.
-	s.Watch(c, l, c.literals[0])
-	return s.enqueue(c.literals[0], c)
+	return true, s.enqueue(c.literals[0], c)
 }
 
 func (c *Clause) explainConflict(outReason *[]Literal) {
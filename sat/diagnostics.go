@@ -0,0 +1,76 @@
+package sat
+
+import "sort"
+
+// numTopVariables is the number of top-score variables in Diagnostics.
+const numTopVariables = 10
+
+// Diagnostics is a detailed snapshot of the solver's state meant to
+// investigate runs that make no visible progress.
+type Diagnostics struct {
+	Progress
+	Iterations     uint64
+	DecisionLevel  int
+	Assigned       int // number of assigned variables
+	Variables      int
+	ProblemClauses int
+	LocalLearnts   int // number of learnt clauses subject to reductions
+	TopVariables   []VarScore
+}
+
+// VarScore is the score of a variable in the decision heuristic, along with
+// its current value.
+type VarScore struct {
+	Var   int
+	Score float64
+	Value LBool
+}
+
+// RequestDiagnostics asks the solver to call f with a snapshot of its state.
+// The call is made by the search itself, at its next iteration, so that the
+// search is not disturbed. It is safe to call RequestDiagnostics from any
+// goroutine. A request made while the solver is not solving is served by the
+// next call to Solve, and only the last pending request is served.
+func (s *Solver) RequestDiagnostics(f func(Diagnostics)) {
+	s.diagnose.Store(&f)
+}
+
+// serveDiagnostics serves the pending diagnostics request, if any.
+func (s *Solver) serveDiagnostics() {
+	if s.diagnose.Load() == nil {
+		return
+	}
+	if f := s.diagnose.Swap(nil); f != nil {
+		(*f)(s.diagnostics())
+	}
+}
+
+// diagnostics returns a snapshot of the solver's state.
+func (s *Solver) diagnostics() Diagnostics {
+	d := Diagnostics{
+		Progress:       s.progress(),
+		Iterations:     s.Statistics.Iterations,
+		DecisionLevel:  s.decisionLevel(),
+		Assigned:       s.NumAssigns(),
+		Variables:      s.NumVariables(),
+		ProblemClauses: s.NumConstraints(),
+		LocalLearnts:   len(s.locals),
+	}
+
+	vars := make([]int, s.NumVariables())
+	for v := range vars {
+		vars[v] = v
+	}
+	scores := s.order.heap.scores
+	sort.SliceStable(vars, func(i, j int) bool {
+		return scores[vars[i]] > scores[vars[j]]
+	})
+	for _, v := range vars[:min(numTopVariables, len(vars))] {
+		d.TopVariables = append(d.TopVariables, VarScore{
+			Var:   v,
+			Score: scores[v],
+			Value: s.VarValue(v),
+		})
+	}
+	return d
+}
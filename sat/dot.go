@@ -0,0 +1,68 @@
+package sat
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteImplicationGraph writes the implication graph of the current
+// assignment in Graphviz DOT format. Each node is a true literal labeled with
+// its decision level, decisions and assumptions being drawn as boxes, and each
+// edge goes from a literal to the literals it implied.
+func (s *Solver) WriteImplicationGraph(w io.Writer) error {
+	lits := make([]Literal, len(s.trail))
+	copy(lits, s.trail)
+	return writeDOT(w, "implications", s.implicationChain(lits, 0), nil)
+}
+
+// writeConflictGraph writes the subgraph explored by the analysis of the
+// conflicting clause: the implications of the current decision level leading
+// to the conflict and the literals of lower levels they depend on.
+func (s *Solver) writeConflictGraph(conflict *Clause) {
+	s.conflictGraphsLeft--
+	falsified := make([]Literal, len(conflict.literals))
+	for i, l := range conflict.literals {
+		falsified[i] = l.Opposite()
+	}
+	chain := s.implicationChain(falsified, s.decisionLevel())
+	name := fmt.Sprintf("conflict_%d", s.Statistics.Conflicts)
+	writeDOT(s.conflictGraphs, name, chain, falsified) // errors are ignored, see Options.ConflictGraphs
+}
+
+// writeDOT writes the implications as a DOT digraph with the given name. If
+// conflict is not nil, the graph also has a conflict node implied by these
+// literals.
+func writeDOT(w io.Writer, name string, chain []Implication, conflict []Literal) error {
+	nodes := make(map[Literal]bool, len(chain))
+	for _, imp := range chain {
+		nodes[imp.Literal] = true
+	}
+
+	sb := strings.Builder{}
+	fmt.Fprintf(&sb, "digraph %s {\n", name)
+	for _, imp := range chain {
+		shape := "ellipse"
+		if imp.Clause == nil {
+			shape = "box"
+		}
+		fmt.Fprintf(&sb, "  \"%d\" [label=\"%d @%d\", shape=%s];\n", imp.Literal.ToDIMACS(), imp.Literal.ToDIMACS(), imp.Level, shape)
+	}
+	for _, imp := range chain {
+		for _, r := range imp.Reason {
+			if nodes[r] {
+				fmt.Fprintf(&sb, "  \"%d\" -> \"%d\";\n", r.ToDIMACS(), imp.Literal.ToDIMACS())
+			}
+		}
+	}
+	if conflict != nil {
+		fmt.Fprintf(&sb, "  conflict [shape=octagon, color=red];\n")
+		for _, l := range conflict {
+			fmt.Fprintf(&sb, "  \"%d\" -> conflict;\n", l.ToDIMACS())
+		}
+	}
+	fmt.Fprintf(&sb, "}\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
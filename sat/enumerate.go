@@ -0,0 +1,184 @@
+package sat
+
+import (
+	"slices"
+	"time"
+)
+
+// EnumerateOptions configures EnumerateModels. The zero value enumerates all
+// the models of the problem.
+type EnumerateOptions struct {
+	// Assumptions under which the models are enumerated (see
+	// SolveWithAssumptions).
+	Assumptions []Literal
+
+	// MaxModels stops the enumeration once that many models have been found
+	// (0 for no maximum).
+	MaxModels int
+
+	// Timeout stops the enumeration once it has run for that duration (0 for
+	// no timeout). Contrary to the solver's Timeout option, which applies to
+	// each solve call, Timeout applies to the whole enumeration.
+	Timeout time.Duration
+
+	// OnModel is called with each model found. Returning false stops the
+	// enumeration. The model remains valid after the call.
+	OnModel func(model []bool) bool
+}
+
+// Enumeration is the outcome of EnumerateModels.
+type Enumeration struct {
+	// Number of models found.
+	Models int
+
+	// Exhaustive is true if all the models were found.
+	Exhaustive bool
+
+	// Reason why the enumeration was stopped if it is not exhaustive.
+	StopReason StopReason
+}
+
+// EnumerateModels enumerates the models of the problem by solving it
+// repeatedly, forbidding each model found with a blocking clause, until no
+// model remains or one of the stop conditions is met. The blocking clauses
+// remain in the solver after the call. After the call, the solver's Statistics
+// cover all the solve calls of the enumeration.
+func (s *Solver) EnumerateModels(opts EnumerateOptions) Enumeration {
+	defer s.beginOperation()()
+	timeout := s.timeout
+	defer func() { s.timeout = timeout }()
+	tStart := time.Now()
+
+	total := Statistics{}
+	defer func() { s.Statistics = total }()
+
+	e := Enumeration{}
+	for {
+		if opts.Timeout > 0 {
+			remaining := opts.Timeout - time.Since(tStart)
+			if remaining <= 0 {
+				e.StopReason = StoppedByTimeout
+				return e
+			}
+			if timeout < 0 || remaining < timeout {
+				s.timeout = remaining
+			}
+		}
+
+		status := s.SolveWithAssumptions(opts.Assumptions).Status
+		total.add(&s.Statistics)
+		switch status {
+		case False:
+			e.Exhaustive = true
+			return e
+		case Unknown:
+			e.StopReason = s.stopReason
+			return e
+		}
+
+		model := s.model
+		e.Models++
+		if opts.OnModel != nil && !opts.OnModel(model) {
+			e.StopReason = StoppedByCallback
+			return e
+		}
+		if e.Models == opts.MaxModels {
+			e.StopReason = StoppedByModelLimit
+			return e
+		}
+
+		// The blocking clause is the negation of the model. Adding it cannot
+		// fail as the solver is back at the root level.
+		blocking := make([]Literal, len(model))
+		for i, v := range model {
+			blocking[i] = PositiveLiteral(i)
+			if v {
+				blocking[i] = NegativeLiteral(i)
+			}
+		}
+		s.AddClause(blocking)
+	}
+}
+
+// DiverseModels returns up to k models of the problem that pairwise differ in
+// the value of at least minDistance variables. Models are found one at a time:
+// after each model, a cardinality constraint requiring the next models to be
+// at Hamming distance at least minDistance from it is added to the problem.
+// These constraints are guarded by a fresh selector variable and removed at
+// the end of the call. The status is True if k models were found, False if
+// no other model is far enough from the models found, and Unknown if the
+// search was stopped (see StopReason).
+//
+// Only the variables existing before the call are considered, and the models
+// only contain these variables.
+func (s *Solver) DiverseModels(k, minDistance int) ([][]bool, LBool) {
+	defer s.beginOperation()()
+
+	n := s.NumVariables()
+	selector := PositiveLiteral(s.AddVariable())
+	s.Freeze(selector.VarID())
+	defer s.AddClause([]Literal{selector.Opposite()})
+
+	models := [][]bool{}
+	for len(models) < k {
+		status := s.SolveWithAssumptions([]Literal{selector}).Status
+		if status != True {
+			return models, status
+		}
+		model := slices.Clone(s.model[:n])
+		differ := make([]Literal, n) // literals falsified by the model
+		for v, val := range model {
+			differ[v] = PositiveLiteral(v)
+			if val {
+				differ[v] = NegativeLiteral(v)
+			}
+		}
+		models = append(models, model)
+		s.addAtLeast(differ, minDistance, selector)
+	}
+	return models, True
+}
+
+// addAtLeast adds clauses, satisfied if guard is false, such that at least k
+// of the literals are true. The constraint is encoded with a sequential
+// counter whose variable s(i, j) implies that at least j of the first i
+// literals are true:
+//
+//	s(i, j) => s(i-1, j) ∨ l_i
+//	s(i, j) => s(i-1, j) ∨ s(i-1, j-1)
+//
+// where s(i, 0) is true and s(i, j) is false for j > i.
+func (s *Solver) addAtLeast(lits []Literal, k int, guard Literal) {
+	if k <= 0 {
+		return
+	}
+	if k > len(lits) {
+		s.AddClause([]Literal{guard.Opposite()})
+		return
+	}
+
+	prev := []Literal{} // prev[j-1] is s(i-1, j)
+	clause := []Literal{}
+	for i, l := range lits {
+		cur := make([]Literal, min(i+1, k))
+		for j := range cur {
+			cur[j] = PositiveLiteral(s.AddVariable())
+
+			clause = append(clause[:0], guard.Opposite(), cur[j].Opposite(), l)
+			if j < len(prev) {
+				clause = append(clause, prev[j])
+			}
+			s.AddClause(clause)
+
+			if j > 0 {
+				clause = append(clause[:0], guard.Opposite(), cur[j].Opposite(), prev[j-1])
+				if j < len(prev) {
+					clause = append(clause, prev[j])
+				}
+				s.AddClause(clause)
+			}
+		}
+		prev = cur
+	}
+	s.AddClause([]Literal{guard.Opposite(), prev[k-1]})
+}
@@ -0,0 +1,82 @@
+package sat
+
+import "slices"
+
+// Implication is an assignment of the current trail along with its cause.
+type Implication struct {
+	// Literal made true by the assignment.
+	Literal Literal
+
+	// Decision level of the assignment.
+	Level int
+
+	// Clause that implied Literal by unit propagation, or nil if Literal is a
+	// decision, an assumption, or a root-level fact without a reason.
+	Clause []Literal
+
+	// Literals of the trail that implied Literal through Clause (i.e. the
+	// negation of the clause's other literals).
+	Reason []Literal
+}
+
+// ExplainAssignment returns the literals of the current assignment that
+// implied literal l by unit propagation. It returns nil if l is not true, or
+// if it was not implied (e.g. a decision or an assumption). Assignments only
+// exist during the search and, at the root level, between solve calls; the
+// function is thus mostly useful from the solver's callbacks.
+func (s *Solver) ExplainAssignment(l Literal) []Literal {
+	if s.LitValue(l) != True {
+		return nil
+	}
+	return s.implication(l).Reason
+}
+
+// WhyImplied returns the chain of implications responsible for literal l to
+// be true: the transitive closure of ExplainAssignment, in the order in which
+// the literals were assigned and ending with l. The chain starts with the
+// decisions, assumptions, and root-level facts on which l depends. It returns
+// nil if l is not true.
+func (s *Solver) WhyImplied(l Literal) []Implication {
+	if s.LitValue(l) != True {
+		return nil
+	}
+
+	return s.implicationChain([]Literal{l}, 0)
+}
+
+// implicationChain returns the implications responsible for the true literals
+// lits, in trail order. Implications below minLevel are included but their
+// own causes are not.
+func (s *Solver) implicationChain(lits []Literal, minLevel int) []Implication {
+	needed := make([]bool, s.NumVariables())
+	for _, l := range lits {
+		needed[l.VarID()] = true
+	}
+	chain := []Implication{}
+	for i := len(s.trail) - 1; i >= 0; i-- {
+		q := s.trail[i]
+		if !needed[q.VarID()] {
+			continue
+		}
+		imp := s.implication(q)
+		if imp.Level >= minLevel {
+			for _, r := range imp.Reason {
+				needed[r.VarID()] = true
+			}
+		}
+		chain = append(chain, imp)
+	}
+	slices.Reverse(chain)
+	return chain
+}
+
+// implication returns the implication that made the true literal l true.
+func (s *Solver) implication(l Literal) Implication {
+	v := l.VarID()
+	imp := Implication{Literal: l, Level: s.assignLevels[v]}
+	if c := s.assignReasons[v]; c != nil {
+		imp.Clause = slices.Clone(c.literals)
+		c.explainAssign(&imp.Reason)
+	}
+	return imp
+}
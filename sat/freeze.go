@@ -0,0 +1,20 @@
+package sat
+
+// Freeze protects variable v from being removed from the problem by
+// simplifications such as variable elimination, so that it can safely be
+// assumed, constrained by new clauses, or queried after solving. Variables
+// must be frozen before the simplifications run; freezing a variable that has
+// already been removed has no effect.
+func (s *Solver) Freeze(v int) {
+	s.frozen[v] = true
+}
+
+// Melt reverts Freeze: variable v can again be removed by simplifications.
+func (s *Solver) Melt(v int) {
+	s.frozen[v] = false
+}
+
+// IsFrozen returns true if variable v is frozen (see Freeze).
+func (s *Solver) IsFrozen(v int) bool {
+	return s.frozen[v]
+}
@@ -0,0 +1,112 @@
+package sat
+
+import "slices"
+
+// varHeap is a binary max-heap of variables ordered by score, ties being
+// broken in favor of the smallest variable. The scores are stored in the heap
+// and can be modified in place, provided the heap is notified with increased
+// or rebuild.
+type varHeap struct {
+	scores  []float64 // score of each variable
+	heap    []int     // variables in the heap, ordered as a binary tree
+	indices []int     // position of each variable in heap (-1 if absent)
+}
+
+// addVar adds a new variable with the given score to the heap.
+func (h *varHeap) addVar(score float64) {
+	v := len(h.scores)
+	h.scores = append(h.scores, score)
+	h.indices = append(h.indices, -1)
+	h.push(v)
+}
+
+// reserve pre-allocates the heap for n additional variables.
+func (h *varHeap) reserve(n int) {
+	h.scores = slices.Grow(h.scores, n)
+	h.heap = slices.Grow(h.heap, n)
+	h.indices = slices.Grow(h.indices, n)
+}
+
+func (h *varHeap) contains(v int) bool {
+	return h.indices[v] >= 0
+}
+
+// push adds variable v to the heap if it is not already in it.
+func (h *varHeap) push(v int) {
+	if h.contains(v) {
+		return
+	}
+	h.indices[v] = len(h.heap)
+	h.heap = append(h.heap, v)
+	h.up(h.indices[v])
+}
+
+// pop removes and returns the variable with the highest score, or false if
+// the heap is empty.
+func (h *varHeap) pop() (int, bool) {
+	if len(h.heap) == 0 {
+		return 0, false
+	}
+	v := h.heap[0]
+	last := len(h.heap) - 1
+	h.swap(0, last)
+	h.heap = h.heap[:last]
+	h.indices[v] = -1
+	h.down(0)
+	return v, true
+}
+
+// increased restores the heap after the score of variable v was increased.
+func (h *varHeap) increased(v int) {
+	if h.contains(v) {
+		h.up(h.indices[v])
+	}
+}
+
+// rebuild restores the heap after arbitrary modifications of the scores.
+func (h *varHeap) rebuild() {
+	for i := len(h.heap)/2 - 1; i >= 0; i-- {
+		h.down(i)
+	}
+}
+
+// before returns true if the variable at position i of the heap must be
+// popped before the one at position j.
+func (h *varHeap) before(i, j int) bool {
+	u, v := h.heap[i], h.heap[j]
+	return h.scores[u] > h.scores[v] || (h.scores[u] == h.scores[v] && u < v)
+}
+
+func (h *varHeap) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !h.before(i, parent) {
+			return
+		}
+		h.swap(i, parent)
+		i = parent
+	}
+}
+
+func (h *varHeap) down(i int) {
+	for {
+		child := 2*i + 1
+		if child >= len(h.heap) {
+			return
+		}
+		if child+1 < len(h.heap) && h.before(child+1, child) {
+			child++
+		}
+		if h.before(i, child) {
+			return
+		}
+		h.swap(i, child)
+		i = child
+	}
+}
+
+func (h *varHeap) swap(i, j int) {
+	h.heap[i], h.heap[j] = h.heap[j], h.heap[i]
+	h.indices[h.heap[i]] = i
+	h.indices[h.heap[j]] = j
+}
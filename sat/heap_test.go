@@ -0,0 +1,48 @@
+package sat
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestVarHeap(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	h := varHeap{}
+	for i := 0; i < 100; i++ {
+		h.addVar(float64(rng.Intn(10))) // many ties
+	}
+	for i := 0; i < 50; i++ {
+		v := rng.Intn(100)
+		h.scores[v] += float64(rng.Intn(5))
+		h.increased(v)
+	}
+
+	want := make([]int, 100)
+	for i := range want {
+		want[i] = i
+	}
+	slices.SortStableFunc(want, func(u, v int) int {
+		switch {
+		case h.scores[u] > h.scores[v]:
+			return -1
+		case h.scores[u] < h.scores[v]:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	for i, w := range want {
+		v, ok := h.pop()
+		if !ok || v != w {
+			t.Fatalf("pop() #%d: want %d, got %d (ok = %t)", i, w, v, ok)
+		}
+		if h.contains(v) {
+			t.Errorf("contains(%d): want false after pop", v)
+		}
+	}
+	if _, ok := h.pop(); ok {
+		t.Errorf("pop(): want empty heap")
+	}
+}
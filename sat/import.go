@@ -0,0 +1,70 @@
+package sat
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// clauseQueue is a queue of clauses that can be pushed from any goroutine.
+type clauseQueue struct {
+	mu      sync.Mutex
+	clauses [][]Literal
+	size    atomic.Int64 // number of queued clauses, read without locking
+}
+
+func (q *clauseQueue) push(clause []Literal) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.clauses = append(q.clauses, clause)
+	q.size.Add(1)
+}
+
+// pending returns true if the queue is not empty.
+func (q *clauseQueue) pending() bool {
+	return q.size.Load() > 0
+}
+
+// drain removes and returns all the queued clauses.
+func (q *clauseQueue) drain() [][]Literal {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	clauses := q.clauses
+	q.clauses = nil
+	q.size.Store(0)
+	return clauses
+}
+
+// ErrProofImport is the proof error of solvers that imported clauses.
+var ErrProofImport = errors.New("proof cannot justify imported clauses")
+
+// ImportClause queues a problem clause to be added by the search the next time
+// it is at the root level (i.e. after its next restart), or by the next solve
+// call if the solver is not solving. It is safe to call ImportClause from any
+// goroutine, e.g. to share clauses between solvers or to inject constraints
+// from domain-specific propagators. The clause is copied.
+//
+// Imported clauses are added with AddClause: clauses that refer to unknown
+// variables are ignored unless the AutoAddVariables option is set. As imported
+// clauses cannot be justified in DRAT proofs, the proof (if any) is no longer
+// written once a clause is imported and ProofError returns ErrProofImport.
+func (s *Solver) ImportClause(lits []Literal) {
+	s.imports.push(slices.Clone(lits))
+}
+
+// importClauses adds the queued clauses to the solver, which must be at the
+// root level. It returns false if the problem became unsatisfiable.
+func (s *Solver) importClauses() bool {
+	clauses := s.imports.drain()
+	if len(clauses) > 0 && s.proof != nil && s.proof.err == nil {
+		s.proof.flush()
+		if s.proof.err == nil {
+			s.proof.err = ErrProofImport
+		}
+	}
+	for _, c := range clauses {
+		s.AddClause(c) // cannot fail at the root level but on unknown variables
+	}
+	return !s.unsat
+}
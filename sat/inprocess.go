@@ -0,0 +1,16 @@
+package sat
+
+// inprocess runs the simplifications enabled in the options, which happens at
+// the start of each solve call. Their outcome is reported with a verbosity of
+// at least 2.
+func (s *Solver) inprocess() {
+	if s.probing && s.Probe() && s.verbosity >= 2 {
+		s.logger.Printf("c probing: %d probes, %d failed literals, %d equivalences\n",
+			s.Statistics.Probes, s.Statistics.FailedLiterals, s.Statistics.Equivalences)
+	}
+	if s.ternaryResolvents > 0 && s.TernaryResolution() && s.verbosity >= 2 {
+		s.logger.Printf("c ternary resolution: %d resolvents, %d subsumed clauses\n",
+			s.Statistics.TernaryResolvents, s.Statistics.SubsumedClauses)
+	}
+	s.dropOccurrences() // not maintained during the search
+}
@@ -0,0 +1,280 @@
+package sat
+
+import (
+	"fmt"
+	"slices"
+)
+
+// LearningScheme determines which clause is learnt from the analysis of a
+// conflict.
+type LearningScheme uint8
+
+const (
+	// LearnFirstUIP learns the clause that contains the first unique
+	// implication point (UIP) of the conflict level, that is the UIP closest
+	// to the conflict, and the literals of lower levels that imply it.
+	LearnFirstUIP LearningScheme = iota
+
+	// LearnLastUIP resolves the literals of the conflict level up to the
+	// decision of that level, which is its last UIP.
+	LearnLastUIP
+
+	// LearnAllUIP learns the first UIP clause, then replaces the literals of
+	// each lower decision level by the UIP of that level when this shrinks the
+	// clause without adding literals of new levels (so that the LBD of the
+	// clause cannot increase).
+	LearnAllUIP
+
+	// LearnDIP learns the first UIP clause and, if the conflict level has a
+	// dual implication point (DIP), that is a pair of literals other than the
+	// UIP through which every path from the UIP to the conflict goes, the
+	// clause made of the negation of the pair and of the literals of lower
+	// levels involved between the pair and the conflict. The DIP closest to the
+	// conflict is used, and its clause is only learnt if it has fewer literals
+	// of lower levels than the first UIP clause. The DIP clause is not
+	// asserting: both literals of the pair are unassigned after backtracking.
+	// This is experimental, and differs from the original DIP learning in that
+	// no extension variable is introduced for the pair.
+	LearnDIP
+)
+
+func (ls LearningScheme) String() string {
+	switch ls {
+	case LearnFirstUIP:
+		return "first-uip"
+	case LearnLastUIP:
+		return "last-uip"
+	case LearnAllUIP:
+		return "all-uip"
+	case LearnDIP:
+		return "dip"
+	default:
+		return fmt.Sprintf("LearningScheme(%d)", ls)
+	}
+}
+
+// allUIP shrinks the first UIP clause learnt by analyze by replacing the
+// literals of each lower decision level by the UIP of that level (see
+// LearnAllUIP). The clause's first literal is the asserting literal and its
+// other literals must be marked in seenVar.
+func (s *Solver) allUIP(learnt []Literal) []Literal {
+	if len(learnt) <= 2 {
+		return learnt
+	}
+
+	s.seenLevel.Clear()
+	for _, l := range learnt[1:] {
+		s.seenLevel.Add(s.assignLevels[l.VarID()])
+	}
+	byLevel := func(a, b Literal) int {
+		return s.assignLevels[b.VarID()] - s.assignLevels[a.VarID()]
+	}
+
+	// The literals of learnt[:w] are final while those of learnt[r:] remain to
+	// be processed, by decreasing level. Each level is replaced by at most as
+	// many literals as it had, hence w <= r.
+	slices.SortFunc(learnt[1:], byLevel)
+	w := 1
+	for r := 1; r < len(learnt); {
+		level := s.assignLevels[learnt[r].VarID()]
+		k := 1
+		for r+k < len(learnt) && s.assignLevels[learnt[r+k].VarID()] == level {
+			k++
+		}
+		if uip, ok := s.levelUIP(level, k); ok {
+			learnt[w] = uip
+			w++
+			r += k
+			learnt = append(learnt, s.tmpUIP...)
+			slices.SortFunc(learnt[r:], byLevel)
+			continue
+		}
+		w += copy(learnt[w:], learnt[r:r+k])
+		r += k
+	}
+	return learnt[:w]
+}
+
+// levelUIP looks for the UIP of the k literals of the learnt clause assigned at
+// the given level. If replacing them by the UIP removes at least one literal
+// from the clause, it returns the UIP as a clause literal and stores in tmpUIP
+// the literals of lower levels that must be added to the clause.
+func (s *Solver) levelUIP(level int, k int) (Literal, bool) {
+	s.tmpUIP = s.tmpUIP[:0]
+	if k == 1 || level == 0 { // root-level literals have no UIP
+		return 0, false
+	}
+
+	nAntecedents := 0
+	if s.tracer != nil {
+		nAntecedents = len(s.tracer.record.Antecedents)
+	}
+
+	// Literals are marked in seenVar as they are resolved in so that those of
+	// lower levels are not added twice. The marks (and the resolutions added
+	// to the trace) must be undone if the level cannot be replaced.
+	s.tmpMarked = s.tmpMarked[:0]
+	fail := func() (Literal, bool) {
+		for _, v := range s.tmpMarked {
+			s.seenVar.Remove(v)
+		}
+		s.tmpUIP = s.tmpUIP[:0]
+		if s.tracer != nil {
+			s.tracer.record.Antecedents = s.tracer.record.Antecedents[:nAntecedents]
+		}
+		return 0, false
+	}
+
+	pending := k
+	for i := s.trailLevels[level] - 1; ; i-- {
+		p := s.trail[i]
+		v := p.VarID()
+		if !s.seenVar.Contains(v) {
+			continue
+		}
+		if pending == 1 {
+			return p.Opposite(), true
+		}
+
+		c := s.assignReasons[v] // not a decision as pending > 1
+		if s.tracer != nil {
+			s.tracer.resolve(c)
+		}
+		for _, q := range c.literals[1:] {
+			u := q.VarID()
+			l := s.assignLevels[u]
+			if l == 0 || s.seenVar.Contains(u) {
+				continue
+			}
+			if l != level {
+				// Literals of levels without literals in the clause would
+				// increase the clause's LBD.
+				if !s.seenLevel.Contains(l) || len(s.tmpUIP)+2 >= k {
+					return fail()
+				}
+				s.tmpUIP = append(s.tmpUIP, q)
+			} else {
+				pending++
+			}
+			s.seenVar.Add(u)
+			s.tmpMarked = append(s.tmpMarked, u)
+		}
+		pending--
+	}
+}
+
+// ExtraLearnts configures the learning of a second clause from the conflicts.
+// The resolvent of the conflict analysis whose literals of the conflict level
+// are first reduced to two is learnt in addition to the asserting clause if it
+// has at most MaxSize literals and an LBD of at most MaxLBD. Such clauses are
+// not asserting but they often propagate after the next decisions. A zero
+// MaxSize disables the extra learnt clauses.
+type ExtraLearnts struct {
+	MaxSize int
+	MaxLBD  int
+}
+
+// validate returns an error if the extra learnts configuration is invalid.
+func (el ExtraLearnts) validate() error {
+	if el.MaxSize < 0 {
+		return fmt.Errorf("extra learnts max size must be positive, got %d", el.MaxSize)
+	}
+	if el.MaxLBD < 0 {
+		return fmt.Errorf("extra learnts max LBD must be positive, got %d", el.MaxLBD)
+	}
+	return nil
+}
+
+// captureExtraLearnt stores in tmpExtra the current resolvent of analyze, whose
+// literals of the conflict level are the two last literals of the trail before
+// trailTop that are marked in seenVar, if it satisfies the limits of
+// ExtraLearnts (or is a DIP clause, see LearnDIP). The literals of the conflict
+// level are put first.
+func (s *Solver) captureExtraLearnt(trailTop int) bool {
+	lower := s.tmpLearnts[1:]
+	lbd := s.computeLBD(lower) + 1
+	if s.learning != LearnDIP && (len(lower)+2 > s.extraLearnts.MaxSize || lbd > s.extraLearnts.MaxLBD) {
+		return false
+	}
+
+	s.tmpExtra = s.tmpExtra[:0]
+	for i := trailTop - 1; len(s.tmpExtra) < 2; i-- {
+		if l := s.trail[i]; s.seenVar.Contains(l.VarID()) {
+			s.tmpExtra = append(s.tmpExtra, l.Opposite())
+		}
+	}
+	s.tmpExtra = append(s.tmpExtra, lower...)
+	s.extraLBD = lbd
+	return true
+}
+
+// checkDIP drops the clause captured by captureExtraLearnt if it is not the
+// clause of a DIP better than the first UIP clause learnt (see LearnDIP).
+func (s *Solver) checkDIP(learnt []Literal) {
+	if len(s.tmpExtra) == 0 {
+		return
+	}
+	uip := learnt[0].VarID()
+	if s.tmpExtra[0].VarID() == uip || s.tmpExtra[1].VarID() == uip || len(s.tmpExtra)-2 >= len(learnt)-1 {
+		s.tmpExtra = s.tmpExtra[:0]
+	}
+}
+
+// recordExtra adds the clause captured by captureExtraLearnt, if any, to the
+// learnt clauses. It must be called after backtracking.
+func (s *Solver) recordExtra() {
+	if len(s.tmpExtra) == 0 {
+		return
+	}
+	// The literals of lower levels are not false anymore if the asserting
+	// clause was strengthened enough to backtrack further.
+	for _, l := range s.tmpExtra[2:] {
+		if s.LitValue(l) != False {
+			return
+		}
+	}
+	s.addLearnt(s.tmpExtra, s.extraLBD)
+	s.Statistics.ExtraLearnts++
+}
+
+// strengthenBinary removes from the learnt clause the literals l for which the
+// clause DB contains the binary clause (learnt[0] ∨ ¬l), as resolving the learnt
+// clause with it yields the learnt clause without l. It must be called before
+// backtracking and returns the strengthened clause.
+func (s *Solver) strengthenBinary(learnt []Literal) []Literal {
+	s.seenVar.Clear()
+	for _, l := range learnt[1:] {
+		s.seenVar.Add(l.VarID())
+	}
+
+	removed := 0
+	for _, w := range s.watchers[learnt[0].Opposite()] {
+		lits := w.clause.literals
+		if len(lits) != 2 {
+			continue
+		}
+		other := lits[0]
+		if other == learnt[0] {
+			other = lits[1]
+		}
+		// The literals of the learnt clause are false, hence the binary
+		// clause contains the negation of one of them if other is true.
+		if v := other.VarID(); s.seenVar.Contains(v) && s.LitValue(other) == True {
+			s.seenVar.Remove(v)
+			removed++
+		}
+	}
+	if removed == 0 {
+		return learnt
+	}
+
+	j := 1
+	for _, l := range learnt[1:] {
+		if s.seenVar.Contains(l.VarID()) {
+			learnt[j] = l
+			j++
+		}
+	}
+	s.Statistics.StrengthenedLiterals += uint64(removed)
+	return learnt[:j]
+}
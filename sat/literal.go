@@ -16,6 +16,36 @@ func NegativeLiteral(v int) Literal {
 	return Literal(v*2 + 1)
 }
 
+// LiteralFromDIMACS returns the literal corresponding to DIMACS literal l, that
+// is variable |l|-1 if l is positive or its negation if l is negative. The
+// DIMACS literal must not be 0.
+func LiteralFromDIMACS(l int) Literal {
+	if l < 0 {
+		return NegativeLiteral(-l - 1)
+	}
+	return PositiveLiteral(l - 1)
+}
+
+// LiteralsFromDIMACS returns the literals corresponding to DIMACS literals
+// (see LiteralFromDIMACS).
+func LiteralsFromDIMACS(dimacs []int) []Literal {
+	lits := make([]Literal, len(dimacs))
+	for i, l := range dimacs {
+		lits[i] = LiteralFromDIMACS(l)
+	}
+	return lits
+}
+
+// LiteralsToDIMACS returns the DIMACS literals corresponding to the literals
+// (see Literal.ToDIMACS).
+func LiteralsToDIMACS(lits []Literal) []int {
+	dimacs := make([]int, len(lits))
+	for i, l := range lits {
+		dimacs[i] = l.ToDIMACS()
+	}
+	return dimacs
+}
+
 // VarID returns the ID of the literal's variable.
 func (l Literal) VarID() int {
 	return int(l) / 2
@@ -27,6 +57,15 @@ func (l Literal) IsPositive() bool {
 	return l&1 == 0
 }
 
+// ToDIMACS returns the DIMACS literal corresponding to the literal, that is
+// the 1-based ID of its variable, negated if the literal is negative.
+func (l Literal) ToDIMACS() int {
+	if l.IsPositive() {
+		return l.VarID() + 1
+	}
+	return -l.VarID() - 1
+}
+
 // Opposite returns the opposite literal.
 func (l Literal) Opposite() Literal {
 	return l ^ 1
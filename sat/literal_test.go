@@ -0,0 +1,20 @@
+package sat
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLiteralDIMACS(t *testing.T) {
+	dimacs := []int{1, -1, 2, -7, 42}
+	want := []Literal{PositiveLiteral(0), NegativeLiteral(0), PositiveLiteral(1), NegativeLiteral(6), PositiveLiteral(41)}
+
+	got := LiteralsFromDIMACS(dimacs)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("LiteralsFromDIMACS(): mismatch (+want, -got):\n%s", diff)
+	}
+	if diff := cmp.Diff(dimacs, LiteralsToDIMACS(got)); diff != "" {
+		t.Errorf("LiteralsToDIMACS(): mismatch (+want, -got):\n%s", diff)
+	}
+}
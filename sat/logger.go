@@ -0,0 +1,13 @@
+package sat
+
+// Logger is the interface used by the solver to report its progress. It is
+// satisfied by *log.Logger.
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
+// nopLogger is a Logger that discards everything it is given. This is the
+// default logger so that the solver stays silent when used as a library.
+type nopLogger struct{}
+
+func (nopLogger) Printf(string, ...any) {}
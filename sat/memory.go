@@ -0,0 +1,59 @@
+package sat
+
+import (
+	"runtime"
+	"unsafe"
+)
+
+// Approximated number of bytes used by a clause of n literals, including its
+// two watchers.
+const (
+	clauseBytes  = int64(unsafe.Sizeof(Clause{}) + 2*unsafe.Sizeof(watcher{}))
+	literalBytes = int64(unsafe.Sizeof(Literal(0)))
+)
+
+// Number of search iterations between two samples of the Go runtime memory
+// statistics. Sampling is relatively expensive as runtime.ReadMemStats stops
+// the world.
+const memorySampleInterval = 10000
+
+// memoryTracker estimates the memory used by the solver. It periodically
+// samples the heap size from the Go runtime and, in-between samples, accounts
+// for the memory allocated (or released) by the clause DB.
+type memoryTracker struct {
+	// Estimated number of bytes used by the clauses currently in the DB.
+	clauseDB int64
+
+	// Heap size and clause DB size at the time of the last sample.
+	sampledHeap     int64
+	sampledClauseDB int64
+
+	// Iteration at which the next sample must be taken.
+	nextSample uint64
+	sampled    bool
+}
+
+// addClause accounts for a new clause of n literals.
+func (mt *memoryTracker) addClause(n int) {
+	mt.clauseDB += clauseBytes + int64(n)*literalBytes
+}
+
+// removeClause accounts for the deletion of a clause of n literals.
+func (mt *memoryTracker) removeClause(n int) {
+	mt.clauseDB -= clauseBytes + int64(n)*literalBytes
+}
+
+// usage returns the estimated number of bytes used by the solver. A new sample
+// of the heap size is taken if enough iterations have happened since the last
+// sample.
+func (mt *memoryTracker) usage(iteration uint64) int64 {
+	if !mt.sampled || iteration >= mt.nextSample {
+		var ms runtime.MemStats
+		runtime.ReadMemStats(&ms)
+		mt.sampled = true
+		mt.sampledHeap = int64(ms.HeapAlloc)
+		mt.sampledClauseDB = mt.clauseDB
+		mt.nextSample = iteration + memorySampleInterval
+	}
+	return max(0, mt.sampledHeap+mt.clauseDB-mt.sampledClauseDB)
+}
@@ -0,0 +1,19 @@
+package sat
+
+import "testing"
+
+func TestMemoryTracker(t *testing.T) {
+	mt := memoryTracker{}
+	sampled := mt.usage(0)
+
+	mt.addClause(10)
+	want := sampled + clauseBytes + 10*literalBytes
+	if got := mt.usage(1); got != want {
+		t.Errorf("usage() after addClause(10): want %d, got %d", want, got)
+	}
+
+	mt.removeClause(10)
+	if got := mt.usage(2); got != sampled {
+		t.Errorf("usage() after removeClause(10): want %d, got %d", sampled, got)
+	}
+}
@@ -0,0 +1,30 @@
+package sat
+
+import "sort"
+
+// NameVariable associates a name with variable v so that models can be
+// reported in terms of the user's variables. Variables can be named before
+// they are added to the solver, and naming a variable twice replaces its
+// previous name.
+func (s *Solver) NameVariable(v int, name string) {
+	if s.names == nil {
+		s.names = map[int]string{}
+	}
+	s.names[v] = name
+}
+
+// VariableName returns the name of variable v, or the empty string if v has
+// no name.
+func (s *Solver) VariableName(v int) string {
+	return s.names[v]
+}
+
+// NamedVariables returns the variables that have a name, in increasing order.
+func (s *Solver) NamedVariables() []int {
+	vars := make([]int, 0, len(s.names))
+	for v := range s.names {
+		vars = append(vars, v)
+	}
+	sort.Ints(vars)
+	return vars
+}
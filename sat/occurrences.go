@@ -0,0 +1,85 @@
+package sat
+
+// occurrenceLists maps each literal to the problem clauses that contain it,
+// which lets simplifications such as subsumption, bounded variable elimination
+// or blocked clause elimination find the clauses of a literal without scanning
+// the whole clause DB.
+//
+// The lists are only needed while simplifying the problem and would slow down
+// the search if they had to be kept up to date. They are thus built on demand
+// (see Solver.occurrences) and dropped before searching. Deleted clauses are
+// removed lazily from the lists, the next time the lists are accessed.
+type occurrenceLists struct {
+	lists [][]*Clause // indexed by literal
+}
+
+// occurrences returns the occurrence lists of the problem clauses, building
+// them if necessary. The lists are maintained as problem clauses are added or
+// deleted until they are dropped with dropOccurrences.
+func (s *Solver) occurrences() *occurrenceLists {
+	if s.occurs != nil {
+		return s.occurs
+	}
+	s.occurs = &occurrenceLists{lists: make([][]*Clause, 2*s.NumVariables())}
+	for _, c := range s.constraints {
+		s.occurs.add(c)
+	}
+	return s.occurs
+}
+
+// dropOccurrences releases the occurrence lists, if any.
+func (s *Solver) dropOccurrences() {
+	s.occurs = nil
+}
+
+// add adds clause c to the lists of its literals.
+func (ol *occurrenceLists) add(c *Clause) {
+	for _, l := range c.literals {
+		for int(l) >= len(ol.lists) {
+			ol.lists = append(ol.lists, nil) // variable added after building
+		}
+		ol.lists[l] = append(ol.lists[l], c)
+	}
+}
+
+// removeLiteral removes clause c from the list of literal l. It must be called
+// when l is removed from the clause (e.g. when strengthening it). There is no
+// need to call it when the clause is deleted.
+func (ol *occurrenceLists) removeLiteral(c *Clause, l Literal) {
+	if int(l) >= len(ol.lists) {
+		return
+	}
+	list := ol.lists[l]
+	for i, o := range list {
+		if o == c {
+			list[i] = list[len(list)-1]
+			list[len(list)-1] = nil
+			ol.lists[l] = list[:len(list)-1]
+			return
+		}
+	}
+}
+
+// clauses returns the clauses that contain literal l. The slice is only valid
+// until the next modification of the lists.
+func (ol *occurrenceLists) clauses(l Literal) []*Clause {
+	if int(l) >= len(ol.lists) {
+		return nil
+	}
+	list := ol.lists[l]
+	j := 0
+	for _, c := range list {
+		if c.statusMask&statusDeleted == 0 {
+			list[j] = c
+			j++
+		}
+	}
+	clear(list[j:])
+	ol.lists[l] = list[:j]
+	return ol.lists[l]
+}
+
+// count returns the number of clauses that contain literal l.
+func (ol *occurrenceLists) count(l Literal) int {
+	return len(ol.clauses(l))
+}
@@ -0,0 +1,40 @@
+package sat
+
+import "testing"
+
+func TestOccurrences(t *testing.T) {
+	s := NewDefaultSolver()
+	for i := 0; i < 3; i++ {
+		s.AddVariable()
+	}
+	s.AddClause([]Literal{PositiveLiteral(0), PositiveLiteral(1)})
+	s.AddClause([]Literal{PositiveLiteral(0), NegativeLiteral(2)})
+
+	occs := s.occurrences()
+	if got := occs.count(PositiveLiteral(0)); got != 2 {
+		t.Errorf("count(x0): want 2, got %d", got)
+	}
+
+	// Clauses added while the lists exist are indexed.
+	v := s.AddVariable()
+	s.AddClause([]Literal{PositiveLiteral(v), PositiveLiteral(1)})
+	if got := occs.count(PositiveLiteral(1)); got != 2 {
+		t.Errorf("count(x1): want 2, got %d", got)
+	}
+	if got := occs.count(PositiveLiteral(v)); got != 1 {
+		t.Errorf("count(x%d): want 1, got %d", v, got)
+	}
+
+	// Lists are not maintained during the search.
+	s.Solve()
+	if s.occurs != nil {
+		t.Errorf("occurrence lists: want dropped after solving, got kept")
+	}
+
+	// Deleted clauses are removed from the lists.
+	c := s.occurrences().clauses(NegativeLiteral(2))[0]
+	c.Delete(s)
+	if got := s.occurrences().count(PositiveLiteral(0)); got != 1 {
+		t.Errorf("count(x0) after deletion: want 1, got %d", got)
+	}
+}
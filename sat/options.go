@@ -0,0 +1,444 @@
+package sat
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+type Options struct {
+	ClauseDecay   float64
+	VariableDecay float64
+	MaxConflicts  int64
+	Timeout       time.Duration
+	MaxMemoryMB   int64
+	PhaseSaving   bool
+
+	// DefaultPolarity is the value assigned by the decisions on variables
+	// without a saved phase or a preferred polarity (see SetPolarity). A false
+	// polarity makes models with few true variables more likely.
+	DefaultPolarity bool
+
+	// Seed of the pseudo-random generator used for every stochastic choice
+	// made by the solver. Two runs with the same seed and options on the same
+	// problem are identical.
+	Seed int64
+
+	// Probability in [0, 1] of picking a random variable for the next decision
+	// instead of the one with the highest score.
+	RandomDecisionFreq float64
+
+	// ReasonSideBumping makes the solver also bump the scores of the variables
+	// that appear in the reasons of the literals of each learnt clause (as in
+	// Glucose), which favors the variables close to the conflicts.
+	ReasonSideBumping bool
+
+	// Learning is the scheme used to learn a clause from each conflict.
+	Learning LearningScheme
+
+	// Probing makes the solver look for failed literals before searching, at
+	// the start of each solve call (see Solver.Probe).
+	Probing bool
+
+	// TernaryResolvents is the maximum number of resolvents of ternary
+	// clauses added at the start of each solve call (see
+	// Solver.TernaryResolution). A zero value disables ternary resolution.
+	TernaryResolvents int
+
+	// BinaryStrengthenLBD is the maximum LBD of the learnt clauses that are
+	// strengthened by resolution with the binary clauses of the clause DB
+	// before being recorded, which cheaply removes the literals l such that
+	// the binary clause (u ∨ ¬l) exists, u being the asserting literal. A zero
+	// value disables the strengthening.
+	BinaryStrengthenLBD int
+
+	// ExtraLearnts configures the learning of a second, non-asserting clause
+	// from the conflicts. It is disabled by default and ignored by LearnDIP,
+	// which learns its own second clause.
+	ExtraLearnts ExtraLearnts
+
+	// Restarts configures the conflict budget of the searches between two
+	// restarts.
+	Restarts Restarts
+
+	// Reductions configures which learnt clauses are deleted by the periodic
+	// reductions of the clause DB.
+	Reductions Reductions
+
+	// Averages configures the decays of the moving averages maintained on
+	// conflicts, which restart policies can build upon.
+	Averages Averages
+
+	// PoolLearnts makes the solver store the learnt clauses in large chunks of
+	// memory and recycle the clauses deleted when reducing the clause DB,
+	// which avoids most allocations during the search. Otherwise, each learnt
+	// clause is allocated on its own and can be garbage collected as soon as
+	// it is deleted.
+	PoolLearnts bool
+
+	// AutoAddVariables makes AddClause add the variables referred to by the
+	// clause that do not exist yet. Otherwise, AddClause returns an error on
+	// such clauses.
+	AutoAddVariables bool
+
+	// Logger receives the solver's progress reports (e.g. search statistics).
+	// Nothing is reported if Logger is nil.
+	Logger Logger
+
+	// Verbosity of the progress reports: 0 disables them, 1 reports the search
+	// statistics periodically and on each restart and reduction of the clause
+	// DB, and 2 also reports the outcome of the inprocessing simplifications.
+	Verbosity int
+
+	// StatsInterval sets how often the search statistics are reported with a
+	// verbosity of at least 1.
+	StatsInterval StatsInterval
+
+	// OnProgress is called with a snapshot of the search progress every
+	// ProgressInterval conflicts. Progress is not reported if OnProgress is
+	// nil or if ProgressInterval is 0.
+	OnProgress       func(Progress)
+	ProgressInterval uint64
+
+	// OnReduce is called after each reduction of the learnt clause DB (nothing
+	// is called if nil).
+	OnReduce func()
+
+	// OnLearnt is called with each new learnt clause whose LBD is at most
+	// LearntExportLBD, e.g. to share clauses with other solvers. The slice of
+	// literals is only valid during the call. Nothing is called if OnLearnt is
+	// nil.
+	OnLearnt        func(lits []Literal, lbd int)
+	LearntExportLBD int
+
+	// OnYield is called every YieldInterval search iterations. These yield
+	// points let the caller give control back to its environment during long
+	// searches, e.g. to the event loop of a browser when running in
+	// WebAssembly. Nothing is called if OnYield is nil or YieldInterval is 0.
+	OnYield       func()
+	YieldInterval uint64
+
+	// ConflictGraphs receives, for debugging purposes, the subgraph of the
+	// implication graph explored by the analysis of each of the first
+	// MaxConflictGraphs conflicts, in Graphviz DOT format (one digraph per
+	// conflict). Write errors are ignored: use a writer that records them,
+	// e.g. a bufio.Writer. Nothing is written if ConflictGraphs is nil.
+	ConflictGraphs    io.Writer
+	MaxConflictGraphs int
+
+	// ConflictTrace receives the trace of the analysis of each of the first
+	// MaxTracedConflicts conflicts as JSON lines, e.g. to validate the solver
+	// step by step against a reference solver. Each line is an object with
+	// the conflict's number (from 1 in each solve call, as the statistics are
+	// reset by each call) and decision level, the conflicting clause, the
+	// reason clauses resolved with it, the learnt clause, its LBD, and the
+	// backtrack level. Clauses are arrays of DIMACS literals. Write errors are
+	// ignored as for ConflictGraphs. Nothing is written if ConflictTrace is
+	// nil.
+	ConflictTrace      io.Writer
+	MaxTracedConflicts int
+
+	// Proof receives a DRAT proof of unsatisfiability in the given format. No
+	// proof is written if Proof is nil.
+	Proof       io.Writer
+	ProofFormat ProofFormat
+}
+
+var DefaultOptions = Options{
+	ClauseDecay:         0.999,
+	VariableDecay:       0.95,
+	MaxConflicts:        -1,
+	Timeout:             -1,
+	MaxMemoryMB:         -1,
+	PhaseSaving:         false,
+	DefaultPolarity:     true,
+	Seed:                0,
+	RandomDecisionFreq:  0,
+	ReasonSideBumping:   false,
+	Learning:            LearnFirstUIP,
+	ExtraLearnts:        ExtraLearnts{MaxSize: 0, MaxLBD: 0},
+	BinaryStrengthenLBD: 0,
+	Probing:             false,
+	TernaryResolvents:   0,
+	Restarts:            Restarts{Policy: RestartArithmetic, Initial: 100, Increment: 1000},
+	Reductions: Reductions{
+		Policy:        ReduceActivity,
+		Fraction:      0.5,
+		CoreLBD:       5,
+		DemoteAfter:   0,
+		Schedule:      ReduceEveryConflicts,
+		Interval:      20000,
+		LearntsFactor: 1.0 / 3,
+		LearntsGrowth: 1.1,
+	},
+	Averages: Averages{
+		ConflictLevel: 0.9999,
+		SlowLBD:       0.9999,
+		FastLBD:       0.97,
+		Trail:         0.9999,
+	},
+	PoolLearnts:        true,
+	AutoAddVariables:   false,
+	Logger:             nil,
+	Verbosity:          1,
+	StatsInterval:      StatsInterval{Conflicts: 10000},
+	OnProgress:         nil,
+	ProgressInterval:   0,
+	OnReduce:           nil,
+	OnLearnt:           nil,
+	LearntExportLBD:    0,
+	OnYield:            nil,
+	YieldInterval:      0,
+	ConflictGraphs:     nil,
+	MaxConflictGraphs:  0,
+	ConflictTrace:      nil,
+	MaxTracedConflicts: 0,
+	Proof:              nil,
+	ProofFormat:        ProofText,
+}
+
+// StatsInterval is the interval between two periodic reports of the search
+// statistics. Statistics are reported every Conflicts conflicts and after each
+// Period of time, whichever comes first; a zero field disables the matching
+// criterion and a zero StatsInterval disables the periodic reports (the
+// statistics are still reported at the beginning and the end of the search).
+// The elapsed time is only checked on conflicts.
+type StatsInterval struct {
+	Conflicts uint64
+	Period    time.Duration
+}
+
+// Validate returns an error if the options do not form a valid configuration.
+func (ops *Options) Validate() error {
+	if ops.ClauseDecay <= 0 || ops.ClauseDecay > 1 {
+		return fmt.Errorf("clause decay must be in (0, 1], got %v", ops.ClauseDecay)
+	}
+	if ops.VariableDecay <= 0 || ops.VariableDecay > 1 {
+		return fmt.Errorf("variable decay must be in (0, 1], got %v", ops.VariableDecay)
+	}
+	if ops.RandomDecisionFreq < 0 || ops.RandomDecisionFreq > 1 {
+		return fmt.Errorf("random decision frequency must be in [0, 1], got %v", ops.RandomDecisionFreq)
+	}
+	switch ops.Learning {
+	case LearnFirstUIP, LearnLastUIP, LearnAllUIP, LearnDIP:
+	default:
+		return fmt.Errorf("unsupported learning scheme %s", ops.Learning)
+	}
+	if ops.TernaryResolvents < 0 {
+		return fmt.Errorf("ternary resolvents must be positive, got %d", ops.TernaryResolvents)
+	}
+	if ops.BinaryStrengthenLBD < 0 {
+		return fmt.Errorf("binary strengthening LBD must be positive, got %d", ops.BinaryStrengthenLBD)
+	}
+	if err := ops.ExtraLearnts.validate(); err != nil {
+		return err
+	}
+	if err := ops.Restarts.validate(); err != nil {
+		return err
+	}
+	if err := ops.Reductions.validate(); err != nil {
+		return err
+	}
+	if err := ops.Averages.validate(); err != nil {
+		return err
+	}
+	if ops.StatsInterval.Period < 0 {
+		return fmt.Errorf("stats period must be positive, got %s", ops.StatsInterval.Period)
+	}
+	if ops.ProofFormat != ProofText && ops.ProofFormat != ProofBinary {
+		return fmt.Errorf("unsupported proof format %s", ops.ProofFormat)
+	}
+	return nil
+}
+
+// Option modifies the configuration of a solver created with NewSolver.
+type Option func(*Options)
+
+// WithOptions replaces the whole configuration by the given options.
+func WithOptions(o Options) Option {
+	return func(ops *Options) { *ops = o }
+}
+
+// WithClauseDecay sets the decay factor of the clauses' activity.
+func WithClauseDecay(decay float64) Option {
+	return func(ops *Options) { ops.ClauseDecay = decay }
+}
+
+// WithVariableDecay sets the decay factor of the variables' score.
+func WithVariableDecay(decay float64) Option {
+	return func(ops *Options) { ops.VariableDecay = decay }
+}
+
+// WithMaxConflicts sets the maximum number of conflicts after which the search
+// is stopped (-1 for no maximum).
+func WithMaxConflicts(n int64) Option {
+	return func(ops *Options) { ops.MaxConflicts = n }
+}
+
+// WithTimeout sets the duration after which the search is stopped (-1 for no
+// timeout).
+func WithTimeout(d time.Duration) Option {
+	return func(ops *Options) { ops.Timeout = d }
+}
+
+// WithMaxMemoryMB sets the memory usage, in megabytes, above which the search
+// is stopped (-1 for no limit).
+func WithMaxMemoryMB(mb int64) Option {
+	return func(ops *Options) { ops.MaxMemoryMB = mb }
+}
+
+// WithPhaseSaving enables or disables phase saving.
+func WithPhaseSaving(enabled bool) Option {
+	return func(ops *Options) { ops.PhaseSaving = enabled }
+}
+
+// WithDefaultPolarity sets the default value assigned by decisions.
+func WithDefaultPolarity(polarity bool) Option {
+	return func(ops *Options) { ops.DefaultPolarity = polarity }
+}
+
+// WithSeed sets the seed of the solver's pseudo-random generator.
+func WithSeed(seed int64) Option {
+	return func(ops *Options) { ops.Seed = seed }
+}
+
+// WithRandomDecisionFreq sets the probability of making random decisions.
+func WithRandomDecisionFreq(freq float64) Option {
+	return func(ops *Options) { ops.RandomDecisionFreq = freq }
+}
+
+// WithReasonSideBumping enables or disables the bumping of the variables of
+// the reasons of the learnt clauses' literals.
+func WithReasonSideBumping(enabled bool) Option {
+	return func(ops *Options) { ops.ReasonSideBumping = enabled }
+}
+
+// WithLearning sets the scheme used to learn a clause from each conflict.
+func WithLearning(ls LearningScheme) Option {
+	return func(ops *Options) { ops.Learning = ls }
+}
+
+// WithProbing enables or disables the probing of failed literals before each
+// search.
+func WithProbing(enabled bool) Option {
+	return func(ops *Options) { ops.Probing = enabled }
+}
+
+// WithTernaryResolvents sets the maximum number of ternary resolvents added at
+// the start of each solve call (0 disables ternary resolution).
+func WithTernaryResolvents(n int) Option {
+	return func(ops *Options) { ops.TernaryResolvents = n }
+}
+
+// WithBinaryStrengthenLBD sets the maximum LBD of the learnt clauses that are
+// strengthened with the binary clauses (0 disables the strengthening).
+func WithBinaryStrengthenLBD(lbd int) Option {
+	return func(ops *Options) { ops.BinaryStrengthenLBD = lbd }
+}
+
+// WithExtraLearnts sets the limits of the extra clauses learnt from the
+// conflicts.
+func WithExtraLearnts(el ExtraLearnts) Option {
+	return func(ops *Options) { ops.ExtraLearnts = el }
+}
+
+// WithRestarts sets the conflict budget of the searches between two restarts.
+func WithRestarts(r Restarts) Option {
+	return func(ops *Options) { ops.Restarts = r }
+}
+
+// WithReductions sets which learnt clauses are deleted by the reductions of the
+// clause DB.
+func WithReductions(r Reductions) Option {
+	return func(ops *Options) { ops.Reductions = r }
+}
+
+// WithAverages sets the decays of the moving averages maintained on conflicts.
+func WithAverages(a Averages) Option {
+	return func(ops *Options) { ops.Averages = a }
+}
+
+// WithPoolLearnts enables or disables the pooling of learnt clauses.
+func WithPoolLearnts(enabled bool) Option {
+	return func(ops *Options) { ops.PoolLearnts = enabled }
+}
+
+// WithAutoAddVariables enables or disables the automatic addition of the
+// variables referred to by new clauses.
+func WithAutoAddVariables(enabled bool) Option {
+	return func(ops *Options) { ops.AutoAddVariables = enabled }
+}
+
+// WithVerbosity sets the verbosity of the progress reports.
+func WithVerbosity(level int) Option {
+	return func(ops *Options) { ops.Verbosity = level }
+}
+
+// WithStatsInterval sets the interval between two periodic reports of the
+// search statistics.
+func WithStatsInterval(interval StatsInterval) Option {
+	return func(ops *Options) { ops.StatsInterval = interval }
+}
+
+// WithLogger sets the logger used to report the search progress.
+func WithLogger(l Logger) Option {
+	return func(ops *Options) { ops.Logger = l }
+}
+
+// WithProgress sets the callback called with the search progress every
+// interval conflicts.
+func WithProgress(interval uint64, f func(Progress)) Option {
+	return func(ops *Options) {
+		ops.ProgressInterval = interval
+		ops.OnProgress = f
+	}
+}
+
+// WithReduceHook sets the function called after each reduction of the learnt
+// clause DB.
+func WithReduceHook(f func()) Option {
+	return func(ops *Options) { ops.OnReduce = f }
+}
+
+// WithLearntExport sets the callback called with each new learnt clause whose
+// LBD is at most maxLBD.
+func WithLearntExport(maxLBD int, f func(lits []Literal, lbd int)) Option {
+	return func(ops *Options) {
+		ops.LearntExportLBD = maxLBD
+		ops.OnLearnt = f
+	}
+}
+
+// WithYield sets the function called every interval search iterations.
+func WithYield(interval uint64, f func()) Option {
+	return func(ops *Options) {
+		ops.YieldInterval = interval
+		ops.OnYield = f
+	}
+}
+
+// WithConflictGraphs sets the writer receiving the DOT graphs of the first max
+// conflicts.
+func WithConflictGraphs(w io.Writer, max int) Option {
+	return func(ops *Options) {
+		ops.ConflictGraphs = w
+		ops.MaxConflictGraphs = max
+	}
+}
+
+// WithConflictTrace sets the writer receiving the trace of the first max
+// conflicts.
+func WithConflictTrace(w io.Writer, max int) Option {
+	return func(ops *Options) {
+		ops.ConflictTrace = w
+		ops.MaxTracedConflicts = max
+	}
+}
+
+// WithProof sets the writer receiving a DRAT proof in the given format.
+func WithProof(w io.Writer, format ProofFormat) Option {
+	return func(ops *Options) {
+		ops.Proof = w
+		ops.ProofFormat = format
+	}
+}
@@ -0,0 +1,104 @@
+package sat
+
+import (
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewSolver_validOptions(t *testing.T) {
+	_, gotErr := NewSolver(
+		WithTimeout(time.Second),
+		WithPhaseSaving(true),
+		WithVariableDecay(1),
+		WithRandomDecisionFreq(0.01),
+	)
+
+	if gotErr != nil {
+		t.Errorf("NewSolver(): want no error, got %s", gotErr)
+	}
+}
+
+func TestNewSolver_invalidOptions(t *testing.T) {
+	testCases := []struct {
+		desc string
+		opt  Option
+	}{
+		{"zero clause decay", WithClauseDecay(0)},
+		{"clause decay above 1", WithClauseDecay(1.5)},
+		{"negative variable decay", WithVariableDecay(-0.5)},
+		{"random frequency above 1", WithRandomDecisionFreq(2)},
+		{"unknown proof format", WithProof(io.Discard, ProofFormat(42))},
+		{"unknown learning scheme", WithLearning(LearningScheme(42))},
+		{"negative ternary resolvents", WithTernaryResolvents(-1)},
+		{"negative binary strengthening LBD", WithBinaryStrengthenLBD(-1)},
+		{"negative extra learnts size", WithExtraLearnts(ExtraLearnts{MaxSize: -1})},
+		{"zero restart budget", WithRestarts(Restarts{Policy: RestartLuby})},
+		{"geometric restart increment below 1", WithRestarts(Restarts{Policy: RestartGeometric, Initial: 100, Increment: 0.5})},
+		{"unknown restart policy", WithRestarts(Restarts{Policy: RestartPolicy(42), Initial: 100})},
+		{"glucose restart margin below 1", WithRestarts(Restarts{Policy: RestartGlucose, Initial: 50, Increment: 0.8})},
+		{"reduction fraction above 1", WithReductions(Reductions{Policy: ReduceLBD, Fraction: 1.5})},
+		{"negative demotion delay", WithReductions(Reductions{Fraction: 0.5, DemoteAfter: -1})},
+		{"unknown reduction policy", WithReductions(Reductions{Policy: ReducePolicy(42), Fraction: 0.5})},
+		{"zero learnts factor", WithReductions(Reductions{Fraction: 0.5, Schedule: ReduceGeometricLimit, LearntsGrowth: 1.1})},
+		{"unknown reduction schedule", WithReductions(Reductions{Fraction: 0.5, Schedule: ReduceSchedule(42)})},
+		{"zero reduction interval", WithReductions(Reductions{Fraction: 0.5, Schedule: ReduceEveryConflicts})},
+		{"average decay of 1", WithAverages(Averages{ConflictLevel: 1, SlowLBD: 0.9, FastLBD: 0.9, Trail: 0.9})},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, gotErr := NewSolver(tc.opt)
+
+			if gotErr == nil {
+				t.Errorf("NewSolver(): want error, got none")
+			}
+		})
+	}
+}
+
+func TestPreset(t *testing.T) {
+	for _, name := range PresetNames() {
+		preset, err := Preset(name)
+		if err != nil {
+			t.Errorf("Preset(%q): want no error, got %s", name, err)
+			continue
+		}
+		if _, err := NewSolver(preset); err != nil {
+			t.Errorf("NewSolver(Preset(%q)): want no error, got %s", name, err)
+		}
+	}
+
+	if _, err := Preset("unknown"); err == nil {
+		t.Errorf("Preset(\"unknown\"): want error, got none")
+	}
+}
+
+func TestRestartSchedule(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		restarts Restarts
+		want     []uint64
+	}{
+		{"arithmetic", Restarts{RestartArithmetic, 100, 1000}, []uint64{100, 1100, 2100, 3100}},
+		{"geometric", Restarts{RestartGeometric, 100, 1.5}, []uint64{100, 150, 225, 337}},
+		{"luby", Restarts{RestartLuby, 10, 0}, []uint64{10, 10, 20, 10, 10, 20, 40, 10}},
+		{"glucose", Restarts{RestartGlucose, 50, 1.25}, []uint64{math.MaxUint64, math.MaxUint64}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			rs := newRestartSchedule(tc.restarts)
+			got := make([]uint64, len(tc.want))
+			for i := range got {
+				got[i] = rs.next()
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("next(): mismatch (+want, -got):\n%s", diff)
+			}
+		})
+	}
+}
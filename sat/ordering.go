@@ -1,46 +1,60 @@
 package sat
 
-import (
-	"log"
-
-	"github.com/rhartert/yagh"
-)
+import "slices"
 
 // VarOrder maintains the order of variable to be assigned by the solver.
 type VarOrder struct {
-	// Binary heap to access the next variable with the highest score. The heap
-	// breaks ties using the index of its elements which will correspond to the
-	// order in which variables are declared with AddVar.
-	order *yagh.IntMap[float64]
+	// Binary heap to access the next variable with the highest score, which
+	// also holds the variables' score in [0, 1e100). The heap breaks ties in
+	// favor of the variables declared first with AddVar.
+	heap varHeap
 
-	scores     []float64 // in [0, 1e100)
-	scoreInc   float64   // in (0, 1e100)
-	scoreDecay float64   // in (0, 1]
+	scoreInc   float64 // in (0, 1e100)
+	scoreDecay float64 // in (0, 1]
 
 	phases      []LBool
 	phaseSaving bool
+
+	// Phase of the decisions on variables without a saved phase or a
+	// preferred polarity.
+	defaultPhase LBool
+
+	// Polarity preferred by the user for each variable (Unknown if none),
+	// which overrides the saved phases (see Solver.SetPolarity).
+	preferred []LBool
 }
 
 // NewVarOrder returns a new initialized VarOrder.
 func NewVarOrder(decay float64, phaseSaving bool) *VarOrder {
 	return &VarOrder{
-		order:       yagh.New[float64](0),
-		scoreInc:    1,
-		scoreDecay:  decay,
-		phases:      make([]LBool, 0),
-		phaseSaving: phaseSaving,
+		scoreInc:     1,
+		scoreDecay:   decay,
+		phases:       make([]LBool, 0),
+		phaseSaving:  phaseSaving,
+		defaultPhase: True,
 	}
 }
 
 // AddVar adds a new variable with the given inital score and phase.
 func (vo *VarOrder) AddVar(initScore float64, initPhase bool) {
-	varID := len(vo.phases)
-
-	vo.scores = append(vo.scores, initScore)
 	vo.phases = append(vo.phases, Lift(initPhase))
+	vo.preferred = append(vo.preferred, Unknown)
+	vo.heap.addVar(initScore)
+}
+
+// AddVars adds n variables with the given initial score and phase.
+func (vo *VarOrder) AddVars(n int, initScore float64, initPhase bool) {
+	vo.reserve(n)
+	for i := 0; i < n; i++ {
+		vo.AddVar(initScore, initPhase)
+	}
+}
 
-	vo.order.GrowBy(1)
-	vo.order.Put(varID, -initScore)
+// reserve pre-allocates the order for n additional variables.
+func (vo *VarOrder) reserve(n int) {
+	vo.heap.reserve(n)
+	vo.phases = slices.Grow(vo.phases, n)
+	vo.preferred = slices.Grow(vo.preferred, n)
 }
 
 // Reinsert adds variable v back to the set of candidates to be selected. This
@@ -48,8 +62,7 @@ func (vo *VarOrder) AddVar(initScore float64, initPhase bool) {
 // a backtrack occurs) where val is the value the variable was assigned to.
 func (vo *VarOrder) Reinsert(v int, val LBool) {
 	vo.phases[v] = val
-	act := vo.scores[v]
-	vo.order.Put(v, -act)
+	vo.heap.push(v)
 }
 
 // DecayScores slightly decreases the scores of the variables. This is used
@@ -67,50 +80,72 @@ func (vo *VarOrder) DecayScores() {
 // a given threshold. The rescaling is done in way that conserves the relative
 // importance of each variable when compared to each other.
 func (vo *VarOrder) BumpScore(v int) {
-	newScore := vo.scores[v] + vo.scoreInc
-	vo.scores[v] = newScore
-	if vo.order.Contains(v) {
-		vo.order.Put(v, -newScore)
-	}
-	if vo.scores[v] > 1e100 {
+	vo.heap.scores[v] += vo.scoreInc
+	vo.heap.increased(v)
+	if vo.heap.scores[v] > 1e100 {
 		vo.rescaleScoresAndIncrement()
 	}
 }
 
-// NextDecision returns the next unnassigned literal to be assigned to true.
-func (vo *VarOrder) NextDecision(s *Solver) Literal {
+// NextDecision returns the next unnassigned literal to be assigned to true, or
+// false if all the variables are assigned.
+func (vo *VarOrder) NextDecision(s *Solver) (Literal, bool) {
+	// Occasionally pick a random variable to diversify the search. The variable
+	// is left in the heap and will be skipped once popped if still assigned.
+	if s.randomDecisionFreq > 0 && s.rng.Float64() < s.randomDecisionFreq {
+		if v := s.rng.Intn(s.NumVariables()); s.VarValue(v) == Unknown {
+			return vo.decide(v), true
+		}
+	}
+
 	for {
-		next, ok := vo.order.Pop()
+		next, ok := vo.heap.pop()
 		if !ok {
-			log.Fatalln("empty heap")
+			break
 		}
-		if s.VarValue(next.Elem) != Unknown {
+		if s.VarValue(next) != Unknown {
 			continue // already assigned
 		}
+		return vo.decide(next), true
+	}
 
-		phase := Unknown
-		if vo.phaseSaving {
-			phase = vo.phases[next.Elem]
-		}
+	// The heap contains all the unassigned variables (see TestVarOrder_heap),
+	// all of them are thus assigned once it is empty.
+	return 0, false
+}
 
-		switch phase {
-		case True:
-			return PositiveLiteral(next.Elem)
-		case False:
-			return NegativeLiteral(next.Elem)
-		default:
-			return PositiveLiteral(next.Elem)
-		}
+// decide returns the literal of variable v to be assigned to true.
+func (vo *VarOrder) decide(v int) Literal {
+	phase := vo.preferred[v]
+	if phase == Unknown && vo.phaseSaving {
+		phase = vo.phases[v]
+	}
+	if phase == Unknown {
+		phase = vo.defaultPhase
 	}
+
+	if phase == False {
+		return NegativeLiteral(v)
+	}
+	return PositiveLiteral(v)
+}
+
+// reset resets the scores and phases of the variables to the initial values
+// given by AddVar in the solver (i.e. a score of 0 and the default phase). The
+// preferred polarities are kept.
+func (vo *VarOrder) reset() {
+	vo.scoreInc = 1
+	for v := range vo.heap.scores {
+		vo.heap.scores[v] = 0
+		vo.phases[v] = vo.defaultPhase
+	}
+	vo.heap.rebuild()
 }
 
 func (vo *VarOrder) rescaleScoresAndIncrement() {
 	vo.scoreInc *= 1e-100 // important to keep proportions
-	for v, s := range vo.scores {
-		newScore := s * 1e-100
-		vo.scores[v] = newScore
-		if vo.order.Contains(v) {
-			vo.order.Put(v, -newScore)
-		}
+	for v := range vo.heap.scores {
+		vo.heap.scores[v] *= 1e-100
 	}
+	vo.heap.rebuild() // scores that underflow to 0 become ties
 }
@@ -0,0 +1,70 @@
+package sat
+
+// SetPolarity sets the value preferably assigned to variable v by decisions,
+// which overrides the default polarity and the saved phases. Unknown removes
+// the preference.
+func (s *Solver) SetPolarity(v int, polarity LBool) {
+	s.order.preferred[v] = polarity
+}
+
+// Polarity returns the value preferably assigned to variable v by decisions
+// (see SetPolarity), or Unknown if there is no preference.
+func (s *Solver) Polarity(v int) LBool {
+	return s.order.preferred[v]
+}
+
+// MinimizeModel reduces the model found by the last solve call, which must
+// have returned True, to a model whose set of true variables is minimal with
+// respect to inclusion: no variable can be made false without making another
+// one true. The given assumptions (typically those of the last solve call)
+// hold in the reduced model.
+//
+// The model is reduced by solving the problem once for each of its true
+// variables v, with v and the model's false variables assumed to be false.
+// It returns True once the model is minimal and Unknown if one of these calls
+// is stopped (see StopReason) or if there is no model to reduce. In both
+// cases, Model returns the smallest model found, which also replaces the
+// reduced model in Models (the intermediate models are not recorded). After
+// the call, the solver's Statistics cover the last solve call as well as all
+// the solve calls of the reduction.
+func (s *Solver) MinimizeModel(assumptions []Literal) LBool {
+	best := s.model
+	if best == nil {
+		return Unknown
+	}
+	status := True
+	defer s.beginOperation()()
+
+	total := s.Statistics
+	defer func() { s.Statistics = total }()
+	nModels := len(s.Models) // the reduced model is the last one
+
+	lits := make([]Literal, 0, len(assumptions)+len(best))
+	for v := 0; v < len(best) && status == True; v++ {
+		if !best[v] {
+			continue
+		}
+		lits = append(lits[:0], assumptions...)
+		for u, val := range best {
+			if !val || u == v {
+				lits = append(lits, NegativeLiteral(u))
+			}
+		}
+		result := s.SolveWithAssumptions(lits)
+		total.add(&s.Statistics)
+		switch result.Status {
+		case True:
+			best = s.model
+		case Unknown:
+			status = Unknown
+		}
+	}
+
+	// Variables found true by the failed calls remain true in the following
+	// models: the model found last is minimal.
+	s.model = best
+	s.Models = append(s.Models[:nModels-1], best)
+	s.status = True
+	s.failedAssumptions = s.failedAssumptions[:0]
+	return status
+}
@@ -0,0 +1,79 @@
+package sat
+
+import "math/bits"
+
+// Learnt clauses with more than 1<<maxPoolClass literals are not pooled.
+const (
+	maxPoolClass        = 10
+	maxPooledClauseSize = 1 << maxPoolClass
+)
+
+// Number of clauses and literals allocated at once by the pool when it has no
+// deleted clause to recycle.
+const (
+	poolClauseChunk  = 1 << 10
+	poolLiteralChunk = 1 << 16
+)
+
+// clausePool stores the learnt clauses so that recording a clause does not
+// require any allocation in general. Clauses and their literals are carved
+// out of large chunks and the clauses deleted when reducing the clause DB are
+// recycled to store the clauses learnt afterwards. Recycled clauses are
+// grouped by size class: the literals of the clauses in free[i] have a
+// capacity of 1<<i.
+//
+// Note that a chunk is only garbage collected once none of its clauses is
+// referenced anymore. A disabled pool allocates each clause on its own and
+// does not recycle any clause.
+type clausePool struct {
+	enabled bool
+
+	free [maxPoolClass + 1][]*Clause
+
+	// Unused parts of the current chunks.
+	clauses  []Clause
+	literals []Literal
+}
+
+// get returns a clause with n literals and all its other fields set to their
+// zero value.
+func (p *clausePool) get(n int) *Clause {
+	if !p.enabled || n > maxPooledClauseSize {
+		return &Clause{literals: make([]Literal, n)}
+	}
+
+	i := bits.Len(uint(n - 1))
+	if k := len(p.free[i]); k > 0 {
+		c := p.free[i][k-1]
+		p.free[i][k-1] = nil
+		p.free[i] = p.free[i][:k-1]
+		c.literals = c.literals[:n]
+		return c
+	}
+
+	if len(p.clauses) == 0 {
+		p.clauses = make([]Clause, poolClauseChunk)
+	}
+	c := &p.clauses[0]
+	p.clauses = p.clauses[1:]
+
+	size := 1 << i
+	if len(p.literals) < size {
+		p.literals = make([]Literal, poolLiteralChunk)
+	}
+	c.literals = p.literals[:n:size]
+	p.literals = p.literals[size:]
+	return c
+}
+
+// put adds clause c to the pool. The clause must have been deleted and not be
+// referenced by the solver anymore, lits being its literals before deletion.
+func (p *clausePool) put(c *Clause, lits []Literal) {
+	n := cap(lits)
+	if !p.enabled || n > maxPooledClauseSize || n&(n-1) != 0 {
+		return // not allocated by get
+	}
+	*c = Clause{literals: lits[:0]}
+	i := bits.Len(uint(n - 1))
+	p.free[i] = append(p.free[i], c)
+}
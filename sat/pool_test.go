@@ -0,0 +1,38 @@
+package sat
+
+import "testing"
+
+func TestClausePool(t *testing.T) {
+	p := clausePool{enabled: true}
+
+	c := p.get(5)
+	if len(c.literals) != 5 || cap(c.literals) != 8 {
+		t.Fatalf("get(5): want 5 literals with capacity 8, got %d with capacity %d", len(c.literals), cap(c.literals))
+	}
+
+	c.lbd = 3
+	p.put(c, c.literals)
+	if got := p.get(7); got != c {
+		t.Errorf("get(7): want the recycled clause, got another one")
+	}
+	if c.lbd != 0 || len(c.literals) != 7 {
+		t.Errorf("get(7): want a reset clause with 7 literals, got lbd %d and %d literals", c.lbd, len(c.literals))
+	}
+	if got := p.get(7); got == c {
+		t.Errorf("get(7): want a new clause, got the recycled one")
+	}
+}
+
+func TestClausePool_disabled(t *testing.T) {
+	p := clausePool{}
+
+	c := p.get(4)
+	if cap(c.literals) != 4 {
+		t.Fatalf("get(4): want capacity 4, got %d", cap(c.literals))
+	}
+
+	p.put(c, c.literals)
+	if got := p.get(4); got == c {
+		t.Errorf("get(4): want a new clause, got the recycled one")
+	}
+}
@@ -0,0 +1,72 @@
+package sat
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// presets are curated bundles of options for families of instances. They only
+// tune the decision heuristic and the restart policy, the clause DB management
+// being left to its defaults.
+var presets = map[string][]Option{
+	// Options of DefaultOptions.
+	"default": {},
+
+	// Satisfiable instances benefit from phase saving, which keeps the search
+	// close to promising partial assignments, and from a small amount of
+	// random decisions to escape bad regions of the search space. Luby
+	// restarts keep most searches short while occasionally letting one run
+	// long enough to reach a model.
+	"sat": {
+		WithPhaseSaving(true),
+		WithRandomDecisionFreq(0.01),
+		WithRestarts(Restarts{Policy: RestartLuby, Initial: 100}),
+	},
+
+	// Unsatisfiable instances benefit from a faster decay of the variables'
+	// score, which focuses the search on the variables of recent conflicts.
+	"unsat": {
+		WithPhaseSaving(false),
+		WithVariableDecay(0.85),
+	},
+
+	// Cryptographic instances have a small core of hard variables that should
+	// keep being decided, hence phase saving and a fast decay of the scores.
+	"crypto": {
+		WithPhaseSaving(true),
+		WithVariableDecay(0.8),
+		WithRandomDecisionFreq(0),
+	},
+
+	// MaxSAT solvers make many incremental calls whose assignments are close
+	// to each other, which phase saving preserves.
+	"maxsat": {
+		WithPhaseSaving(true),
+		WithClauseDecay(0.9999),
+	},
+}
+
+// PresetNames returns the names of the available presets in lexical order.
+func PresetNames() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Preset returns the option applying the preset with the given name (see
+// PresetNames). Options applied after the preset override its values.
+func Preset(name string) (Option, error) {
+	opts, ok := presets[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown preset %q (available: %s)", name, strings.Join(PresetNames(), ", "))
+	}
+	return func(ops *Options) {
+		for _, o := range opts {
+			o(ops)
+		}
+	}, nil
+}
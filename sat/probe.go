@@ -0,0 +1,155 @@
+package sat
+
+import "slices"
+
+// defaultProbeBudget is the maximum number of propagations of each Probe call.
+const defaultProbeBudget = 1_000_000
+
+// Probe looks for failed literals, that is literals whose propagation leads to
+// a conflict, and asserts their negation at the root level. Literals are
+// probed along the trees of the binary implication graph: if a implies b, then
+// a is probed on top of the propagation of b, which it would derive anyway, so
+// that the propagation of b is shared by all the literals that imply it.
+//
+// Probing also detects the literals a that are equivalent to the literal b
+// they imply, as b's propagation assigns a to true. These are counted in the
+// statistics but not substituted.
+//
+// Probing stops early once it exhausts its propagation budget or if the solver
+// must stop (e.g. on timeout). The saved phases of the variables are restored
+// afterwards so that probing does not change the polarity of the decisions.
+//
+// Probe must be called at the root level. It returns false if the problem is
+// unsatisfiable.
+func (s *Solver) Probe() bool {
+	if s.decisionLevel() != 0 {
+		return !s.unsat
+	}
+	if s.unsat || s.Propagate() != nil {
+		s.unsat = true
+		return false
+	}
+
+	preds, roots := s.binaryImplicationTrees()
+	visited := make([]bool, len(preds))
+	phases := slices.Clone(s.order.phases)
+	s.tmpFailed = s.tmpFailed[:0]
+	s.probeLimit = s.Statistics.Propagations + s.probeBudget
+
+	for _, r := range roots {
+		if s.probeExhausted() {
+			break
+		}
+		if visited[r] || s.LitValue(r) != Unknown {
+			continue
+		}
+		visited[r] = true
+		s.probeLiteral(r, preds, visited)
+	}
+	copy(s.order.phases, phases)
+
+	for _, l := range s.tmpFailed {
+		if s.LitValue(l) != True && s.proof != nil {
+			s.proof.add([]Literal{l})
+		}
+		if !s.enqueue(l, nil) {
+			s.unsat = true
+			return false
+		}
+	}
+	if s.Propagate() != nil {
+		s.unsat = true
+		return false
+	}
+	return true
+}
+
+// binaryImplicationTrees returns the binary implication graph of the clause DB
+// as the list of the literals that imply each literal, and the literals from
+// which the trees of the graph must be probed. Literals that do not imply any
+// other literal are returned first as they are the roots of the trees. The
+// literals that remain are part of cycles.
+func (s *Solver) binaryImplicationTrees() ([][]Literal, []Literal) {
+	preds := make([][]Literal, 2*s.NumVariables())
+	implies := make([]bool, len(preds))
+	add := func(c *Clause) {
+		if len(c.literals) != 2 {
+			return
+		}
+		a, b := c.literals[0], c.literals[1]
+		if s.LitValue(a) != Unknown || s.LitValue(b) != Unknown {
+			return
+		}
+		preds[b] = append(preds[b], a.Opposite()) // ¬a implies b
+		preds[a] = append(preds[a], b.Opposite()) // ¬b implies a
+		implies[a.Opposite()] = true
+		implies[b.Opposite()] = true
+	}
+	for _, c := range s.constraints {
+		add(c)
+	}
+	for _, c := range s.cores {
+		add(c)
+	}
+	for _, c := range s.locals {
+		add(c)
+	}
+
+	roots := []Literal{}
+	for l := range preds {
+		if len(preds[l]) > 0 && !implies[l] {
+			roots = append(roots, Literal(l))
+		}
+	}
+	for l := range preds {
+		if len(preds[l]) > 0 && implies[l] {
+			roots = append(roots, Literal(l))
+		}
+	}
+	return preds, roots
+}
+
+// probeLiteral probes unassigned literal l on a new decision level, and then
+// the tree of the literals that imply l (see probeTree). The negation of l is
+// stored in tmpFailed if l fails.
+func (s *Solver) probeLiteral(l Literal, preds [][]Literal, visited []bool) {
+	s.Statistics.Probes++
+	s.assume(l)
+	if s.Propagate() != nil {
+		s.Statistics.FailedLiterals++
+		s.tmpFailed = append(s.tmpFailed, l.Opposite())
+	} else {
+		s.probeTree(l, preds, visited)
+	}
+	s.backtrackTo(s.decisionLevel() - 1)
+}
+
+// probeTree probes the literals that imply l, which is true on the current
+// decision level, and that have not been visited yet. As each of them also
+// implies l and the literals probed below l, it fails if the current
+// assignment makes it false.
+func (s *Solver) probeTree(l Literal, preds [][]Literal, visited []bool) {
+	for _, a := range preds[l] {
+		if visited[a] || s.probeExhausted() {
+			continue
+		}
+		visited[a] = true
+
+		switch s.LitValue(a) {
+		case True: // a implies l and l implies a
+			s.Statistics.Equivalences++
+			s.probeTree(a, preds, visited)
+		case False:
+			s.Statistics.FailedLiterals++
+			s.tmpFailed = append(s.tmpFailed, a.Opposite())
+		default:
+			s.probeLiteral(a, preds, visited)
+		}
+	}
+}
+
+// probeExhausted returns true if the current Probe call must stop, either
+// because it exhausted its propagation budget or because the solver must stop.
+func (s *Solver) probeExhausted() bool {
+	return s.Statistics.Propagations >= s.probeLimit || s.checkStop() != NotStopped
+}
@@ -0,0 +1,35 @@
+package sat
+
+import "time"
+
+// Progress is a snapshot of the search progress reported to the OnProgress
+// callback of the solver's options.
+type Progress struct {
+	Time         time.Duration // time elapsed since the start of the search
+	Conflicts    uint64
+	Propagations uint64
+	Decisions    uint64
+	Restarts     uint64
+	Learnts      int     // number of learnt clauses (local and core)
+	CoreLearnts  int     // number of core learnt clauses
+	AvgCoreLBD   float64 // average LBD of the core learnt clauses
+	AvgLearntLBD float64 // moving average of the LBD of new learnt clauses
+}
+
+// progress returns a snapshot of the search progress.
+func (s *Solver) progress() Progress {
+	p := Progress{
+		Time:         time.Since(s.startTime),
+		Conflicts:    s.Statistics.Conflicts,
+		Propagations: s.Statistics.Propagations,
+		Decisions:    s.Statistics.Decisions,
+		Restarts:     s.Statistics.Restarts,
+		Learnts:      s.NumLearnts() + len(s.cores),
+		CoreLearnts:  len(s.cores),
+		AvgLearntLBD: s.Statistics.AvgLearntLBD.Val(),
+	}
+	if len(s.cores) > 0 {
+		p.AvgCoreLBD = float64(s.Statistics.TotalCoreLBD) / float64(len(s.cores))
+	}
+	return p
+}
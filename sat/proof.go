@@ -0,0 +1,114 @@
+package sat
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ProofFormat is the format of the unsatisfiability proofs written by the
+// solver. Only DRAT proofs are supported: LRAT proofs would require to record
+// the antecedents of every clause derived by the solver, which its conflict
+// analysis and inprocessing (e.g. probing and ternary resolution) do not do.
+type ProofFormat uint8
+
+const (
+	// ProofText writes DRAT proofs in their textual format.
+	ProofText ProofFormat = iota
+
+	// ProofBinary writes DRAT proofs in their binary format, which is more
+	// compact and faster to write and check.
+	ProofBinary
+)
+
+func (f ProofFormat) String() string {
+	switch f {
+	case ProofText:
+		return "text"
+	case ProofBinary:
+		return "binary"
+	default:
+		return fmt.Sprintf("ProofFormat(%d)", f)
+	}
+}
+
+// proofWriter writes a DRAT proof, that is the sequence of clauses added to
+// and deleted from the clause DB, terminated by the empty clause if the problem
+// is unsatisfiable. Literals are written in the DIMACS convention.
+type proofWriter struct {
+	w      *bufio.Writer
+	binary bool
+	buf    []byte
+	err    error // first write error
+
+	// Whether the empty clause was written.
+	done bool
+}
+
+func newProofWriter(w io.Writer, format ProofFormat) *proofWriter {
+	return &proofWriter{
+		w:      bufio.NewWriter(w),
+		binary: format == ProofBinary,
+		buf:    make([]byte, 0, 64),
+	}
+}
+
+// add logs the addition of the clause.
+func (pw *proofWriter) add(lits []Literal) {
+	pw.write('a', lits)
+}
+
+// delete logs the deletion of the clause.
+func (pw *proofWriter) delete(lits []Literal) {
+	pw.write('d', lits)
+}
+
+// empty logs the addition of the empty clause, which concludes the proof.
+func (pw *proofWriter) empty() {
+	if !pw.done {
+		pw.done = true
+		pw.write('a', nil)
+	}
+}
+
+func (pw *proofWriter) write(op byte, lits []Literal) {
+	if pw.err != nil {
+		return
+	}
+	b := pw.buf[:0]
+	if pw.binary {
+		b = append(b, op)
+		for _, l := range lits {
+			// Literal of variable v (from 1) is mapped to 2v, and its negation
+			// to 2v+1, which is exactly l+2 in the solver's encoding.
+			for u := uint32(l) + 2; ; u >>= 7 {
+				if u < 0x80 {
+					b = append(b, byte(u))
+					break
+				}
+				b = append(b, byte(u&0x7f|0x80))
+			}
+		}
+		b = append(b, 0)
+	} else {
+		if op == 'd' {
+			b = append(b, 'd', ' ')
+		}
+		for _, l := range lits {
+			b = strconv.AppendInt(b, int64(l.ToDIMACS()), 10)
+			b = append(b, ' ')
+		}
+		b = append(b, '0', '\n')
+	}
+	pw.buf = b
+	_, pw.err = pw.w.Write(b)
+}
+
+// flush writes the buffered proof to the underlying writer.
+func (pw *proofWriter) flush() error {
+	if pw.err == nil {
+		pw.err = pw.w.Flush()
+	}
+	return pw.err
+}
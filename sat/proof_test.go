@@ -0,0 +1,118 @@
+package sat
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// isRUP returns true if unit propagation on the clauses and the negation of the
+// candidate clause leads to a conflict. Literals are in the DIMACS convention.
+func isRUP(clauses [][]int, candidate []int) bool {
+	assigned := map[int]bool{}
+	for _, l := range candidate {
+		assigned[-l] = true
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, c := range clauses {
+			unassigned, free := 0, 0
+			satisfied := false
+			for _, l := range c {
+				switch {
+				case assigned[l]:
+					satisfied = true
+				case !assigned[-l]:
+					unassigned++
+					free = l
+				}
+			}
+			if satisfied {
+				continue
+			}
+			if unassigned == 0 {
+				return true
+			}
+			if unassigned == 1 {
+				assigned[free] = true
+				changed = true
+			}
+		}
+	}
+	return false
+}
+
+// checkRUPProof checks that every clause added by the text proof is RUP with
+// respect to the clauses and the previously added clauses, deletions being
+// ignored, and that the proof ends with the empty clause.
+func checkRUPProof(t *testing.T, clauses [][]int, proof string) {
+	t.Helper()
+	lines := strings.Split(strings.TrimSpace(proof), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "d ") {
+			continue
+		}
+		clause := []int{}
+		for _, f := range strings.Fields(line) {
+			l, err := strconv.Atoi(f)
+			if err != nil {
+				t.Fatalf("proof line %d: invalid literal %q", i+1, f)
+			}
+			if l != 0 {
+				clause = append(clause, l)
+			}
+		}
+		if !isRUP(clauses, clause) {
+			t.Fatalf("proof line %d: clause %q is not RUP", i+1, line)
+		}
+		clauses = append(clauses, clause)
+	}
+	if last := lines[len(lines)-1]; last != "0" {
+		t.Errorf("last proof line: want \"0\", got %q", last)
+	}
+}
+
+func TestWithProof_text(t *testing.T) {
+	proof := &bytes.Buffer{}
+	s, err := NewSolver(WithProof(proof, ProofText))
+	if err != nil {
+		t.Fatalf("NewSolver(): want no error, got %s", err)
+	}
+	addPigeonhole(s, 4)
+
+	clauses := [][]int{}
+	for _, c := range s.constraints {
+		clauses = append(clauses, LiteralsToDIMACS(c.literals))
+	}
+	for _, l := range s.trail { // root-level units
+		clauses = append(clauses, LiteralsToDIMACS([]Literal{l}))
+	}
+
+	if status := s.Solve().Status; status != False {
+		t.Fatalf("Solve(): want false, got %s", status)
+	}
+	if err := s.ProofError(); err != nil {
+		t.Fatalf("ProofError(): want no error, got %s", err)
+	}
+
+	checkRUPProof(t, clauses, proof.String())
+}
+
+func TestProofWriter_binary(t *testing.T) {
+	buf := &bytes.Buffer{}
+	pw := newProofWriter(buf, ProofBinary)
+	pw.add([]Literal{PositiveLiteral(0), NegativeLiteral(63)})
+	pw.delete([]Literal{NegativeLiteral(1)})
+	pw.empty()
+	pw.empty() // written only once
+	if err := pw.flush(); err != nil {
+		t.Fatalf("flush(): want no error, got %s", err)
+	}
+
+	// Literal -64 is mapped to 129, which is encoded on two bytes.
+	want := []byte{'a', 2, 0x81, 0x01, 0, 'd', 5, 0, 'a', 0}
+	if got := buf.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("binary proof: want %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,40 @@
+package sat
+
+// reconstructionStack records the clauses removed by simplifications that do
+// not preserve models (e.g. variable elimination or blocked clause
+// elimination) so that the models of the simplified problem can be extended
+// into models of the original problem.
+//
+// Each removed clause is recorded with a witness literal of the clause. A
+// model is extended by going through the clauses in the reverse order of
+// their removal and flipping the variable of the witness of each clause that
+// is not satisfied, which satisfies the clause.
+type reconstructionStack struct {
+	literals  []Literal // literals of the removed clauses, one after the other
+	ends      []int     // end of each clause in literals
+	witnesses []Literal
+}
+
+// push records a removed clause along with its witness literal, which must
+// belong to the clause.
+func (rs *reconstructionStack) push(witness Literal, clause []Literal) {
+	rs.literals = append(rs.literals, clause...)
+	rs.ends = append(rs.ends, len(rs.literals))
+	rs.witnesses = append(rs.witnesses, witness)
+}
+
+// extend modifies the model of the simplified problem into a model of the
+// original problem.
+func (rs *reconstructionStack) extend(model []bool) {
+	for i := len(rs.witnesses) - 1; i >= 0; i-- {
+		start := 0
+		if i > 0 {
+			start = rs.ends[i-1]
+		}
+		if isSatisfied(rs.literals[start:rs.ends[i]], model) {
+			continue
+		}
+		w := rs.witnesses[i]
+		model[w.VarID()] = w.IsPositive()
+	}
+}
@@ -0,0 +1,31 @@
+package sat
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReconstructionStack(t *testing.T) {
+	// Eliminating x1 from (x0 | x1) & (!x1 | x2) leaves the resolvent
+	// (x0 | x2), whose model {x0: false, x1: false, x2: true} violates the
+	// first removed clause.
+	rs := &reconstructionStack{}
+	rs.push(PositiveLiteral(1), []Literal{PositiveLiteral(0), PositiveLiteral(1)})
+	rs.push(NegativeLiteral(1), []Literal{NegativeLiteral(1), PositiveLiteral(2)})
+
+	model := []bool{false, false, true}
+	rs.extend(model)
+
+	want := []bool{false, true, true}
+	if diff := cmp.Diff(want, model); diff != "" {
+		t.Errorf("extend(): mismatch (+want, -got):\n%s", diff)
+	}
+	clauses := [][]Literal{
+		{PositiveLiteral(0), PositiveLiteral(1)},
+		{NegativeLiteral(1), PositiveLiteral(2)},
+	}
+	if i := VerifyModel(clauses, model); i >= 0 {
+		t.Errorf("extend(): model violates clause %d", i)
+	}
+}
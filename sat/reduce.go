@@ -0,0 +1,248 @@
+package sat
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+)
+
+// ReducePolicy determines which learnt clauses are deleted first when the
+// clause DB is reduced.
+type ReducePolicy uint8
+
+const (
+	// ReduceActivity deletes the clauses with the lowest activity first.
+	ReduceActivity ReducePolicy = iota
+
+	// ReduceLBD deletes the clauses with the highest LBD first, breaking ties
+	// by deleting the ones with the lowest activity first.
+	ReduceLBD
+
+	// ReduceHybrid ranks the clauses by activity and by LBD, and deletes the
+	// clauses with the worst sum of ranks first.
+	ReduceHybrid
+)
+
+func (p ReducePolicy) String() string {
+	switch p {
+	case ReduceActivity:
+		return "activity"
+	case ReduceLBD:
+		return "lbd"
+	case ReduceHybrid:
+		return "hybrid"
+	default:
+		return fmt.Sprintf("ReducePolicy(%d)", p)
+	}
+}
+
+// ReduceSchedule determines when the learnt clause DB is reduced.
+type ReduceSchedule uint8
+
+const (
+	// ReduceEveryConflicts reduces the clause DB every Interval conflicts.
+	ReduceEveryConflicts ReduceSchedule = iota
+
+	// ReduceGeometricLimit reduces the clause DB when the number of learnt
+	// clauses that can be deleted exceeds a limit, as in MiniSat. The limit is
+	// set to LearntsFactor times the number of problem clauses at the start of
+	// each solve call, and then multiplied by LearntsGrowth after 100
+	// conflicts, then 150, 225, and so on.
+	ReduceGeometricLimit
+)
+
+func (rs ReduceSchedule) String() string {
+	switch rs {
+	case ReduceEveryConflicts:
+		return "every conflicts"
+	case ReduceGeometricLimit:
+		return "geometric limit"
+	default:
+		return fmt.Sprintf("ReduceSchedule(%d)", rs)
+	}
+}
+
+// Reductions configures the reductions of the learnt clause DB, which happen
+// according to Schedule. Learnt clauses with an LBD of at most CoreLBD are
+// moved to the core tier, whose clauses are never deleted. Core clauses that
+// neither propagated nor took part in a conflict analysis during DemoteAfter
+// consecutive reductions are moved back to the local tier (0 disables the
+// demotions). Each reduction then deletes a Fraction of the local learnt
+// clauses, chosen according to Policy. Clauses that are reasons of the current
+// assignments, that have an LBD of at most 2, or that are binary are never
+// deleted.
+type Reductions struct {
+	Policy      ReducePolicy
+	Fraction    float64
+	CoreLBD     uint32
+	DemoteAfter int
+
+	Schedule      ReduceSchedule
+	Interval      uint64  // only used by ReduceEveryConflicts
+	LearntsFactor float64 // only used by ReduceGeometricLimit
+	LearntsGrowth float64 // only used by ReduceGeometricLimit
+}
+
+// validate returns an error if the reduction configuration is invalid.
+func (r Reductions) validate() error {
+	if r.Fraction < 0 || r.Fraction > 1 {
+		return fmt.Errorf("reduction fraction must be in [0, 1], got %v", r.Fraction)
+	}
+	if r.DemoteAfter < 0 || r.DemoteAfter > math.MaxUint8 {
+		return fmt.Errorf("demotion delay must be in [0, %d], got %d", math.MaxUint8, r.DemoteAfter)
+	}
+	switch r.Policy {
+	case ReduceActivity, ReduceLBD, ReduceHybrid:
+	default:
+		return fmt.Errorf("unsupported reduction policy %s", r.Policy)
+	}
+	switch r.Schedule {
+	case ReduceEveryConflicts:
+		if r.Interval == 0 {
+			return fmt.Errorf("reduction interval must be positive")
+		}
+	case ReduceGeometricLimit:
+		if r.LearntsFactor <= 0 {
+			return fmt.Errorf("learnts factor must be positive, got %v", r.LearntsFactor)
+		}
+		if r.LearntsGrowth < 1 {
+			return fmt.Errorf("learnts growth must be at least 1, got %v", r.LearntsGrowth)
+		}
+	default:
+		return fmt.Errorf("unsupported reduction schedule %s", r.Schedule)
+	}
+	return nil
+}
+
+// learntsLimit is the limit on the number of learnt clauses of the
+// ReduceGeometricLimit schedule.
+type learntsLimit struct {
+	max       float64 // maximum number of deletable learnt clauses
+	adjust    float64 // conflicts between two increases of max
+	adjustCnt int     // conflicts before the next increase of max
+}
+
+// initReductions sets the first reductions of the ReduceEveryConflicts
+// schedule, which then carries over from one solve call to the next.
+func (s *Solver) initReductions() {
+	s.conflictBeforeReduce = s.reductions.Interval
+	s.conflictBeforeReduceInc = s.reductions.Interval
+	s.conflictBeforeReduceIncInc = 0
+}
+
+// resetReductions initializes the reduction schedule of a solve call.
+func (s *Solver) resetReductions() {
+	if s.reductions.Schedule == ReduceGeometricLimit {
+		s.learntsLimit = learntsLimit{
+			max:       float64(s.NumConstraints()) * s.reductions.LearntsFactor,
+			adjust:    100,
+			adjustCnt: 100,
+		}
+	}
+}
+
+// onConflictReductions updates the reduction schedule after a conflict.
+func (s *Solver) onConflictReductions() {
+	if s.reductions.Schedule != ReduceGeometricLimit {
+		return
+	}
+	ll := &s.learntsLimit
+	ll.adjustCnt--
+	if ll.adjustCnt == 0 {
+		ll.adjust *= 1.5
+		ll.adjustCnt = int(ll.adjust)
+		ll.max *= s.reductions.LearntsGrowth
+	}
+}
+
+// shouldReduce returns true if the clause DB must be reduced now. It assumes
+// that the clause DB is reduced if so.
+func (s *Solver) shouldReduce() bool {
+	if s.reductions.Schedule == ReduceGeometricLimit {
+		return float64(len(s.locals)-s.NumAssigns()) >= s.learntsLimit.max
+	}
+	if s.Statistics.Conflicts < s.conflictBeforeReduce {
+		return false
+	}
+	s.conflictBeforeReduceInc += s.conflictBeforeReduceIncInc
+	s.conflictBeforeReduce += s.conflictBeforeReduceInc
+	return true
+}
+
+// demoteCores moves the core clauses that were not used during the last
+// Reductions.DemoteAfter reductions back to the local tier. Demoted clauses keep
+// their LBD (and thus the protection of glue clauses) as well as their idle
+// count, which prevents ReduceDB from promoting them again until they are used.
+func (s *Solver) demoteCores() {
+	if s.reductions.DemoteAfter == 0 {
+		return
+	}
+	k := 0
+	for _, c := range s.cores {
+		if c.used {
+			c.used = false
+			c.idle = 0
+		} else {
+			c.idle++
+		}
+		if int(c.idle) < s.reductions.DemoteAfter {
+			s.cores[k] = c
+			k++
+			continue
+		}
+		s.Statistics.TotalCoreLBD -= uint64(c.lbd)
+		s.Statistics.Demotions++
+		s.setTier(c, TierLocal)
+		s.locals = append(s.locals, c)
+	}
+	clear(s.cores[k:])
+	s.cores = s.cores[:k]
+}
+
+// sortForReduction sorts the learnt clauses from the first to be deleted to the
+// last according to the reduction policy.
+func sortForReduction(clauses []*Clause, policy ReducePolicy) {
+	byActivity := func(a, b *Clause) int {
+		switch {
+		case a.activity < b.activity:
+			return -1
+		case a.activity > b.activity:
+			return 1
+		default:
+			return 0
+		}
+	}
+	byLBD := func(a, b *Clause) int {
+		switch {
+		case a.lbd > b.lbd:
+			return -1
+		case a.lbd < b.lbd:
+			return 1
+		default:
+			return byActivity(a, b)
+		}
+	}
+
+	switch policy {
+	case ReduceActivity:
+		sort.Slice(clauses, func(i, j int) bool {
+			return clauses[i].activity < clauses[j].activity
+		})
+	case ReduceLBD:
+		slices.SortFunc(clauses, byLBD)
+	case ReduceHybrid:
+		ranks := make(map[*Clause]int, len(clauses))
+		slices.SortFunc(clauses, byActivity)
+		for i, c := range clauses {
+			ranks[c] = i
+		}
+		slices.SortFunc(clauses, byLBD)
+		for i, c := range clauses {
+			ranks[c] += i
+		}
+		slices.SortStableFunc(clauses, func(a, b *Clause) int {
+			return ranks[a] - ranks[b]
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package sat
+
+import (
+	"fmt"
+	"slices"
+)
+
+// AddRemovableClause adds a clause that can later be removed with
+// RemoveClause. The clause is extended with the negation of a fresh selector
+// variable, whose positive literal is returned, and the selector is assumed
+// by every solve call until the clause is removed. The selector variable is
+// frozen (see Freeze) and counts as a regular variable (e.g. in models).
+//
+// As selectors are assumptions, a solve call can return False because of
+// removable clauses, in which case FailedAssumptions contains the selectors
+// of the clauses involved.
+func (s *Solver) AddRemovableClause(lits []Literal) (Literal, error) {
+	v := s.AddVariable()
+	selector := PositiveLiteral(v)
+	s.Freeze(v)
+
+	clause := append(slices.Clone(lits), selector.Opposite())
+	if err := s.AddClause(clause); err != nil {
+		return 0, err
+	}
+	s.selectors = append(s.selectors, selector)
+	return selector, nil
+}
+
+// RemoveClause permanently removes the clause added by AddRemovableClause
+// with the given selector. The clause is satisfied by fixing its selector to
+// false at the root level.
+func (s *Solver) RemoveClause(selector Literal) error {
+	i := slices.Index(s.selectors, selector)
+	if i < 0 {
+		return fmt.Errorf("unknown selector %s", selector)
+	}
+	s.selectors = slices.Delete(s.selectors, i, i+1)
+	return s.AddClause([]Literal{selector.Opposite()})
+}
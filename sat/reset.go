@@ -0,0 +1,44 @@
+package sat
+
+// Reset brings the solver back to the state it had after its problem was
+// loaded so that it can be reused for independent solve calls: the learnt
+// clauses, models, statistics, and clause DB reduction schedule are cleared.
+// The problem clauses, variables, and root-level assignments (which are
+// implied by the problem clauses) are kept, as well as the variables' scores
+// and phases (see ResetActivities).
+func (s *Solver) Reset() {
+	s.backtrackTo(0)
+
+	for _, c := range s.locals {
+		c.Delete(s)
+	}
+	for _, c := range s.cores {
+		c.Delete(s)
+	}
+	s.locals = s.locals[:0]
+	s.cores = s.cores[:0]
+
+	// Root-level assignments do not need reasons as they are never explained
+	// during conflict analysis.
+	for _, l := range s.trail {
+		if r := s.assignReasons[l.VarID()]; r != nil && r.isLearnt() {
+			s.assignReasons[l.VarID()] = nil
+		}
+	}
+
+	s.clauseInc = 1
+	s.initReductions()
+
+	s.resetStatistics()
+	s.Models = nil
+	s.model = nil
+	s.failedAssumptions = s.failedAssumptions[:0]
+	s.status = Unknown
+	s.stopReason = NotStopped
+}
+
+// ResetActivities resets the scores and phases of the variables to their
+// initial values so that the next search does not depend on previous ones.
+func (s *Solver) ResetActivities() {
+	s.order.reset()
+}
@@ -0,0 +1,152 @@
+package sat
+
+import (
+	"fmt"
+	"math"
+)
+
+// RestartPolicy determines how the conflict budget of the successive searches
+// between two restarts evolves.
+type RestartPolicy uint8
+
+const (
+	// RestartArithmetic adds Increment conflicts to the budget after each
+	// restart.
+	RestartArithmetic RestartPolicy = iota
+
+	// RestartGeometric multiplies the budget by Increment after each restart.
+	RestartGeometric
+
+	// RestartLuby sets the budget of the i-th search to Initial times the i-th
+	// term of the Luby sequence (1, 1, 2, 1, 1, 2, 4, 1, ...). Increment is not
+	// used.
+	RestartLuby
+
+	// RestartGlucose restarts dynamically, as in Glucose, once the recent
+	// learnt clauses are worse than average: after at least Initial conflicts,
+	// the search restarts as soon as the fast moving average of the learnt
+	// clauses' LBD exceeds Increment times the slow one (see Averages). A
+	// restart is postponed by Initial conflicts when a conflict occurs with a
+	// trail much longer than average (see glucoseBlockingFactor), as the
+	// search is then likely to be close to a model.
+	RestartGlucose
+)
+
+// glucoseBlockingFactor is the ratio between the trail's length and its moving
+// average above which RestartGlucose postpones the next restart.
+const glucoseBlockingFactor = 1.4
+
+func (p RestartPolicy) String() string {
+	switch p {
+	case RestartArithmetic:
+		return "arithmetic"
+	case RestartGeometric:
+		return "geometric"
+	case RestartLuby:
+		return "luby"
+	case RestartGlucose:
+		return "glucose"
+	default:
+		return fmt.Sprintf("RestartPolicy(%d)", p)
+	}
+}
+
+// Restarts configures the conflict budget of the searches between two
+// restarts. The first search of each solve call has a budget of Initial
+// conflicts. These budgets are independent of MaxConflicts, which bounds the
+// total number of conflicts of a solve call.
+type Restarts struct {
+	Policy    RestartPolicy
+	Initial   uint64
+	Increment float64
+}
+
+// validate returns an error if the restart configuration is invalid.
+func (r Restarts) validate() error {
+	if r.Initial == 0 {
+		return fmt.Errorf("initial restart budget must be positive")
+	}
+	switch r.Policy {
+	case RestartArithmetic:
+		if r.Increment < 0 {
+			return fmt.Errorf("arithmetic restart increment must be positive, got %v", r.Increment)
+		}
+	case RestartGeometric:
+		if r.Increment < 1 {
+			return fmt.Errorf("geometric restart increment must be at least 1, got %v", r.Increment)
+		}
+	case RestartLuby:
+	case RestartGlucose:
+		if r.Increment < 1 {
+			return fmt.Errorf("glucose restart margin must be at least 1, got %v", r.Increment)
+		}
+	default:
+		return fmt.Errorf("unsupported restart policy %s", r.Policy)
+	}
+	return nil
+}
+
+// restartSchedule generates the conflict budgets of the successive searches of
+// a solve call.
+type restartSchedule struct {
+	Restarts
+	searches int     // number of budgets generated so far
+	budget   float64 // budget of the next search (arithmetic and geometric)
+}
+
+func newRestartSchedule(r Restarts) restartSchedule {
+	return restartSchedule{Restarts: r, budget: float64(r.Initial)}
+}
+
+// next returns the conflict budget of the next search. The budget of the
+// RestartGlucose searches is unlimited as they decide when to restart.
+func (rs *restartSchedule) next() uint64 {
+	i := rs.searches
+	rs.searches++
+
+	switch rs.Policy {
+	case RestartLuby:
+		return rs.Initial * luby(i)
+	case RestartGlucose:
+		return math.MaxUint64
+	}
+	budget := rs.budget
+	if rs.Policy == RestartGeometric {
+		rs.budget *= rs.Increment
+	} else {
+		rs.budget += rs.Increment
+	}
+	return uint64(budget)
+}
+
+// blockGlucoseRestart returns true if the RestartGlucose restarts must be
+// postponed because the trail of the current conflict is much longer than
+// average. It must be called before adding the trail to its average.
+func (s *Solver) blockGlucoseRestart() bool {
+	return float64(len(s.trail)) > glucoseBlockingFactor*s.Statistics.AvgTrail.Val()
+}
+
+// glucoseRestart returns true if a RestartGlucose search must restart, given
+// the number of conflicts since its last restart (or postponement).
+func (s *Solver) glucoseRestart(conflicts uint64) bool {
+	st := &s.Statistics
+	return conflicts >= s.restarts.Initial &&
+		st.AvgFastLBD.Val() > s.restarts.Increment*st.AvgLearntLBD.Val()
+}
+
+// luby returns the i-th term (starting from 0) of the Luby sequence.
+func luby(i int) uint64 {
+	// Find the smallest complete subsequence containing i, of size 2^(k+1)-1,
+	// then the term's position in the finite subsequences it is made of.
+	size, k := 1, 0
+	for size < i+1 {
+		k++
+		size = 2*size + 1
+	}
+	for size-1 != i {
+		size = (size - 1) >> 1
+		k--
+		i = i % size
+	}
+	return 1 << k
+}
@@ -0,0 +1,37 @@
+package sat
+
+import "slices"
+
+// Result summarizes the outcome of a solve call. Callers can tell from
+// StopReason why the search ended without deciding the problem, e.g. to
+// distinguish a timeout from an exhausted conflict budget.
+type Result struct {
+	// Status of the problem: True if satisfiable, False if unsatisfiable, and
+	// Unknown if the search was stopped before deciding it.
+	Status LBool
+
+	// Reason why the search was stopped if Status is Unknown (NotStopped
+	// otherwise), e.g. to distinguish a timeout from an exhausted budget.
+	StopReason StopReason
+
+	// Snapshot of the search statistics at the end of the call.
+	Statistics Statistics
+
+	// Model found if Status is True (nil otherwise).
+	Model []bool
+
+	// Failed assumptions if Status is False (see FailedAssumptions).
+	FailedAssumptions []Literal
+}
+
+// result returns the result of the last solve call. The result is a snapshot
+// that is not modified by later calls.
+func (s *Solver) result() Result {
+	return Result{
+		Status:            s.status,
+		StopReason:        s.stopReason,
+		Statistics:        s.Statistics,
+		Model:             s.model,
+		FailedAssumptions: slices.Clone(s.failedAssumptions),
+	}
+}
@@ -1,10 +1,15 @@
 package sat
 
+import (
+	"math"
+	"slices"
+)
+
 // ResetSet represents a set of integers from 0 to N-1 where N is the capacity
 // of the set.
 type ResetSet struct {
-	addedAt        []uint16
-	addedTimestamp uint16
+	addedAt        []uint32
+	addedTimestamp uint32
 }
 
 // Contains returns true if v is in the set.
@@ -17,18 +22,30 @@ func (rs *ResetSet) Add(v int) {
 	rs.addedAt[v] = rs.addedTimestamp
 }
 
-// Clear removes all the elements in the set in constant time.
+// Remove removes v from the set.
+func (rs *ResetSet) Remove(v int) {
+	rs.addedAt[v] = rs.addedTimestamp - 1
+}
+
+// Clear removes all the elements in the set in constant time. The timestamps
+// only need to be reset every 2^32-1 calls.
 func (rs *ResetSet) Clear() {
-	rs.addedTimestamp++
-	if rs.addedTimestamp == 0 { // overflow
-		rs.addedTimestamp = 1
-		for i := range rs.addedAt {
-			rs.addedAt[i] = 0
-		}
+	if rs.addedTimestamp == math.MaxUint32 { // overflow
+		rs.addedTimestamp = 0
+		clear(rs.addedAt)
 	}
+	rs.addedTimestamp++
+}
+
+// Expand increases the capacity of the set by n.
+func (rs *ResetSet) Expand(n int) {
+	size := len(rs.addedAt)
+	rs.addedAt = slices.Grow(rs.addedAt, n)[:size+n]
+	clear(rs.addedAt[size:])
 }
 
-// Expand increases the capacity of the set.
-func (rs *ResetSet) Expand() {
-	rs.addedAt = append(rs.addedAt, 0)
+// reserve pre-allocates the set for n additional elements without increasing
+// its capacity.
+func (rs *ResetSet) reserve(n int) {
+	rs.addedAt = slices.Grow(rs.addedAt, n)
 }
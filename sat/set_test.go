@@ -0,0 +1,33 @@
+package sat
+
+import (
+	"math"
+	"testing"
+)
+
+func TestResetSet(t *testing.T) {
+	rs := ResetSet{}
+	rs.Expand(3)
+	rs.Clear()
+	rs.Add(1)
+	rs.Add(2)
+	rs.Remove(2)
+	rs.Expand(2)
+
+	for v, want := range []bool{false, true, false, false, false} {
+		if got := rs.Contains(v); got != want {
+			t.Errorf("Contains(%d): want %t, got %t", v, want, got)
+		}
+	}
+
+	// Clearing the set when the timestamps overflow must not resurrect
+	// elements added long ago.
+	rs.addedTimestamp = math.MaxUint32
+	rs.Add(2)
+	rs.Clear()
+	for v := 0; v < 5; v++ {
+		if rs.Contains(v) {
+			t.Errorf("Contains(%d) after overflow: want false, got true", v)
+		}
+	}
+}
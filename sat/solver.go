@@ -2,29 +2,149 @@ package sat
 
 import (
 	"fmt"
-	"log"
-	"sort"
+	"io"
+	"math"
+	"math/rand"
+	"slices"
+	"sync/atomic"
 	"time"
 )
 
 type Statistics struct {
-	Propagations     uint64
-	Guards           uint64
-	Conflicts        uint64
-	Iterations       uint64
-	Decisions        uint64
-	Restarts         uint64
-	TotalCoreLBD     uint64
-	AvgConflictLevel EMA
+	Propagations uint64 `json:"propagations"`
+	Guards       uint64 `json:"guards"`
+	Conflicts    uint64 `json:"conflicts"`
+	Iterations   uint64 `json:"iterations"`
+	Decisions    uint64 `json:"decisions"` // branching decisions, assumptions excluded
+	Restarts     uint64 `json:"restarts"`
+	TotalCoreLBD uint64 `json:"total_core_lbd"`
+	ExtraLearnts uint64 `json:"extra_learnts"` // see Options.ExtraLearnts and LearnDIP
+
+	// Number of core clauses moved back to the local tier because they were
+	// not used (see Reductions.DemoteAfter).
+	Demotions uint64 `json:"demotions"`
+
+	// Number of literals probed, of failed literals found and of equivalent
+	// literals detected by Probe.
+	Probes         uint64 `json:"probes"`
+	FailedLiterals uint64 `json:"failed_literals"`
+	Equivalences   uint64 `json:"equivalences"`
+
+	// Number of resolvents added by TernaryResolution and of problem clauses
+	// they subsumed.
+	TernaryResolvents uint64 `json:"ternary_resolvents"`
+	SubsumedClauses   uint64 `json:"subsumed_clauses"`
+
+	// Number of literals removed from learnt clauses by resolution with binary
+	// clauses (see Options.BinaryStrengthenLBD).
+	StrengthenedLiterals uint64 `json:"strengthened_literals"`
+
+	// Moving averages updated on each conflict, whose decays are configured
+	// with Options.Averages. Comparing the fast and slow averages of the LBD
+	// of the learnt clauses tells whether the recent conflicts are better or
+	// worse than usual.
+	AvgConflictLevel EMA `json:"avg_conflict_level"` // decision level of the conflicts
+	AvgLearntLBD     EMA `json:"avg_learnt_lbd"`     // slow average of the LBD of learnt clauses
+	AvgFastLBD       EMA `json:"avg_fast_lbd"`       // fast average of the LBD of learnt clauses
+	AvgTrail         EMA `json:"avg_trail"`          // number of assigned literals when conflicting
+
+	// Propagation statistics of each tier of clauses, indexed by Tier.
+	Tiers [numTiers]TierStatistics `json:"tiers"`
+}
+
+// add adds the counters of o to st. The moving averages and the total LBD of
+// the core clauses, which describe the state of the search rather than count
+// events, are replaced by those of o.
+func (st *Statistics) add(o *Statistics) {
+	st.Propagations += o.Propagations
+	st.Guards += o.Guards
+	st.Conflicts += o.Conflicts
+	st.Iterations += o.Iterations
+	st.Decisions += o.Decisions
+	st.Restarts += o.Restarts
+	st.TotalCoreLBD = o.TotalCoreLBD
+	st.ExtraLearnts += o.ExtraLearnts
+	st.Demotions += o.Demotions
+	st.Probes += o.Probes
+	st.FailedLiterals += o.FailedLiterals
+	st.Equivalences += o.Equivalences
+	st.TernaryResolvents += o.TernaryResolvents
+	st.SubsumedClauses += o.SubsumedClauses
+	st.StrengthenedLiterals += o.StrengthenedLiterals
+	st.AvgConflictLevel = o.AvgConflictLevel
+	st.AvgLearntLBD = o.AvgLearntLBD
+	st.AvgFastLBD = o.AvgFastLBD
+	st.AvgTrail = o.AvgTrail
+	for t := range st.Tiers {
+		st.Tiers[t].Visits += o.Tiers[t].Visits
+		st.Tiers[t].Guards += o.Tiers[t].Guards
+		st.Tiers[t].Implied += o.Tiers[t].Implied
+	}
 }
 
 type Solver struct {
 	// Variable ordering.
 	order *VarOrder
 
+	// Source of randomness used for all the solver's stochastic choices.
+	rng *rand.Rand
+
+	// Probability of making a random decision instead of following the
+	// variable ordering.
+	randomDecisionFreq float64
+
+	// Conflict budget of the searches between two restarts.
+	restarts Restarts
+
+	// Configuration of the reductions of the learnt clause DB.
+	reductions Reductions
+
+	// Decays of the moving averages maintained on conflicts.
+	averages Averages
+
+	// Scheme used to learn a clause from each conflict.
+	learning LearningScheme
+
+	// Learnt clauses with an LBD of at most binaryStrengthenLBD are
+	// strengthened with the binary clauses (0 disables the strengthening).
+	binaryStrengthenLBD int
+
+	// If true, failed literals are probed at the start of each solve call.
+	// Each Probe call stops once it performed probeBudget propagations, when
+	// the Statistics.Propagations reach probeLimit.
+	probing     bool
+	probeBudget uint64
+	probeLimit  uint64
+
+	// Maximum number of ternary resolvents added at the start of each solve
+	// call (0 disables ternary resolution).
+	ternaryResolvents int
+
+	// If true, the variables of the reasons of the learnt clauses' literals
+	// are bumped along with the variables of the clauses.
+	reasonSideBumping bool
+
+	// Limits of the extra clauses learnt from the conflicts, and the clause
+	// captured from the conflict being analyzed (if any) with its LBD.
+	extraLearnts ExtraLearnts
+	tmpExtra     []Literal
+	extraLBD     int
+
+	// Whether AddClause adds the missing variables of the clauses.
+	autoAddVariables bool
+
 	// Whether the solver has reached a top level conflict or not.
 	unsat bool
 
+	// Number of clauses added with AddClause, and the index of the clause
+	// whose addition made the problem unsatisfiable (-1 if none did). The
+	// source lines of the next clause and of that clause are only known if
+	// they are set with SetClauseLine (0 otherwise).
+	addedClauses int
+	unsatClause  int
+	clauseLine   int
+	unsatLine    int
+
 	// Value assigned to each literal.
 	assigns []LBool
 
@@ -34,11 +154,22 @@ type Solver struct {
 	// Level at which each variable was assigned (-1 if unnassigned).
 	assignLevels []int
 
+	// Whether each variable is protected from simplifications (see Freeze).
+	frozen []bool
+
+	// Clauses removed by simplifications, used to extend the models found on
+	// the simplified problem to the original problem.
+	reconstruction reconstructionStack
+
 	// Clause database.
 	constraints []*Clause
 	cores       []*Clause
 	locals      []*Clause
 
+	// Occurrence lists of the problem clauses, only built while simplifying
+	// the problem (nil otherwise).
+	occurs *occurrenceLists
+
 	clauseInc   float64
 	clauseDecay float64
 
@@ -53,6 +184,10 @@ type Solver struct {
 	conflictBeforeReduceInc    uint64
 	conflictBeforeReduceIncInc uint64
 
+	// Limit on the learnt clauses of the ReduceGeometricLimit schedule, which
+	// replaces the above thresholds.
+	learntsLimit learntsLimit
+
 	// List of watcher for each literal.
 	watchers [][]watcher
 
@@ -75,13 +210,54 @@ type Solver struct {
 	hasStopCond bool
 	maxConflict int64
 	timeout     time.Duration
+	maxMemory   int64 // in bytes
+
+	// Set by Interrupt to stop the search, by RequestDiagnostics, and by
+	// ImportClause. These are the only fields that can be safely accessed
+	// from other goroutines.
+	interrupted atomic.Bool
+	diagnose    atomic.Pointer[func(Diagnostics)]
+	imports     clauseQueue
+
+	// Assumptions of the current solve call. Assumption i is decided at level
+	// i+1 before any other decision is made.
+	assumptions []Literal
+
+	// Selectors of the removable clauses, assumed by every solve call (see
+	// AddRemovableClause).
+	selectors []Literal
 
-	// Models.
+	// Subset of the assumptions responsible for the last solve call to return
+	// False (see FailedAssumptions).
+	failedAssumptions []Literal
+
+	// Status returned by the last solve call and reason why it was stopped.
+	status     LBool
+	stopReason StopReason
+
+	// Set during the operations made of several solve calls (see
+	// beginOperation) to keep the interruptions requested between two calls.
+	keepInterrupt bool
+
+	// Budgets of the current SolveBudgeted call (-1 if unlimited).
+	conflictBudget    int64
+	propagationBudget int64
+
+	// Memory accounting used by the memory stop condition (see memoryUsage).
+	memory memoryTracker
+
+	// Allocator of the learnt clauses (see Options.PoolLearnts).
+	pool clausePool
+
+	// Models found by the successive solve calls, from the oldest to the most
+	// recent. Prefer Model and Value to access the last one.
 	Models [][]bool
 
-	// Temporary slice used in the Propagate function. The slice is re-used by
-	// all Propagate calls to avoid unnecessarily allocating new slices.
-	tmpWatchers []watcher
+	// Model found by the last solve call (nil if it did not return True).
+	model []bool
+
+	// Names of the variables (see NameVariable).
+	names map[int]string
 
 	// Temporary slice used in Analyze to accumulate literals before these are
 	// used to create a new learnt clause. Having one shared buffer between all
@@ -91,6 +267,17 @@ type Solver struct {
 	// Used for clause to explain themselves.
 	tmpReason []Literal
 
+	// Used by Probe to store the negation of the failed literals.
+	tmpFailed []Literal
+
+	// Used by TernaryResolution to store the resolvent of two clauses.
+	tmpResolvent []Literal
+
+	// Used by allUIP to store the literals added to the learnt clause and the
+	// variables marked while looking for the UIP of a decision level.
+	tmpUIP    []Literal
+	tmpMarked []int
+
 	// Shared by operation that needs to put variables in a set and empty that
 	// set efficiently.
 	seenVar ResetSet
@@ -101,7 +288,46 @@ type Solver struct {
 	// time.
 	seenLevel ResetSet
 
+	// Set of literals used by NewClause to find the duplicate literals and the
+	// tautologies of problem clauses without allocating memory.
+	seenLit ResetSet
+
+	// Logger used to report the search progress.
+	logger     Logger
+	verbosity  int
 	printCount int
+
+	// Periodic reports of the search statistics (see StatsInterval).
+	statsInterval  StatsInterval
+	nextStatsPrint uint64    // conflicts of the next report
+	nextStatsTime  time.Time // time of the next report
+
+	// Writer of the DRAT proof (nil if no proof is written).
+	proof *proofWriter
+
+	// Callback called after each reduction of the clause DB (disabled if nil).
+	onReduce func()
+
+	// Callback called every yieldInterval iterations (disabled if nil).
+	onYield       func()
+	yieldInterval uint64
+
+	// Callback called with the learnt clauses whose LBD is at most
+	// learntExportLBD (disabled if nil).
+	onLearnt        func([]Literal, int)
+	learntExportLBD int
+
+	// Writer receiving the DOT graphs of the next conflictGraphsLeft
+	// conflicts (disabled if nil).
+	conflictGraphs     io.Writer
+	conflictGraphsLeft int
+
+	// Tracer of the next conflict analyses (disabled if nil).
+	tracer *conflictTracer
+
+	// Callback called every progressInterval conflicts (disabled if nil).
+	onProgress       func(Progress)
+	progressInterval uint64
 }
 
 // watcher represents a clause attached to the watch list of a literal.
@@ -114,42 +340,90 @@ type watcher struct {
 	// no need to propagate the clause. Note that the guard literal must be
 	// different from the watcher literal.
 	guard Literal
-}
-
-type Options struct {
-	ClauseDecay   float64
-	VariableDecay float64
-	MaxConflicts  int64
-	Timeout       time.Duration
-	PhaseSaving   bool
-}
 
-var DefaultOptions = Options{
-	ClauseDecay:   0.999,
-	VariableDecay: 0.95,
-	MaxConflicts:  -1,
-	Timeout:       -1,
-	PhaseSaving:   false,
+	// Tier of the clause (see Solver.setTier), which attributes the guard
+	// hits to a tier without loading the clause. It fits in the padding of
+	// the struct.
+	tier Tier
 }
 
 // NewDefaultSolver returns a solver configured with default options. This is
-// equivalent to calling NewSolver with DefaultOptions.
+// equivalent to calling NewSolver without options.
 func NewDefaultSolver() *Solver {
-	return NewSolver(DefaultOptions)
+	return newSolver(DefaultOptions)
 }
 
-func NewSolver(ops Options) *Solver {
+// NewSolver returns a solver configured with DefaultOptions modified by the
+// given options, applied in order. An error is returned if the resulting
+// configuration is invalid.
+func NewSolver(opts ...Option) (*Solver, error) {
+	ops := DefaultOptions
+	for _, opt := range opts {
+		opt(&ops)
+	}
+	if err := ops.Validate(); err != nil {
+		return nil, err
+	}
+	return newSolver(ops), nil
+}
+
+func newSolver(ops Options) *Solver {
 	s := &Solver{
-		clauseDecay:                ops.ClauseDecay,
-		clauseInc:                  1,
-		order:                      NewVarOrder(ops.VariableDecay, ops.PhaseSaving),
-		maxConflict:                -1,
-		timeout:                    -1,
-		conflictBeforeReduce:       20000,
-		conflictBeforeReduceInc:    20000,
-		conflictBeforeReduceIncInc: 0,
-		tmpLearnts:                 make([]Literal, 0, 32),
-		tmpReason:                  make([]Literal, 0, 32),
+		clauseDecay:         ops.ClauseDecay,
+		clauseInc:           1,
+		order:               NewVarOrder(ops.VariableDecay, ops.PhaseSaving),
+		rng:                 rand.New(rand.NewSource(ops.Seed)),
+		randomDecisionFreq:  ops.RandomDecisionFreq,
+		restarts:            ops.Restarts,
+		reductions:          ops.Reductions,
+		averages:            ops.Averages,
+		learning:            ops.Learning,
+		extraLearnts:        ops.ExtraLearnts,
+		reasonSideBumping:   ops.ReasonSideBumping,
+		probing:             ops.Probing,
+		probeBudget:         defaultProbeBudget,
+		ternaryResolvents:   ops.TernaryResolvents,
+		binaryStrengthenLBD: ops.BinaryStrengthenLBD,
+		autoAddVariables:    ops.AutoAddVariables,
+		unsatClause:         -1,
+		maxConflict:         -1,
+		timeout:             -1,
+		maxMemory:           -1,
+		conflictBudget:      -1,
+		propagationBudget:   -1,
+		tmpLearnts:          make([]Literal, 0, 32),
+		tmpReason:           make([]Literal, 0, 32),
+		logger:              ops.Logger,
+		verbosity:           ops.Verbosity,
+		statsInterval:       ops.StatsInterval,
+		progressInterval:    ops.ProgressInterval,
+		onReduce:            ops.OnReduce,
+		yieldInterval:       ops.YieldInterval,
+		onLearnt:            ops.OnLearnt,
+		learntExportLBD:     ops.LearntExportLBD,
+		conflictGraphs:      ops.ConflictGraphs,
+		conflictGraphsLeft:  ops.MaxConflictGraphs,
+		pool:                clausePool{enabled: ops.PoolLearnts},
+	}
+
+	s.order.defaultPhase = Lift(ops.DefaultPolarity)
+	s.initReductions()
+	if ops.ProgressInterval > 0 {
+		s.onProgress = ops.OnProgress
+	}
+	if ops.YieldInterval > 0 {
+		s.onYield = ops.OnYield
+	}
+	if ops.ConflictTrace != nil && ops.MaxTracedConflicts > 0 {
+		s.tracer = newConflictTracer(ops.ConflictTrace, ops.MaxTracedConflicts)
+	}
+	if ops.Proof != nil {
+		s.proof = newProofWriter(ops.Proof, ops.ProofFormat)
+	}
+
+	if s.logger == nil || s.verbosity <= 0 {
+		s.logger = nopLogger{}
+		s.verbosity = 0
 	}
 
 	if ops.MaxConflicts >= 0 {
@@ -160,22 +434,87 @@ func NewSolver(ops Options) *Solver {
 		s.hasStopCond = true
 		s.timeout = ops.Timeout
 	}
+	if ops.MaxMemoryMB >= 0 {
+		s.hasStopCond = true
+		s.maxMemory = ops.MaxMemoryMB << 20
+	}
 
 	return s
 }
 
+// Interrupt requests the solver to stop its search as soon as possible, in which
+// case Solve returns Unknown. It is safe to call Interrupt from any goroutine.
+// Each solve call starts uninterrupted: interruptions requested while the
+// solver is not solving are discarded. EnumerateModels, DiverseModels and
+// MinimizeModel are interrupted as a whole, even if the interruption is
+// requested between two of their solve calls.
+func (s *Solver) Interrupt() {
+	s.interrupted.Store(true)
+}
+
+// beginOperation starts an operation made of several solve calls, which stops
+// at the first solve call started after an interruption. The returned function
+// ends the operation.
+func (s *Solver) beginOperation() (end func()) {
+	s.interrupted.Store(false)
+	s.keepInterrupt = true
+	return func() { s.keepInterrupt = false }
+}
+
 func (s *Solver) shouldStop() bool {
+	s.stopReason = s.checkStop()
+	return s.stopReason != NotStopped
+}
+
+// checkStop returns the reason why the search must be stopped, if any.
+func (s *Solver) checkStop() StopReason {
+	if s.interrupted.Load() {
+		return StoppedByInterrupt
+	}
 	if !s.hasStopCond {
-		return false
+		return NotStopped
 	}
 	if s.maxConflict >= 0 && uint64(s.maxConflict) <= s.Statistics.Conflicts {
-		return true
+		return StoppedByMaxConflicts
 	}
 	if s.timeout >= 0 && s.timeout <= time.Since(s.startTime) {
-		return true
+		return StoppedByTimeout
+	}
+	if s.maxMemory >= 0 && s.maxMemory <= s.memory.usage(s.Statistics.Iterations) {
+		return StoppedByMemory
+	}
+	if s.conflictBudget >= 0 && uint64(s.conflictBudget) <= s.Statistics.Conflicts {
+		return StoppedByConflictBudget
+	}
+	if s.propagationBudget >= 0 && uint64(s.propagationBudget) <= s.Statistics.Propagations {
+		return StoppedByPropagationBudget
 	}
 
-	return false
+	return NotStopped
+}
+
+// StopReason returns the reason why the last solve call returned Unknown, or
+// NotStopped if it decided the problem.
+func (s *Solver) StopReason() StopReason {
+	return s.stopReason
+}
+
+// Model returns the model found by the last solve call, indexed by variable,
+// or nil if that call did not return True. The model remains valid after the
+// solver backtracks and until the next solve call or the next addition of a
+// clause; it must not be modified.
+func (s *Solver) Model() []bool {
+	return s.model
+}
+
+// Value returns the value of variable v in the model found by the last solve
+// call, or Unknown if there is no such model or if v was added after it was
+// found.
+func (s *Solver) Value(v int) LBool {
+	if v < 0 || v >= len(s.model) {
+		return Unknown
+	}
+	return Lift(s.model[v])
 }
 
 func (s *Solver) NumVariables() int {
@@ -194,6 +533,34 @@ func (s *Solver) NumLearnts() int {
 	return len(s.locals)
 }
 
+// ProblemClauses calls yield on each problem clause of the clause DB until
+// yield returns false. Unit clauses are not stored in the DB: they are
+// root-level assignments (see VarValue). The literals passed to yield are
+// owned by the solver and must not be modified nor retained.
+func (s *Solver) ProblemClauses(yield func([]Literal) bool) {
+	iterateClauses(s.constraints, yield)
+}
+
+// LearntClauses calls yield on each learnt clause of the clause DB, core
+// clauses first, until yield returns false. The literals passed to yield are
+// owned by the solver and must not be modified nor retained.
+func (s *Solver) LearntClauses(yield func([]Literal) bool) {
+	if iterateClauses(s.cores, yield) {
+		iterateClauses(s.locals, yield)
+	}
+}
+
+// iterateClauses calls yield on each clause until it returns false, in which
+// case iterateClauses returns false.
+func iterateClauses(clauses []*Clause, yield func([]Literal) bool) bool {
+	for _, c := range clauses {
+		if !yield(c.literals) {
+			return false
+		}
+	}
+	return true
+}
+
 func (s *Solver) VarValue(x int) LBool {
 	return s.assigns[PositiveLiteral(x)]
 }
@@ -202,27 +569,72 @@ func (s *Solver) LitValue(l Literal) LBool {
 	return s.assigns[l]
 }
 
+// AddVariable adds a variable to the solver and returns its index.
 func (s *Solver) AddVariable() int {
+	return s.AddVariables(1)
+}
+
+// AddVariables adds n variables to the solver and returns the index of the
+// first one. The structures indexed by variables are grown once for all of
+// them, which is faster than calling AddVariable n times.
+func (s *Solver) AddVariables(n int) int {
 	index := s.NumVariables()
-	s.watchers = append(s.watchers, nil)
-	s.watchers = append(s.watchers, nil)
+	s.watchers = extend(s.watchers, 2*n, nil)
 
-	s.seenVar.Expand()
-	s.seenLevel.Expand()
+	s.seenVar.Expand(n)
+	s.seenLevel.Expand(n)
+	s.seenLit.Expand(2 * n)
 
-	s.assignReasons = append(s.assignReasons, nil)
-	s.assignLevels = append(s.assignLevels, -1)
-	s.assigns = append(s.assigns, Unknown, Unknown) // one for each literal
+	s.assignReasons = extend(s.assignReasons, n, nil)
+	s.assignLevels = extend(s.assignLevels, n, -1)
+	s.frozen = extend(s.frozen, n, false)
+	s.assigns = extend(s.assigns, 2*n, Unknown) // one for each literal
 
-	s.order.AddVar(0.0, true)
+	s.order.AddVars(n, 0.0, s.order.defaultPhase == True)
 	return index
 }
 
+// extend returns the slice extended with n copies of v.
+func extend[T any](x []T, n int, v T) []T {
+	size := len(x)
+	x = slices.Grow(x, n)[:size+n]
+	for i := size; i < len(x); i++ {
+		x[i] = v
+	}
+	return x
+}
+
+// Reserve pre-allocates the solver's internal structures for nVars additional
+// variables and nClauses additional problem clauses. This avoids repeatedly
+// growing these structures when the size of the problem is known upfront. It
+// does not add variables nor clauses to the solver.
+func (s *Solver) Reserve(nVars int, nClauses int) {
+	s.ReserveVariables(nVars)
+	s.constraints = slices.Grow(s.constraints, nClauses)
+}
+
+// ReserveVariables pre-allocates the structures indexed by variables (e.g.
+// assignments, watchers, and scores) for n additional variables so that
+// adding them with AddVariable does not repeatedly grow these structures. It
+// does not add variables to the solver.
+func (s *Solver) ReserveVariables(n int) {
+	s.watchers = slices.Grow(s.watchers, 2*n)
+	s.assigns = slices.Grow(s.assigns, 2*n)
+	s.assignReasons = slices.Grow(s.assignReasons, n)
+	s.assignLevels = slices.Grow(s.assignLevels, n)
+	s.frozen = slices.Grow(s.frozen, n)
+	s.seenVar.reserve(n)
+	s.seenLevel.reserve(n)
+	s.seenLit.reserve(2 * n)
+	s.order.reserve(n)
+}
+
 // Watch registers clause c to be awaken when Literal watch is assigned to true.
 func (s *Solver) Watch(c *Clause, watch Literal, guard Literal) {
 	s.watchers[watch] = append(s.watchers[watch], watcher{
 		clause: c,
 		guard:  guard,
+		tier:   c.tier,
 	})
 }
 
@@ -238,27 +650,93 @@ func (s *Solver) Unwatch(c *Clause, watch Literal) {
 	s.watchers[watch] = s.watchers[watch][:j]
 }
 
+// AddClause adds a problem clause to the solver. Clauses can be added before
+// the first solve call as well as between solve calls (e.g. to block the last
+// model), but not during a search. The clause is simplified with the
+// root-level assignments; unit clauses are propagated right away and adding
+// an empty or falsified clause makes the problem unsatisfiable. The clause's
+// slice may be modified but is not retained.
+//
+// Adding a clause invalidates the model of the last solve call (see Model),
+// which might not satisfy it. Learnt clauses remain valid as they are implied
+// by the problem clauses.
 func (s *Solver) AddClause(clause []Literal) error {
 	if s.decisionLevel() != 0 {
 		return fmt.Errorf("can only add clauses at the root level")
 	}
+	for _, l := range clause {
+		if v := l.VarID(); v >= s.NumVariables() {
+			if !s.autoAddVariables {
+				return fmt.Errorf("unknown variable %d: the solver has %d variables", v, s.NumVariables())
+			}
+			s.AddVariables(v + 1 - s.NumVariables())
+		}
+	}
+	s.model = nil
+	s.addedClauses++
+
 	c, ok := NewClause(s, clause, false)
 	if c != nil {
 		s.constraints = append(s.constraints, c)
+		if s.occurs != nil {
+			s.occurs.add(c)
+		}
 	}
-	if !ok {
+	if !ok || (c == nil && s.propagated < len(s.trail) && s.Propagate() != nil) {
+		if !s.unsat {
+			s.unsatClause = s.addedClauses - 1
+			s.unsatLine = s.clauseLine
+		}
 		s.unsat = true
 	}
 
 	return nil
 }
 
+// UnsatClause returns the index of the clause whose addition made the problem
+// unsatisfiable at the root level, that is before any search, and true if
+// there is such a clause. Clauses are indexed from 0 in the order of the
+// AddClause calls that succeeded, which is the order of the clauses in the
+// file when the problem is loaded from DIMACS.
+func (s *Solver) UnsatClause() (int, bool) {
+	return s.unsatClause, s.unsatClause >= 0
+}
+
+// SetClauseLine sets the line of the source file on which the clauses added
+// next are written, as reported by UnsatClauseLine. Parsers call it before
+// adding each clause.
+func (s *Solver) SetClauseLine(line int) {
+	s.clauseLine = line
+}
+
+// UnsatClauseLine returns the source line of the clause reported by
+// UnsatClause, and true if there is such a clause and its line was set with
+// SetClauseLine.
+func (s *Solver) UnsatClauseLine() (int, bool) {
+	return s.unsatLine, s.unsatClause >= 0 && s.unsatLine > 0
+}
+
+// AddClauses adds the clauses to the solver, pre-allocating the clause DB for
+// all of them at once. As with AddClause, the slices of literals may be
+// modified but are not retained. It stops at the first error.
+func (s *Solver) AddClauses(clauses [][]Literal) error {
+	s.constraints = slices.Grow(s.constraints, len(clauses))
+	for _, c := range clauses {
+		if err := s.AddClause(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Simplify simplifies the clause DB as well as the problem clauses according
 // to the root-level assignments. Clauses that are satisfied at the root-level
-// are removed.
+// are removed. Simplify returns false if the problem is unsatisfiable. It does
+// nothing if called above the root level, which cannot happen between solve
+// calls.
 func (s *Solver) Simplify() bool {
-	if l := s.decisionLevel(); l != 0 {
-		log.Fatalf("Simplify called on non root-level: %d", l)
+	if s.decisionLevel() != 0 {
+		return !s.unsat
 	}
 
 	if s.unsat || s.Propagate() != nil {
@@ -292,30 +770,108 @@ func (s *Solver) decisionLevel() int {
 	return len(s.trailLevels)
 }
 
-func (s *Solver) Solve() LBool {
-	numConflicts := uint64(100)
+// Solve decides the satisfiability of the problem and returns the result of
+// the search. Its status is Unknown if the search was stopped before deciding
+// the problem, in which case its stop reason tells why.
+func (s *Solver) Solve() Result {
+	s.solve(nil, -1, -1)
+	return s.result()
+}
+
+// SolveBudgeted is equivalent to Solve except that the search is stopped, with
+// an Unknown status, once it has reached the given number of conflicts or the
+// given number of propagations. A negative budget means that the corresponding
+// resource is unlimited. Budgets only apply to the current call and come on
+// top of the stop conditions configured in the solver's options.
+func (s *Solver) SolveBudgeted(conflicts, propagations int64) Result {
+	s.solve(nil, conflicts, propagations)
+	return s.result()
+}
+
+// SolveWithAssumptions is equivalent to Solve except that the given literals
+// are assumed to be true. If the status is False, the problem is unsatisfiable
+// under these assumptions and the result's failed assumptions are the subset of
+// them that caused unsatisfiability. Assumptions do not persist after the call.
+func (s *Solver) SolveWithAssumptions(assumptions []Literal) Result {
+	s.solve(assumptions, -1, -1)
+	return s.result()
+}
+
+// FailedAssumptions returns the subset of the assumptions that caused the last
+// call to SolveWithAssumptions to return False. The subset is empty if the
+// problem is unsatisfiable regardless of the assumptions.
+func (s *Solver) FailedAssumptions() []Literal {
+	return s.failedAssumptions
+}
+
+// ProofError returns the first error that occurred while writing the proof, if
+// any. The proof is flushed at the end of each solve call.
+func (s *Solver) ProofError() error {
+	if s.proof == nil {
+		return nil
+	}
+	return s.proof.err
+}
+
+func (s *Solver) solve(assumptions []Literal, conflicts, propagations int64) LBool {
+	restarts := newRestartSchedule(s.restarts)
 	status := Unknown
 
-	s.startTime = time.Now()
-	s.Statistics = Statistics{
-		AvgConflictLevel: NewEMA(0.9999),
+	if len(s.selectors) > 0 {
+		assumptions = append(slices.Clone(s.selectors), assumptions...)
+	}
+	s.assumptions = assumptions
+	s.failedAssumptions = s.failedAssumptions[:0]
+	s.model = nil
+	if !s.keepInterrupt {
+		s.interrupted.Store(false)
 	}
+	s.conflictBudget = conflicts
+	s.propagationBudget = propagations
+	s.hasStopCond = s.maxConflict >= 0 ||
+		s.timeout >= 0 ||
+		s.maxMemory >= 0 ||
+		conflicts >= 0 ||
+		propagations >= 0
 
-	fmt.Printf("c variables: %d\n", s.NumVariables())
-	fmt.Printf("c clauses:   %d\n", s.NumConstraints())
+	s.dropOccurrences() // not maintained during the search
+	s.resetReductions()
+
+	s.startTime = time.Now()
+	s.resetStatistics()
+	s.memory.sampled = false // iterations are reset with the statistics
+
+	s.logger.Printf("c variables: %d\n", s.NumVariables())
+	s.logger.Printf("c clauses:   %d\n", s.NumConstraints())
+	s.printSearchStats(' ')
+	s.scheduleStats()
+
+	s.inprocess()
 
 	for status == Unknown {
-		status = s.Search(numConflicts)
-		numConflicts += 1000
+		status = s.Search(restarts.next())
 
 		if s.shouldStop() {
 			break
 		}
 	}
 
+	if status != Unknown {
+		s.stopReason = NotStopped
+	}
+
 	s.printSearchStats(' ')
 
+	if s.proof != nil {
+		if s.unsat {
+			s.proof.empty()
+		}
+		s.proof.flush()
+	}
+
 	s.backtrackTo(0)
+	s.assumptions = nil
+	s.status = status
 	return status
 }
 
@@ -341,17 +897,18 @@ func (s *Solver) rescaleClauseActivitiesAndIncrement() {
 }
 
 func (s *Solver) Propagate() *Clause {
+	tiers := &s.Statistics.Tiers
 	for s.propagated < len(s.trail) {
 		l := s.trail[s.propagated]
 		s.propagated++
 
-		s.tmpWatchers = s.tmpWatchers[:0]
-		s.tmpWatchers = append(s.tmpWatchers, s.watchers[l]...)
-		s.watchers[l] = s.watchers[l][:0]
-
-		for i, w := range s.tmpWatchers {
-			s.Statistics.Propagations++
-
+		// The watch list is traversed and compacted in place: ws[:j] contains
+		// the watchers kept so far, which never overtakes the watcher being
+		// propagated as clauses moving their watch never move it to l.
+		ws := s.watchers[l]
+		j := 0
+		guards := 0
+		for i, w := range ws {
 			// No need to propagate the clause if its guard is true. This block
 			// is not necessary for propagation to behave properly. However, it
 			// helps to significantly speed-up computation by avoiding loading
@@ -359,20 +916,32 @@ func (s *Solver) Propagate() *Clause {
 			// this alters the order in which clause are propagated and can thus
 			// yield to different conflict analysis and learnt clauses.
 			if s.LitValue(w.guard) == True {
-				s.Statistics.Guards++
-				s.watchers[l] = append(s.watchers[l], w)
+				tiers[w.tier].Guards++
+				guards++
+				ws[j] = w
+				j++
 				continue
 			}
 
-			if w.clause.Propagate(s, l) {
-				continue
+			tiers[w.tier].Visits++
+			keep, ok := w.clause.Propagate(s, l)
+			if keep {
+				ws[j] = watcher{clause: w.clause, guard: w.clause.literals[0], tier: w.tier}
+				j++
+			}
+			if !ok {
+				// Constraint is conflicting, keep the remaining watchers
+				// and return the constraint.
+				s.Statistics.Propagations += uint64(i + 1)
+				s.Statistics.Guards += uint64(guards)
+				j += copy(ws[j:], ws[i+1:])
+				s.watchers[l] = ws[:j]
+				return w.clause
 			}
-
-			// Constraint is conflicting, copy remaining watchers
-			// and return the constraint.
-			s.watchers[l] = append(s.watchers[l], s.tmpWatchers[i+1:]...)
-			return w.clause
 		}
+		s.Statistics.Propagations += uint64(len(ws))
+		s.Statistics.Guards += uint64(guards)
+		s.watchers[l] = ws[:j]
 	}
 
 	return nil
@@ -392,6 +961,10 @@ func (s *Solver) enqueue(l Literal, from *Clause) bool {
 		s.assignLevels[varID] = s.decisionLevel()
 		s.assignReasons[varID] = from
 		s.trail = append(s.trail, l)
+		if from != nil {
+			s.Statistics.Tiers[from.tier].Implied++
+			from.used = true
+		}
 		return true
 	}
 }
@@ -419,14 +992,21 @@ func (s *Solver) analyze(conflicting *Clause) ([]Literal, int, int) {
 	s.seenVar.Clear()
 	backtrackLevel := 0
 
+	s.tmpExtra = s.tmpExtra[:0]
+	captureExtra := s.extraLearnts.MaxSize > 0 || s.learning == LearnDIP
+
 	for {
 		if c == conflicting {
 			c.explainConflict(&s.tmpReason)
 		} else {
 			c.explainAssign(&s.tmpReason)
+			if s.tracer != nil {
+				s.tracer.resolve(c)
+			}
 		}
 		if c.isLearnt() {
 			s.BumpClaActivity(c)
+			c.used = true
 		}
 
 		for _, q := range s.tmpReason {
@@ -460,6 +1040,10 @@ func (s *Solver) analyze(conflicting *Clause) ([]Literal, int, int) {
 			c.lbd = newLBD
 		}
 
+		if captureExtra && nImplicationPoints == 2 && c != conflicting {
+			captureExtra = !s.captureExtraLearnt(trailTop)
+		}
+
 		// Select next literal to look at.
 		for {
 			trailTop--
@@ -470,18 +1054,72 @@ func (s *Solver) analyze(conflicting *Clause) ([]Literal, int, int) {
 			}
 		}
 
+		// The last UIP is the decision of the level, which is the only literal
+		// of the level without a reason.
 		nImplicationPoints--
-		if nImplicationPoints <= 0 {
+		if nImplicationPoints <= 0 && (s.learning != LearnLastUIP || c == nil) {
 			break
 		}
 	}
 
 	s.tmpLearnts[0] = s.trail[trailTop].Opposite()
+	switch s.learning {
+	case LearnAllUIP:
+		s.tmpLearnts = s.allUIP(s.tmpLearnts)
+	case LearnDIP:
+		s.checkDIP(s.tmpLearnts)
+	}
 	lbd := s.computeLBD(s.tmpLearnts)
 
+	// Strengthening may remove all the literals of some levels, including the
+	// highest of the lower levels.
+	if lbd <= s.binaryStrengthenLBD {
+		n := len(s.tmpLearnts)
+		s.tmpLearnts = s.strengthenBinary(s.tmpLearnts)
+		if len(s.tmpLearnts) < n {
+			lbd = s.computeLBD(s.tmpLearnts)
+			backtrackLevel = 0
+			for _, l := range s.tmpLearnts[1:] {
+				backtrackLevel = max(backtrackLevel, s.assignLevels[l.VarID()])
+			}
+		}
+	}
+
 	return s.tmpLearnts, lbd, backtrackLevel
 }
 
+// analyzeFinal computes the subset of the assumptions that imply the negation
+// of assumption a, which is currently false, and stores them (including a) in
+// failedAssumptions.
+func (s *Solver) analyzeFinal(a Literal) {
+	s.failedAssumptions = append(s.failedAssumptions[:0], a)
+	if s.assignLevels[a.VarID()] == 0 {
+		return
+	}
+
+	s.seenVar.Clear()
+	s.seenVar.Add(a.VarID())
+
+	for i := len(s.trail) - 1; i >= s.trailLevels[0]; i-- {
+		l := s.trail[i]
+		v := l.VarID()
+		if !s.seenVar.Contains(v) {
+			continue
+		}
+		c := s.assignReasons[v]
+		if c == nil { // decisions are assumptions
+			s.failedAssumptions = append(s.failedAssumptions, l)
+			continue
+		}
+		c.explainAssign(&s.tmpReason)
+		for _, q := range s.tmpReason {
+			if s.assignLevels[q.VarID()] > 0 {
+				s.seenVar.Add(q.VarID())
+			}
+		}
+	}
+}
+
 // computeLBD returns the LBD (Literal Block Distance) of the given sequence of
 // literals. All literals in the sequence must be assigned.
 func (s *Solver) computeLBD(literals []Literal) int {
@@ -499,18 +1137,60 @@ func (s *Solver) computeLBD(literals []Literal) int {
 }
 
 func (s *Solver) record(clause []Literal, lbd int) {
-	c, _ := NewClause(s, clause, true)
+	c := s.addLearnt(clause, lbd)
 	s.enqueue(clause[0], c)
 
 	if c != nil {
-		s.BumpClaActivity(c)
 		for _, l := range c.literals {
 			s.order.BumpScore(l.VarID())
 		}
+		if s.reasonSideBumping {
+			s.bumpReasonSide(c.literals)
+		}
+	}
+}
+
+// bumpReasonSide bumps the score of the variables that appear in the reasons
+// of the false literals of the learnt clause, but not in the clause itself.
+// Root-level variables are ignored and the other variables are only bumped
+// once, even if they appear in several reasons.
+func (s *Solver) bumpReasonSide(learnt []Literal) {
+	s.seenVar.Clear()
+	for _, l := range learnt {
+		s.seenVar.Add(l.VarID())
+	}
+	for _, l := range learnt[1:] {
+		// Reasons of root-level literals may have been deleted by Simplify.
+		reason := s.assignReasons[l.VarID()]
+		if reason == nil || s.assignLevels[l.VarID()] == 0 {
+			continue
+		}
+		for _, q := range reason.literals[1:] {
+			if v := q.VarID(); !s.seenVar.Contains(v) && s.assignLevels[v] > 0 {
+				s.seenVar.Add(v)
+				s.order.BumpScore(v)
+			}
+		}
+	}
+}
+
+// addLearnt adds the learnt clause to the clause DB. It returns nil if the
+// clause is unit, in which case its literal is enqueued.
+func (s *Solver) addLearnt(clause []Literal, lbd int) *Clause {
+	if s.proof != nil {
+		s.proof.add(clause)
+	}
+	if s.onLearnt != nil && lbd <= s.learntExportLBD {
+		s.onLearnt(clause, lbd)
+	}
 
+	c, _ := NewClause(s, clause, true)
+	if c != nil {
+		s.BumpClaActivity(c)
 		s.locals = append(s.locals, c)
 		c.lbd = uint32(lbd)
 	}
+	return c
 }
 
 func (s *Solver) Search(nConflicts uint64) LBool {
@@ -520,30 +1200,63 @@ func (s *Solver) Search(nConflicts uint64) LBool {
 		return False
 	}
 
-	conflictLimit := s.Statistics.Conflicts + nConflicts
+	conflictLimit := uint64(math.MaxUint64)
+	if nConflicts < conflictLimit-s.Statistics.Conflicts {
+		conflictLimit = s.Statistics.Conflicts + nConflicts
+	}
+	glucose := s.restarts.Policy == RestartGlucose
+	restart := false
+	searchStart := s.Statistics.Conflicts // start of the glucose restart window
 
 	for !s.shouldStop() {
-		if s.Statistics.Iterations%100000 == 0 {
-			s.printSearchStats(' ')
-		}
 		s.Statistics.Iterations++
+		s.serveDiagnostics()
+		if s.onYield != nil && s.Statistics.Iterations%s.yieldInterval == 0 {
+			s.onYield()
+		}
 
 		if conflict := s.Propagate(); conflict != nil {
 			s.Statistics.Conflicts++
+			if glucose && s.blockGlucoseRestart() {
+				searchStart = s.Statistics.Conflicts
+			}
 			s.Statistics.AvgConflictLevel.Add(float64(s.decisionLevel()))
+			s.Statistics.AvgTrail.Add(float64(len(s.trail)))
 
 			if s.decisionLevel() == 0 {
 				s.unsat = true
 				return False
 			}
 
+			if s.conflictGraphs != nil && s.conflictGraphsLeft > 0 {
+				s.writeConflictGraph(conflict)
+			}
+
+			if s.tracer != nil {
+				s.tracer.begin(s, conflict)
+			}
 			learntClause, lbd, backtrackLevel := s.analyze(conflict)
+			if s.tracer != nil && !s.tracer.end(learntClause, lbd, backtrackLevel) {
+				s.tracer = nil
+			}
 			s.backtrackTo(backtrackLevel)
 
 			s.record(learntClause, lbd)
+			s.recordExtra()
+			s.Statistics.AvgLearntLBD.Add(float64(lbd))
+			s.Statistics.AvgFastLBD.Add(float64(lbd))
+			restart = glucose && s.glucoseRestart(s.Statistics.Conflicts-searchStart)
+
+			if s.onProgress != nil && s.Statistics.Conflicts%s.progressInterval == 0 {
+				s.onProgress(s.progress())
+			}
+			if s.verbosity > 0 {
+				s.printPeriodicStats()
+			}
 
 			s.DecayClaActivity()
 			s.order.DecayScores()
+			s.onConflictReductions()
 
 			continue
 		}
@@ -552,14 +1265,33 @@ func (s *Solver) Search(nConflicts uint64) LBool {
 		// -----------
 
 		if s.decisionLevel() == 0 {
+			if s.imports.pending() && !s.importClauses() {
+				return False
+			}
 			s.Simplify()
 		}
 
-		if s.Statistics.Conflicts >= s.conflictBeforeReduce {
-			s.conflictBeforeReduceInc += s.conflictBeforeReduceIncInc
-			s.conflictBeforeReduce += s.conflictBeforeReduceInc
+		if s.shouldReduce() {
 			s.ReduceDB()
 			s.printSearchStats('C')
+			if s.onReduce != nil {
+				s.onReduce()
+			}
+		}
+
+		// Assumptions are decided first, in order, each on its own level.
+		if level := s.decisionLevel(); level < len(s.assumptions) {
+			switch a := s.assumptions[level]; s.LitValue(a) {
+			case True:
+				s.newDecisionLevel() // keep one level per assumption
+			case False:
+				s.analyzeFinal(a)
+				s.backtrackTo(0)
+				return False
+			default:
+				s.assume(a)
+			}
+			continue
 		}
 
 		if s.NumAssigns() == s.NumVariables() { // solution found
@@ -568,13 +1300,19 @@ func (s *Solver) Search(nConflicts uint64) LBool {
 			return True
 		}
 
-		if s.Statistics.Conflicts > conflictLimit {
+		if s.Statistics.Conflicts > conflictLimit || restart {
 			s.backtrackTo(0)
 			s.printSearchStats('R')
 			return Unknown
 		}
 
-		l := s.order.NextDecision(s)
+		l, ok := s.order.NextDecision(s)
+		if !ok { // all the variables are assigned
+			s.saveModel()
+			s.backtrackTo(0)
+			return True
+		}
+		s.Statistics.Decisions++
 		s.assume(l)
 	}
 
@@ -582,10 +1320,15 @@ func (s *Solver) Search(nConflicts uint64) LBool {
 }
 
 func (s *Solver) ReduceDB() {
-	// Collect core clauses.
+	s.demoteCores()
+
+	// Collect core clauses. Demoted clauses (with a non-zero idle count) are
+	// only promoted again once they are used.
 	k := 0
 	for _, c := range s.locals {
-		if c.lbd <= 5 {
+		if c.lbd <= s.reductions.CoreLBD && (c.idle == 0 || c.used) {
+			c.idle = 0
+			s.setTier(c, TierCore)
 			s.cores = append(s.cores, c)
 			s.Statistics.TotalCoreLBD += uint64(c.lbd)
 		} else {
@@ -596,11 +1339,9 @@ func (s *Solver) ReduceDB() {
 	s.locals = s.locals[:k]
 
 	// Sort learnt clauses from "the worst" to "the best".
-	sort.Slice(s.locals, func(i, j int) bool {
-		return s.locals[i].activity < s.locals[j].activity
-	})
+	sortForReduction(s.locals, s.reductions.Policy)
 
-	toDelete := len(s.locals) / 2
+	toDelete := int(float64(len(s.locals)) * s.reductions.Fraction)
 
 	i, j := 0, 0
 	for ; i < len(s.locals); i++ {
@@ -608,7 +1349,9 @@ func (s *Solver) ReduceDB() {
 
 		if toDelete > 0 && !c.locked(s) && c.lbd > 2 && len(c.literals) > 2 && !c.isProtected() {
 			toDelete--
+			lits := c.literals
 			c.Delete(s)
+			s.pool.put(c, lits)
 		} else {
 			if c.isProtected() {
 				c.setUnprotected()
@@ -647,20 +1390,57 @@ func (s *Solver) unnassignedLast() {
 }
 
 func (s *Solver) assume(l Literal) bool {
-	s.trailLevels = append(s.trailLevels, len(s.trail))
+	s.newDecisionLevel()
 	return s.enqueue(l, nil)
 }
 
+func (s *Solver) newDecisionLevel() {
+	s.trailLevels = append(s.trailLevels, len(s.trail))
+}
+
+// saveModel saves the current assignment as a model. It must only be called
+// when all the variables are assigned.
 func (s *Solver) saveModel() {
 	model := make([]bool, s.NumVariables())
 	for i := range model {
-		lb := s.VarValue(i)
-		if lb == Unknown {
-			panic("not a model")
-		}
-		model[i] = lb == True
+		model[i] = s.VarValue(i) == True
 	}
+	s.reconstruction.extend(model)
 	s.Models = append(s.Models, model)
+	s.model = model
+}
+
+// scheduleStats schedules the next periodic report of the search statistics.
+func (s *Solver) scheduleStats() {
+	if n := s.statsInterval.Conflicts; n > 0 {
+		s.nextStatsPrint = s.Statistics.Conflicts + n
+	}
+	if p := s.statsInterval.Period; p > 0 {
+		s.nextStatsTime = time.Now().Add(p)
+	}
+}
+
+// printPeriodicStats reports the search statistics if the next periodic report
+// is due.
+func (s *Solver) printPeriodicStats() {
+	due := s.statsInterval.Conflicts > 0 && s.Statistics.Conflicts >= s.nextStatsPrint
+	if !due && s.statsInterval.Period > 0 {
+		due = !time.Now().Before(s.nextStatsTime)
+	}
+	if due {
+		s.printSearchStats(' ')
+		s.scheduleStats()
+	}
+}
+
+// resetStatistics resets the statistics.
+func (s *Solver) resetStatistics() {
+	s.Statistics = Statistics{
+		AvgConflictLevel: NewEMA(s.averages.ConflictLevel),
+		AvgLearntLBD:     NewEMA(s.averages.SlowLBD),
+		AvgFastLBD:       NewEMA(s.averages.FastLBD),
+		AvgTrail:         NewEMA(s.averages.Trail),
+	}
 }
 
 const statsHeader = `c
@@ -668,13 +1448,18 @@ c -------------------------------------------------------------------
 c         time  #conflict     #local      #core   core-lbd     clevel
 c -------------------------------------------------------------------`
 
+// printSearchStats reports the search statistics along with the event that
+// triggered the report (' ' for periodic reports).
 func (s *Solver) printSearchStats(event byte) {
+	if s.verbosity == 0 {
+		return
+	}
 	if s.printCount%20 == 0 {
-		fmt.Println(statsHeader)
+		s.logger.Printf("%s\n", statsHeader)
 	}
 
 	s.printCount++
-	fmt.Printf(
+	s.logger.Printf(
 		"c %s %9.2fs %10d %10d %10d %10.2f %9.2f%%\n",
 		string(event),
 		time.Since(s.startTime).Seconds(),
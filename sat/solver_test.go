@@ -0,0 +1,1969 @@
+package sat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"unsafe"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// newChainSolver returns a solver with n variables and clauses x[i] => x[i+1].
+func newChainSolver(n int) *Solver {
+	s := NewDefaultSolver()
+	for i := 0; i < n; i++ {
+		s.AddVariable()
+	}
+	for i := 0; i+1 < n; i++ {
+		s.AddClause([]Literal{NegativeLiteral(i), PositiveLiteral(i + 1)})
+	}
+	return s
+}
+
+func TestSolveWithAssumptions(t *testing.T) {
+	s := newChainSolver(4)
+
+	if got := s.SolveWithAssumptions([]Literal{PositiveLiteral(0)}).Status; got != True {
+		t.Errorf("SolveWithAssumptions(x0): want true, got %s", got)
+	}
+
+	assumptions := []Literal{PositiveLiteral(1), NegativeLiteral(2), PositiveLiteral(0), NegativeLiteral(3)}
+	if got := s.SolveWithAssumptions(assumptions).Status; got != False {
+		t.Errorf("SolveWithAssumptions(x1, !x2, x0, !x3): want false, got %s", got)
+	}
+	want := []Literal{PositiveLiteral(1), NegativeLiteral(2)}
+	got := slices.Clone(s.FailedAssumptions())
+	slices.Sort(got)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("FailedAssumptions(): mismatch (+want, -got):\n%s", diff)
+	}
+
+	if got := s.Solve().Status; got != True {
+		t.Errorf("Solve(): want true, got %s", got)
+	}
+}
+
+// addPigeonhole adds to the solver the clauses stating that n+1 pigeons fit in
+// n holes, which is unsatisfiable but requires many conflicts to prove.
+func addPigeonhole(s *Solver, n int) {
+	v := func(p, h int) int { return p*n + h }
+	for i := 0; i < (n+1)*n; i++ {
+		s.AddVariable()
+	}
+	for p := 0; p <= n; p++ {
+		clause := []Literal{}
+		for h := 0; h < n; h++ {
+			clause = append(clause, PositiveLiteral(v(p, h)))
+		}
+		s.AddClause(clause)
+	}
+	for h := 0; h < n; h++ {
+		for p := 0; p <= n; p++ {
+			for q := p + 1; q <= n; q++ {
+				s.AddClause([]Literal{NegativeLiteral(v(p, h)), NegativeLiteral(v(q, h))})
+			}
+		}
+	}
+}
+
+func TestWithProgress(t *testing.T) {
+	var s *Solver
+	var got []uint64
+	s, err := NewSolver(WithProgress(10, func(p Progress) {
+		got = append(got, p.Conflicts)
+		if want := len(s.locals) + len(s.cores); p.Learnts != want {
+			t.Errorf("progress learnts: want %d (local and core), got %d", want, p.Learnts)
+		}
+	}))
+	if err != nil {
+		t.Fatalf("NewSolver(): want no error, got %s", err)
+	}
+	addPigeonhole(s, 5)
+
+	if status := s.Solve().Status; status != False {
+		t.Fatalf("Solve(): want false, got %s", status)
+	}
+
+	// The last conflict is found at the root level and is not reported.
+	want := []uint64{}
+	for c := uint64(10); c < s.Statistics.Conflicts; c += 10 {
+		want = append(want, c)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("progress conflicts: mismatch (+want, -got):\n%s", diff)
+	}
+}
+
+func TestStopReason(t *testing.T) {
+	s, err := NewSolver(WithMaxConflicts(10))
+	if err != nil {
+		t.Fatalf("NewSolver(): want no error, got %s", err)
+	}
+	addPigeonhole(s, 6)
+
+	if status := s.Solve().Status; status != Unknown {
+		t.Fatalf("Solve(): want unknown, got %s", status)
+	}
+	if got := s.StopReason(); got != StoppedByMaxConflicts {
+		t.Errorf("StopReason(): want %s, got %s", StoppedByMaxConflicts, got)
+	}
+
+	s = newChainSolver(3)
+	if status := s.Solve().Status; status != True {
+		t.Fatalf("Solve(): want true, got %s", status)
+	}
+	if got := s.StopReason(); got != NotStopped {
+		t.Errorf("StopReason(): want %s, got %s", NotStopped, got)
+	}
+}
+
+func TestSolveBudgeted(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		conflicts    int64
+		propagations int64
+		wantStatus   LBool
+		wantReason   StopReason
+	}{
+		{"conflict budget", 10, -1, Unknown, StoppedByConflictBudget},
+		{"propagation budget", -1, 100, Unknown, StoppedByPropagationBudget},
+		{"no budget", -1, -1, False, NotStopped},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewDefaultSolver()
+			addPigeonhole(s, 5)
+
+			if got := s.SolveBudgeted(tc.conflicts, tc.propagations).Status; got != tc.wantStatus {
+				t.Fatalf("SolveBudgeted(): want %s, got %s", tc.wantStatus, got)
+			}
+			if got := s.StopReason(); got != tc.wantReason {
+				t.Errorf("StopReason(): want %s, got %s", tc.wantReason, got)
+			}
+			// Budgets are per call and do not apply to the next ones.
+			if got := s.Solve().Status; got != False {
+				t.Errorf("Solve(): want false, got %s", got)
+			}
+		})
+	}
+}
+
+func TestInterrupt(t *testing.T) {
+	started := make(chan struct{})
+	s, err := NewSolver(WithProgress(100, func(p Progress) {
+		if p.Conflicts == 100 {
+			close(started)
+		}
+	}))
+	if err != nil {
+		t.Fatalf("NewSolver(): want no error, got %s", err)
+	}
+	addPigeonhole(s, 10)
+
+	go func() {
+		<-started
+		s.Interrupt()
+	}()
+
+	if got := s.Solve().Status; got != Unknown {
+		t.Fatalf("Solve(): want unknown, got %s", got)
+	}
+	if got := s.StopReason(); got != StoppedByInterrupt {
+		t.Errorf("StopReason(): want %s, got %s", StoppedByInterrupt, got)
+	}
+
+	// Interruptions requested between two calls are discarded.
+	s.Interrupt()
+	if got := s.SolveBudgeted(10, -1).Status; got != Unknown {
+		t.Fatalf("SolveBudgeted(): want unknown, got %s", got)
+	}
+	if got := s.StopReason(); got != StoppedByConflictBudget {
+		t.Errorf("StopReason(): want %s, got %s", StoppedByConflictBudget, got)
+	}
+}
+
+func TestYield(t *testing.T) {
+	var s *Solver
+	calls := 0
+	s, err := NewSolver(WithYield(100, func() {
+		calls++
+		if s.Statistics.Iterations != uint64(100*calls) {
+			t.Errorf("yield %d: want %d iterations, got %d", calls, 100*calls, s.Statistics.Iterations)
+		}
+		if calls == 3 {
+			s.Interrupt() // yield points can stop the search
+		}
+	}))
+	if err != nil {
+		t.Fatalf("NewSolver(): want no error, got %s", err)
+	}
+	addPigeonhole(s, 10)
+
+	if got := s.Solve().Status; got != Unknown {
+		t.Fatalf("Solve(): want unknown, got %s", got)
+	}
+	if got := s.StopReason(); got != StoppedByInterrupt {
+		t.Errorf("StopReason(): want %s, got %s", StoppedByInterrupt, got)
+	}
+	if calls != 3 {
+		t.Errorf("yield calls: want 3, got %d", calls)
+	}
+
+	// Yield points are disabled with a zero interval.
+	s, err = NewSolver(WithYield(0, func() { t.Errorf("yield: want no call") }))
+	if err != nil {
+		t.Fatalf("NewSolver(): want no error, got %s", err)
+	}
+	addPigeonhole(s, 4)
+	if got := s.Solve().Status; got != False {
+		t.Errorf("Solve(): want false, got %s", got)
+	}
+}
+
+// TestVarOrder_heap verifies that the unassigned variables are always in the
+// heap of the decision order, on which NextDecision relies to detect that all
+// the variables are assigned.
+func TestVarOrder_heap(t *testing.T) {
+	checkHeap := func(s *Solver) {
+		t.Helper()
+		for v := 0; v < s.NumVariables(); v++ {
+			if s.VarValue(v) == Unknown && !s.order.heap.contains(v) {
+				t.Fatalf("variable %d: unassigned but not in the heap", v)
+			}
+		}
+	}
+
+	var s *Solver
+	s, err := NewSolver(
+		WithRandomDecisionFreq(0.1),
+		WithProgress(1, func(Progress) { checkHeap(s) }),
+	)
+	if err != nil {
+		t.Fatalf("NewSolver(): want no error, got %s", err)
+	}
+	addPigeonhole(s, 9)
+
+	if got := s.SolveBudgeted(2000, -1).Status; got != Unknown {
+		t.Fatalf("SolveBudgeted(): want unknown, got %s", got)
+	}
+	checkHeap(s)
+}
+
+func TestWithSeed(t *testing.T) {
+	// Random 3-SAT instance below the satisfiability threshold.
+	rng := rand.New(rand.NewSource(1))
+	clauses := make([][]Literal, 300)
+	for i := range clauses {
+		for j := 0; j < 3; j++ {
+			l := PositiveLiteral(rng.Intn(80))
+			if rng.Intn(2) == 0 {
+				l = l.Opposite()
+			}
+			clauses[i] = append(clauses[i], l)
+		}
+	}
+	solve := func(seed int64) Result {
+		t.Helper()
+		s, err := NewSolver(WithSeed(seed), WithRandomDecisionFreq(0.2))
+		if err != nil {
+			t.Fatalf("NewSolver(): want no error, got %s", err)
+		}
+		for i := 0; i < 80; i++ {
+			s.AddVariable()
+		}
+		for _, c := range clauses {
+			s.AddClause(slices.Clone(c))
+		}
+		return s.Solve()
+	}
+
+	first, second, other := solve(42), solve(42), solve(7)
+
+	if first.Status != True {
+		t.Fatalf("Solve(): want true, got %s", first.Status)
+	}
+	if diff := cmp.Diff(first, second, cmp.AllowUnexported(EMA{})); diff != "" {
+		t.Errorf("same seed: result mismatch (+want, -got):\n%s", diff)
+	}
+	if first.Statistics.Decisions == other.Statistics.Decisions &&
+		first.Statistics.Propagations == other.Statistics.Propagations &&
+		slices.Equal(first.Model, other.Model) {
+		t.Errorf("different seeds: want different search paths, got the same statistics and model")
+	}
+}
+
+func TestWithMaxMemoryMB(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		mb         int64
+		wantStatus LBool
+		wantReason StopReason
+	}{
+		{"exhausted", 0, Unknown, StoppedByMemory},
+		{"large enough", 1 << 20, False, NotStopped},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s, err := NewSolver(WithMaxMemoryMB(tc.mb))
+			if err != nil {
+				t.Fatalf("NewSolver(): want no error, got %s", err)
+			}
+			addPigeonhole(s, 5)
+
+			if got := s.Solve().Status; got != tc.wantStatus {
+				t.Errorf("Solve(): want %s, got %s", tc.wantStatus, got)
+			}
+			if got := s.StopReason(); got != tc.wantReason {
+				t.Errorf("StopReason(): want %s, got %s", tc.wantReason, got)
+			}
+		})
+	}
+}
+
+// recordingLogger records the formatted reports it is given.
+type recordingLogger struct {
+	lines []string
+}
+
+func (l *recordingLogger) Printf(format string, args ...any) {
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+}
+
+func TestWithLogger(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		verbosity int
+		want      []string // first reports
+	}{
+		{"verbose", 1, []string{"c variables: 4\n", "c clauses:   3\n"}},
+		{"silent", 0, []string{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			logger := &recordingLogger{}
+			s, err := NewSolver(WithLogger(logger), WithVerbosity(tc.verbosity))
+			if err != nil {
+				t.Fatalf("NewSolver(): want no error, got %s", err)
+			}
+			for i := 0; i < 4; i++ {
+				s.AddVariable()
+			}
+			for i := 0; i+1 < 4; i++ {
+				s.AddClause([]Literal{NegativeLiteral(i), PositiveLiteral(i + 1)})
+			}
+
+			if status := s.Solve().Status; status != True {
+				t.Fatalf("Solve(): want true, got %s", status)
+			}
+			got := logger.lines
+			if len(tc.want) > 0 {
+				got = got[:min(len(tc.want), len(got))]
+			}
+			if diff := cmp.Diff(tc.want, append([]string{}, got...)); diff != "" {
+				t.Errorf("reports: mismatch (+want, -got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWithVerbosity(t *testing.T) {
+	testCases := []struct {
+		verbosity   int
+		wantRestart bool
+	}{
+		{0, false},
+		{1, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprint(tc.verbosity), func(t *testing.T) {
+			logger := &recordingLogger{}
+			s, err := NewSolver(WithLogger(logger), WithVerbosity(tc.verbosity))
+			if err != nil {
+				t.Fatalf("NewSolver(): want no error, got %s", err)
+			}
+			addPigeonhole(s, 6)
+
+			if status := s.Solve().Status; status != False {
+				t.Fatalf("Solve(): want false, got %s", status)
+			}
+			gotRestart := slices.ContainsFunc(logger.lines, func(l string) bool {
+				return strings.HasPrefix(l, "c R ")
+			})
+			if gotRestart != tc.wantRestart {
+				t.Errorf("restart reports: want %t, got %t", tc.wantRestart, gotRestart)
+			}
+		})
+	}
+}
+
+// countingLogger counts the periodic reports of the search statistics.
+type countingLogger struct {
+	stats int
+}
+
+func (l *countingLogger) Printf(format string, args ...any) {
+	if strings.HasPrefix(format, "c %s") && args[0] == " " {
+		l.stats++
+	}
+}
+
+func TestWithStatsInterval(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		interval StatsInterval
+		want     func(conflicts uint64) int
+	}{
+		{"disabled", StatsInterval{}, func(uint64) int { return 2 }},
+		{"conflicts", StatsInterval{Conflicts: 10}, func(c uint64) int { return 2 + int(c/10) }},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			logger := &countingLogger{}
+			s, err := NewSolver(WithLogger(logger), WithStatsInterval(tc.interval))
+			if err != nil {
+				t.Fatalf("NewSolver(): want no error, got %s", err)
+			}
+			addPigeonhole(s, 5)
+
+			if status := s.Solve().Status; status != False {
+				t.Fatalf("Solve(): want false, got %s", status)
+			}
+			// The last conflict is found at the root level and is not reported.
+			if want := tc.want(s.Statistics.Conflicts - 1); logger.stats != want {
+				t.Errorf("reports: want %d, got %d", want, logger.stats)
+			}
+		})
+	}
+}
+
+func TestRequestDiagnostics(t *testing.T) {
+	s := NewDefaultSolver()
+	addPigeonhole(s, 4)
+
+	var got []Diagnostics
+	s.RequestDiagnostics(func(d Diagnostics) { got = append(got, d) })
+	if status := s.Solve().Status; status != False {
+		t.Fatalf("Solve(): want false, got %s", status)
+	}
+
+	if len(got) != 1 {
+		t.Fatalf("RequestDiagnostics(): want 1 call, got %d", len(got))
+	}
+	if d := got[0]; d.Variables != 20 || len(d.TopVariables) != numTopVariables {
+		t.Errorf("RequestDiagnostics(): want 20 variables and %d top variables, got %d and %d",
+			numTopVariables, d.Variables, len(d.TopVariables))
+	}
+}
+
+func TestModel(t *testing.T) {
+	s := newChainSolver(3)
+
+	if status := s.SolveWithAssumptions([]Literal{PositiveLiteral(0)}).Status; status != True {
+		t.Fatalf("SolveWithAssumptions(x0): want true, got %s", status)
+	}
+	if diff := cmp.Diff([]bool{true, true, true}, s.Model()); diff != "" {
+		t.Errorf("Model(): mismatch (+want, -got):\n%s", diff)
+	}
+	for v := 0; v < 3; v++ {
+		if got := s.Value(v); got != True {
+			t.Errorf("Value(%d): want true, got %s", v, got)
+		}
+	}
+	if got := s.Value(3); got != Unknown {
+		t.Errorf("Value(3): want unknown, got %s", got)
+	}
+
+	assumptions := []Literal{PositiveLiteral(0), NegativeLiteral(2)}
+	if status := s.SolveWithAssumptions(assumptions).Status; status != False {
+		t.Fatalf("SolveWithAssumptions(x0, !x2): want false, got %s", status)
+	}
+	if got := s.Model(); got != nil {
+		t.Errorf("Model(): want nil, got %v", got)
+	}
+	if got := s.Value(0); got != Unknown {
+		t.Errorf("Value(0): want unknown, got %s", got)
+	}
+}
+
+func TestReset(t *testing.T) {
+	reductions := DefaultOptions.Reductions
+	reductions.Interval = 20
+	s, err := NewSolver(WithReductions(reductions))
+	if err != nil {
+		t.Fatalf("NewSolver(): want no error, got %s", err)
+	}
+	addPigeonhole(s, 5)
+	s.SolveBudgeted(50, -1)
+
+	s.Reset()
+	s.ResetActivities()
+	if got := s.NumLearnts(); got != 0 {
+		t.Errorf("NumLearnts(): want 0 after Reset, got %d", got)
+	}
+	if got := s.Statistics.Conflicts; got != 0 {
+		t.Errorf("Statistics.Conflicts: want 0 after Reset, got %d", got)
+	}
+	if got := s.conflictBeforeReduce; got != 20 {
+		t.Errorf("conflictBeforeReduce: want the configured interval 20 after Reset, got %d", got)
+	}
+
+	for v, score := range s.order.heap.scores {
+		if score != 0 {
+			t.Errorf("score of variable %d: want 0 after ResetActivities, got %v", v, score)
+		}
+	}
+
+	if got := s.Solve().Status; got != False {
+		t.Errorf("Solve(): want false, got %s", got)
+	}
+}
+
+func TestProblemClauses(t *testing.T) {
+	s := newChainSolver(4)
+
+	got := [][]Literal{}
+	s.ProblemClauses(func(c []Literal) bool {
+		got = append(got, slices.Clone(c))
+		return len(got) < 2
+	})
+
+	want := [][]Literal{
+		{NegativeLiteral(0), PositiveLiteral(1)},
+		{NegativeLiteral(1), PositiveLiteral(2)},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ProblemClauses(): mismatch (+want, -got):\n%s", diff)
+	}
+}
+
+func TestLearntClauses(t *testing.T) {
+	s, err := NewSolver(WithMaxConflicts(20))
+	if err != nil {
+		t.Fatalf("NewSolver(): want no error, got %s", err)
+	}
+	addPigeonhole(s, 6)
+	s.Solve()
+
+	got := 0
+	s.LearntClauses(func([]Literal) bool {
+		got++
+		return true
+	})
+	if want := len(s.cores) + len(s.locals); got != want {
+		t.Errorf("LearntClauses(): want %d clauses, got %d", want, got)
+	}
+}
+
+func TestResult(t *testing.T) {
+	s, err := NewSolver(WithMaxConflicts(10))
+	if err != nil {
+		t.Fatalf("NewSolver(): want no error, got %s", err)
+	}
+	addPigeonhole(s, 6)
+
+	got := s.Solve()
+	if got.Status != Unknown || got.StopReason != StoppedByMaxConflicts {
+		t.Errorf("Solve(): want status unknown stopped by %s, got %s stopped by %s",
+			StoppedByMaxConflicts, got.Status, got.StopReason)
+	}
+	if got.Statistics.Conflicts != 10 {
+		t.Errorf("Solve(): want 10 conflicts, got %d", got.Statistics.Conflicts)
+	}
+	if got.Model != nil {
+		t.Errorf("Solve(): want no model, got %v", got.Model)
+	}
+
+	// The statistics are a snapshot of the call.
+	s.SolveBudgeted(5, -1)
+	if got.Statistics.Conflicts != 10 {
+		t.Errorf("Solve(): want the result to keep 10 conflicts, got %d", got.Statistics.Conflicts)
+	}
+
+	s, err = NewSolver()
+	if err != nil {
+		t.Fatalf("NewSolver(): want no error, got %s", err)
+	}
+	s.AddVariable()
+	s.AddClause([]Literal{PositiveLiteral(0)})
+	got = s.Solve()
+	if got.Status != True || got.StopReason != NotStopped {
+		t.Errorf("Solve(): want status true not stopped, got %s stopped by %s", got.Status, got.StopReason)
+	}
+	if diff := cmp.Diff([]bool{true}, got.Model); diff != "" {
+		t.Errorf("Solve(): model mismatch (+want, -got):\n%s", diff)
+	}
+}
+
+func TestAddClauses(t *testing.T) {
+	s := NewDefaultSolver()
+	s.ReserveVariables(3)
+	for i := 0; i < 3; i++ {
+		s.AddVariable()
+	}
+	err := s.AddClauses([][]Literal{
+		{PositiveLiteral(0), PositiveLiteral(1)},
+		{NegativeLiteral(0)},
+		{NegativeLiteral(1), PositiveLiteral(2)},
+	})
+	if err != nil {
+		t.Fatalf("AddClauses(): want no error, got %s", err)
+	}
+
+	if status := s.Solve().Status; status != True {
+		t.Fatalf("Solve(): want true, got %s", status)
+	}
+	if diff := cmp.Diff([]bool{false, true, true}, s.Model()); diff != "" {
+		t.Errorf("Model(): mismatch (+want, -got):\n%s", diff)
+	}
+}
+
+func TestWithAutoAddVariables(t *testing.T) {
+	clause := []Literal{PositiveLiteral(0), NegativeLiteral(4)}
+
+	s := NewDefaultSolver()
+	if err := s.AddClause(slices.Clone(clause)); err == nil {
+		t.Errorf("AddClause(): want error on unknown variables, got none")
+	}
+
+	s, err := NewSolver(WithAutoAddVariables(true))
+	if err != nil {
+		t.Fatalf("NewSolver(): want no error, got %s", err)
+	}
+	if err := s.AddClause(slices.Clone(clause)); err != nil {
+		t.Fatalf("AddClause(): want no error, got %s", err)
+	}
+	if got := s.NumVariables(); got != 5 {
+		t.Errorf("NumVariables(): want 5, got %d", got)
+	}
+}
+
+func TestAddClause_betweenSolves(t *testing.T) {
+	s := newChainSolver(3)
+	if status := s.Solve().Status; status != True {
+		t.Fatalf("Solve(): want true, got %s", status)
+	}
+
+	// Forbid x2, which propagates !x1 and !x0 at the root level.
+	if err := s.AddClause([]Literal{NegativeLiteral(2)}); err != nil {
+		t.Fatalf("AddClause(): want no error, got %s", err)
+	}
+	if got := s.Model(); got != nil {
+		t.Errorf("Model(): want nil after AddClause, got %v", got)
+	}
+	if got := s.VarValue(0); got != False {
+		t.Errorf("VarValue(0): want false after AddClause, got %s", got)
+	}
+
+	if status := s.Solve().Status; status != True {
+		t.Fatalf("Solve(): want true, got %s", status)
+	}
+	if diff := cmp.Diff([]bool{false, false, false}, s.Model()); diff != "" {
+		t.Errorf("Model(): mismatch (+want, -got):\n%s", diff)
+	}
+
+	if err := s.AddClause([]Literal{PositiveLiteral(0)}); err != nil {
+		t.Fatalf("AddClause(): want no error, got %s", err)
+	}
+	if status := s.Solve().Status; status != False {
+		t.Errorf("Solve(): want false, got %s", status)
+	}
+}
+
+func TestAddVariables(t *testing.T) {
+	s := NewDefaultSolver()
+	s.AddVariable()
+
+	if got := s.AddVariables(3); got != 1 {
+		t.Errorf("AddVariables(3): want first variable 1, got %d", got)
+	}
+	if got := s.NumVariables(); got != 4 {
+		t.Fatalf("NumVariables(): want 4, got %d", got)
+	}
+	for v := 0; v < 4; v++ {
+		if s.VarValue(v) != Unknown || s.assignLevels[v] != -1 || !s.order.heap.contains(v) {
+			t.Errorf("variable %d: want unassigned and in the heap", v)
+		}
+	}
+	s.AddClause([]Literal{NegativeLiteral(3)})
+	if status := s.Solve().Status; status != True || s.Model()[3] {
+		t.Errorf("Solve(): want true with x3 false, got %s and %v", status, s.Model())
+	}
+}
+
+func TestUnsatClause(t *testing.T) {
+	s := NewDefaultSolver()
+	s.AddVariable()
+	s.AddVariable()
+	clauses := [][]Literal{
+		{PositiveLiteral(0), PositiveLiteral(1)},
+		{NegativeLiteral(0)},
+		{NegativeLiteral(1)}, // propagates a conflict with the clauses above
+		{},                   // already unsatisfiable
+	}
+	for i, c := range clauses {
+		if err := s.AddClause(c); err != nil {
+			t.Fatalf("AddClause(): want no error, got %s", err)
+		}
+		got, ok := s.UnsatClause()
+		if want := i >= 2; ok != want || (ok && got != 2) {
+			t.Errorf("UnsatClause() after clause %d: got (%d, %t)", i, got, ok)
+		}
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	s := newChainSolver(2)
+
+	s.Freeze(1)
+	if !s.IsFrozen(1) || s.IsFrozen(0) {
+		t.Errorf("IsFrozen(): want only variable 1 frozen, got %t and %t", s.IsFrozen(0), s.IsFrozen(1))
+	}
+	s.Melt(1)
+	if s.IsFrozen(1) {
+		t.Errorf("IsFrozen(1): want false after Melt, got true")
+	}
+}
+
+func TestAddRemovableClause(t *testing.T) {
+	s := newChainSolver(3)
+	if err := s.AddClause([]Literal{PositiveLiteral(0)}); err != nil {
+		t.Fatalf("AddClause(): want no error, got %s", err)
+	}
+
+	selector, err := s.AddRemovableClause([]Literal{NegativeLiteral(2)})
+	if err != nil {
+		t.Fatalf("AddRemovableClause(): want no error, got %s", err)
+	}
+	if status := s.Solve().Status; status != False {
+		t.Fatalf("Solve(): want false with the removable clause, got %s", status)
+	}
+	if diff := cmp.Diff([]Literal{selector}, s.FailedAssumptions()); diff != "" {
+		t.Errorf("FailedAssumptions(): mismatch (+want, -got):\n%s", diff)
+	}
+
+	if err := s.RemoveClause(selector); err != nil {
+		t.Fatalf("RemoveClause(): want no error, got %s", err)
+	}
+	if status := s.Solve().Status; status != True {
+		t.Errorf("Solve(): want true once the clause is removed, got %s", status)
+	}
+	if err := s.RemoveClause(selector); err == nil {
+		t.Errorf("RemoveClause(): want error on removed clause, got none")
+	}
+}
+
+func TestImportClause(t *testing.T) {
+	s := newChainSolver(3)
+
+	done := make(chan struct{})
+	go func() {
+		s.ImportClause([]Literal{PositiveLiteral(0)})
+		s.ImportClause([]Literal{NegativeLiteral(2)})
+		close(done)
+	}()
+	<-done
+
+	if status := s.Solve().Status; status != False {
+		t.Errorf("Solve(): want false with the imported clauses, got %s", status)
+	}
+}
+
+func TestImportClause_concurrent(t *testing.T) {
+	proof := &bytes.Buffer{}
+	s, err := NewSolver(WithProof(proof, ProofText))
+	if err != nil {
+		t.Fatalf("NewSolver(): want no error, got %s", err)
+	}
+	addPigeonhole(s, 7)
+	clauses := [][]Literal{}
+	for _, c := range s.constraints {
+		clauses = append(clauses, slices.Clone(c.literals))
+	}
+
+	// Re-import the problem clauses before and, from several goroutines,
+	// while solving.
+	s.ImportClause(clauses[0])
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, c := range clauses {
+				s.ImportClause(c)
+			}
+		}()
+	}
+	got := s.Solve().Status
+	wg.Wait()
+
+	if got != False {
+		t.Errorf("Solve(): want false, got %s", got)
+	}
+	if err := s.ProofError(); err != ErrProofImport {
+		t.Errorf("ProofError(): want %q, got %v", ErrProofImport, err)
+	}
+}
+
+func TestWithLearntExport(t *testing.T) {
+	got := 0
+	s, err := NewSolver(WithLearntExport(3, func(lits []Literal, lbd int) {
+		if lbd > 3 {
+			t.Errorf("exported clause %v: want LBD at most 3, got %d", lits, lbd)
+		}
+		got++
+	}))
+	if err != nil {
+		t.Fatalf("NewSolver(): want no error, got %s", err)
+	}
+	addPigeonhole(s, 5)
+
+	if status := s.Solve().Status; status != False {
+		t.Fatalf("Solve(): want false, got %s", status)
+	}
+	if got == 0 {
+		t.Errorf("exported clauses: want at least one, got none")
+	}
+}
+
+func TestEnumerateModels(t *testing.T) {
+	testCases := []struct {
+		desc string
+		opts EnumerateOptions
+		want Enumeration
+	}{
+		{
+			desc: "all models",
+			opts: EnumerateOptions{},
+			want: Enumeration{Models: 5, Exhaustive: true},
+		},
+		{
+			desc: "max models",
+			opts: EnumerateOptions{MaxModels: 2},
+			want: Enumeration{Models: 2, StopReason: StoppedByModelLimit},
+		},
+		{
+			desc: "stopped by callback",
+			opts: EnumerateOptions{OnModel: func([]bool) bool { return false }},
+			want: Enumeration{Models: 1, StopReason: StoppedByCallback},
+		},
+		{
+			desc: "assumptions",
+			opts: EnumerateOptions{Assumptions: []Literal{PositiveLiteral(1)}},
+			want: Enumeration{Models: 2, Exhaustive: true},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := newChainSolver(4) // 5 models
+			got := s.EnumerateModels(tc.opts)
+			if got != tc.want {
+				t.Errorf("EnumerateModels(): want %+v, got %+v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestEnumerateModels_statistics(t *testing.T) {
+	s := newChainSolver(4)
+	decisions := uint64(0) // of the satisfiable calls
+	s.EnumerateModels(EnumerateOptions{OnModel: func([]bool) bool {
+		decisions += s.Statistics.Decisions
+		return true
+	}})
+
+	if s.Statistics.Decisions < decisions || decisions == 0 {
+		t.Errorf("Statistics.Decisions: want at least %d (sum over the calls), got %d", decisions, s.Statistics.Decisions)
+	}
+}
+
+func TestEnumerateModels_interrupt(t *testing.T) {
+	// The interruption is requested between two solve calls of the
+	// enumeration, which must not discard it.
+	s := newChainSolver(4)
+	got := s.EnumerateModels(EnumerateOptions{OnModel: func([]bool) bool {
+		s.Interrupt()
+		return true
+	}})
+
+	want := Enumeration{Models: 1, StopReason: StoppedByInterrupt}
+	if got != want {
+		t.Errorf("EnumerateModels(): want %+v, got %+v", want, got)
+	}
+
+	// The interruption does not outlive the enumeration.
+	if got := s.Solve().Status; got != True {
+		t.Errorf("Solve(): want true, got %s", got)
+	}
+}
+
+func TestWhyImplied(t *testing.T) {
+	s := newChainSolver(4)
+	s.AddClause([]Literal{PositiveLiteral(1)}) // implies x2 and x3
+
+	want := []Implication{
+		{Literal: PositiveLiteral(1)},
+		{
+			Literal: PositiveLiteral(2),
+			Clause:  []Literal{PositiveLiteral(2), NegativeLiteral(1)},
+			Reason:  []Literal{PositiveLiteral(1)},
+		},
+		{
+			Literal: PositiveLiteral(3),
+			Clause:  []Literal{PositiveLiteral(3), NegativeLiteral(2)},
+			Reason:  []Literal{PositiveLiteral(2)},
+		},
+	}
+	if diff := cmp.Diff(want, s.WhyImplied(PositiveLiteral(3))); diff != "" {
+		t.Errorf("WhyImplied(): mismatch (+want, -got):\n%s", diff)
+	}
+	if got := s.ExplainAssignment(PositiveLiteral(3)); !slices.Equal(got, want[2].Reason) {
+		t.Errorf("ExplainAssignment(): want %v, got %v", want[2].Reason, got)
+	}
+	if got := s.WhyImplied(PositiveLiteral(0)); got != nil {
+		t.Errorf("WhyImplied() of an unassigned literal: want nil, got %v", got)
+	}
+}
+
+func TestWriteImplicationGraph(t *testing.T) {
+	s := newChainSolver(3)
+	s.AddClause([]Literal{PositiveLiteral(1)}) // implies x2
+
+	sb := strings.Builder{}
+	if err := s.WriteImplicationGraph(&sb); err != nil {
+		t.Fatalf("WriteImplicationGraph(): want no error, got %s", err)
+	}
+
+	want := `digraph implications {
+  "2" [label="2 @0", shape=box];
+  "3" [label="3 @0", shape=ellipse];
+  "2" -> "3";
+}
+`
+	if diff := cmp.Diff(want, sb.String()); diff != "" {
+		t.Errorf("WriteImplicationGraph(): mismatch (+want, -got):\n%s", diff)
+	}
+}
+
+func TestWithConflictGraphs(t *testing.T) {
+	sb := strings.Builder{}
+	s, err := NewSolver(WithConflictGraphs(&sb, 3))
+	if err != nil {
+		t.Fatalf("NewSolver(): want no error, got %s", err)
+	}
+	addPigeonhole(s, 4)
+	s.Solve()
+
+	if got := strings.Count(sb.String(), "digraph conflict_"); got != 3 {
+		t.Errorf("WithConflictGraphs(): want 3 graphs, got %d", got)
+	}
+	if got := strings.Count(sb.String(), "-> conflict;"); got == 0 {
+		t.Errorf("WithConflictGraphs(): want edges to the conflict nodes, got none")
+	}
+}
+
+func TestWithConflictTrace(t *testing.T) {
+	sb := strings.Builder{}
+	s, err := NewSolver(WithConflictTrace(&sb, 2))
+	if err != nil {
+		t.Fatalf("NewSolver(): want no error, got %s", err)
+	}
+	addPigeonhole(s, 4)
+	s.Solve()
+
+	lines := strings.Split(strings.TrimSpace(sb.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("WithConflictTrace(): want 2 traces, got %d", len(lines))
+	}
+	for i, line := range lines {
+		r := conflictRecord{}
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("trace %d: invalid JSON: %s", i, err)
+		}
+		if r.Conflict != uint64(i+1) {
+			t.Errorf("trace %d: want conflict %d, got %d", i, i+1, r.Conflict)
+		}
+		if len(r.Clause) == 0 || len(r.Learnt) == 0 {
+			t.Errorf("trace %d: want conflicting and learnt clauses, got %v and %v", i, r.Clause, r.Learnt)
+		}
+		if r.BacktrackLevel >= r.Level {
+			t.Errorf("trace %d: want backtrack level below %d, got %d", i, r.Level, r.BacktrackLevel)
+		}
+	}
+}
+
+func TestWithConflictTrace_solveCalls(t *testing.T) {
+	sb := strings.Builder{}
+	s, err := NewSolver(WithConflictTrace(&sb, 4))
+	if err != nil {
+		t.Fatalf("NewSolver(): want no error, got %s", err)
+	}
+	addPigeonhole(s, 4)
+	s.SolveBudgeted(2, -1)
+	s.SolveBudgeted(2, -1)
+
+	got := []uint64{}
+	for _, line := range strings.Split(strings.TrimSpace(sb.String()), "\n") {
+		r := conflictRecord{}
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("invalid JSON: %s", err)
+		}
+		got = append(got, r.Conflict)
+	}
+	if diff := cmp.Diff([]uint64{1, 2, 1, 2}, got); diff != "" {
+		t.Errorf("conflict numbers: mismatch (+want, -got):\n%s", diff)
+	}
+}
+
+func TestMinimizeModel(t *testing.T) {
+	s := NewDefaultSolver()
+	for i := 0; i < 4; i++ {
+		s.AddVariable()
+	}
+	// At least one of x0 and x1, and x2 implies x3.
+	s.AddClause([]Literal{PositiveLiteral(0), PositiveLiteral(1)})
+	s.AddClause([]Literal{NegativeLiteral(2), PositiveLiteral(3)})
+
+	if status := s.Solve().Status; status != True {
+		t.Fatalf("Solve(): want true, got %s", status)
+	}
+	if status := s.MinimizeModel(nil); status != True {
+		t.Fatalf("MinimizeModel(): want true, got %s", status)
+	}
+	if diff := cmp.Diff([]bool{false, true, false, false}, s.Model()); diff != "" {
+		t.Errorf("Model(): mismatch (+want, -got):\n%s", diff)
+	}
+}
+
+func TestMinimizeModel_models(t *testing.T) {
+	s, err := NewSolver(WithDefaultPolarity(true)) // the first model is all true
+	if err != nil {
+		t.Fatalf("NewSolver(): want no error, got %s", err)
+	}
+	for i := 0; i < 3; i++ {
+		s.AddVariable()
+	}
+	s.AddClause([]Literal{PositiveLiteral(0), PositiveLiteral(1), PositiveLiteral(2)})
+
+	if status := s.Solve().Status; status != True {
+		t.Fatalf("Solve(): want true, got %s", status)
+	}
+	if status := s.MinimizeModel(nil); status != True {
+		t.Fatalf("MinimizeModel(): want true, got %s", status)
+	}
+	want := [][]bool{{false, false, true}}
+	if diff := cmp.Diff(want, s.Models); diff != "" {
+		t.Errorf("Models: mismatch (+want, -got):\n%s", diff)
+	}
+}
+
+func TestMinimizeModel_statistics(t *testing.T) {
+	// Pigeonhole whose first pigeon may stay out of the holes, which is
+	// satisfiable but only once the solver proves that it must stay out.
+	const n = 5
+	s := NewDefaultSolver()
+	for i := 0; i <= (n+1)*n; i++ {
+		s.AddVariable()
+	}
+	out := PositiveLiteral((n + 1) * n)
+	for p := 0; p <= n; p++ {
+		clause := []Literal{}
+		if p == 0 {
+			clause = append(clause, out)
+		}
+		for h := 0; h < n; h++ {
+			clause = append(clause, PositiveLiteral(p*n+h))
+		}
+		s.AddClause(clause)
+	}
+	for h := 0; h < n; h++ {
+		for p := 0; p <= n; p++ {
+			for q := p + 1; q <= n; q++ {
+				s.AddClause([]Literal{NegativeLiteral(p*n + h), NegativeLiteral(q*n + h)})
+			}
+		}
+	}
+
+	first := s.Solve()
+	if first.Status != True {
+		t.Fatalf("Solve(): want true, got %s", first.Status)
+	}
+	if status := s.MinimizeModel(nil); status != True {
+		t.Fatalf("MinimizeModel(): want true, got %s", status)
+	}
+
+	got := s.Statistics
+	if got.Conflicts < first.Statistics.Conflicts || first.Statistics.Conflicts == 0 {
+		t.Errorf("conflicts: want at least %d (first solve), got %d", first.Statistics.Conflicts, got.Conflicts)
+	}
+	if got.Propagations <= first.Statistics.Propagations {
+		t.Errorf("propagations: want more than %d (first solve), got %d", first.Statistics.Propagations, got.Propagations)
+	}
+}
+
+func TestSetPolarity(t *testing.T) {
+	s, err := NewSolver(WithDefaultPolarity(false))
+	if err != nil {
+		t.Fatalf("NewSolver(): want no error, got %s", err)
+	}
+	for i := 0; i < 3; i++ {
+		s.AddVariable()
+	}
+	s.SetPolarity(1, True)
+
+	if status := s.Solve().Status; status != True {
+		t.Fatalf("Solve(): want true, got %s", status)
+	}
+	if diff := cmp.Diff([]bool{false, true, false}, s.Model()); diff != "" {
+		t.Errorf("Model(): mismatch (+want, -got):\n%s", diff)
+	}
+}
+
+func TestDiverseModels(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		k          int
+		wantModels int
+		wantStatus LBool
+	}{
+		{"all found", 4, 4, True},
+		{"too many", 5, 4, False}, // at most 4 words of 3 bits at distance 2
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewDefaultSolver()
+			for i := 0; i < 3; i++ {
+				s.AddVariable()
+			}
+
+			models, status := s.DiverseModels(tc.k, 2)
+
+			if status != tc.wantStatus {
+				t.Errorf("DiverseModels(): want status %s, got %s", tc.wantStatus, status)
+			}
+			if len(models) != tc.wantModels {
+				t.Fatalf("DiverseModels(): want %d models, got %d", tc.wantModels, len(models))
+			}
+			for i := range models {
+				for j := i + 1; j < len(models); j++ {
+					d := 0
+					for v := range models[i] {
+						if models[i][v] != models[j][v] {
+							d++
+						}
+					}
+					if d < 2 {
+						t.Errorf("models %v and %v: want distance at least 2, got %d", models[i], models[j], d)
+					}
+				}
+			}
+			if status := s.Solve().Status; status != True {
+				t.Errorf("Solve() after DiverseModels(): want true, got %s", status)
+			}
+		})
+	}
+}
+
+// searchAllocsPerConflict is the maximum average number of allocations per
+// conflict tolerated once the solver is warmed up. Conflict analysis does not
+// allocate (see TestAnalyzeAllocations), the remaining allocations come from
+// the growth of the clause DB between two reductions and of the core tier:
+// the watch lists and the list of learnt clauses grow their capacity, and the
+// clause pool allocates a new chunk when no deleted clause of the right size
+// can be recycled. These are amortized over many conflicts.
+const searchAllocsPerConflict = 0.02
+
+// TestAnalyzeAllocations verifies that analyzing a conflict, i.e. explaining
+// it and deriving the minimized learnt clause, does not allocate.
+func TestAnalyzeAllocations(t *testing.T) {
+	s := NewDefaultSolver()
+	addPigeonhole(s, 8)
+	s.SolveBudgeted(2000, -1) // learn clauses and grow the buffers
+
+	var conflict *Clause
+	for conflict == nil {
+		l, ok := s.order.NextDecision(s)
+		if !ok {
+			t.Fatalf("NextDecision(): want a decision before the first conflict")
+		}
+		s.assume(l)
+		conflict = s.Propagate()
+	}
+
+	allocs := testing.AllocsPerRun(10, func() { s.analyze(conflict) })
+
+	if allocs != 0 {
+		t.Errorf("allocations per analysis: want 0, got %g", allocs)
+	}
+}
+
+func TestSearchAllocations(t *testing.T) {
+	const conflicts = 10000
+	s := NewDefaultSolver()
+	addPigeonhole(s, 10)
+	s.SolveBudgeted(2*conflicts, -1) // warm-up
+
+	// Note that AllocsPerRun makes an additional warm-up call.
+	allocs := testing.AllocsPerRun(1, func() {
+		if got := s.SolveBudgeted(conflicts, -1).Status; got != Unknown {
+			t.Fatalf("SolveBudgeted(): want %s, got %s", Unknown, got)
+		}
+	})
+
+	if got := allocs / conflicts; got > searchAllocsPerConflict {
+		t.Errorf("allocations per conflict: want at most %g, got %g", searchAllocsPerConflict, got)
+	}
+}
+
+// BenchmarkSearch measures the cost of a conflict once the solver is warmed up,
+// each iteration being one conflict. No allocation is expected per conflict.
+func BenchmarkSearch(b *testing.B) {
+	s := NewDefaultSolver()
+	addPigeonhole(s, 12)
+	s.SolveBudgeted(10000, -1) // warm-up
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	s.SolveBudgeted(int64(b.N), -1)
+	b.StopTimer()
+
+	if s.Statistics.Conflicts != uint64(b.N) {
+		b.Fatalf("conflicts: want %d, got %d", b.N, s.Statistics.Conflicts)
+	}
+}
+
+// BenchmarkSolve measures the cost of solving a small pigeonhole instance from
+// scratch, including the allocation of the solver.
+func BenchmarkSolve(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s := NewDefaultSolver()
+		addPigeonhole(s, 7)
+		if got := s.Solve().Status; got != False {
+			b.Fatalf("Solve(): want %s, got %s", False, got)
+		}
+	}
+}
+
+// BenchmarkPropagate measures the propagation of a decision through a chain of
+// 10000 binary implications.
+func BenchmarkPropagate(b *testing.B) {
+	const n = 10000
+	s := newChainSolver(n)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.assume(PositiveLiteral(0))
+		if c := s.Propagate(); c != nil {
+			b.Fatalf("Propagate(): want no conflict, got %s", c)
+		}
+		s.backtrackTo(0)
+	}
+	b.ReportMetric(float64(b.N*n)/b.Elapsed().Seconds(), "assigns/s")
+}
+
+// newImplicationGraphSolver returns a solver in conflict on a synthetic
+// implication graph: k variables are decided, one per level, and a last
+// decision implies a chain of m variables in which each implication also
+// depends on one of the first k decisions, the end of the chain conflicting
+// with the last decision. The conflicting clause is returned.
+func newImplicationGraphSolver(k, m int) (*Solver, *Clause) {
+	s := NewDefaultSolver()
+	for i := 0; i < k+1+m; i++ {
+		s.AddVariable()
+	}
+	a := func(i int) int { return i % k }
+	d := k
+	x := func(i int) int { return k + 1 + i }
+
+	s.AddClause([]Literal{NegativeLiteral(d), PositiveLiteral(x(0))})
+	for i := 1; i < m; i++ {
+		s.AddClause([]Literal{NegativeLiteral(x(i - 1)), NegativeLiteral(a(i)), PositiveLiteral(x(i))})
+	}
+	s.AddClause([]Literal{NegativeLiteral(x(m - 1)), NegativeLiteral(d)})
+
+	for i := 0; i < k; i++ {
+		s.assume(PositiveLiteral(a(i)))
+		s.Propagate()
+	}
+	s.assume(PositiveLiteral(d))
+	return s, s.Propagate()
+}
+
+// BenchmarkAnalyze measures the analysis of a conflict whose learnt clause is
+// derived from 1000 reasons.
+func BenchmarkAnalyze(b *testing.B) {
+	s, conflict := newImplicationGraphSolver(100, 1000)
+	if conflict == nil {
+		b.Fatalf("Propagate(): want conflict, got none")
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if learnt, _, _ := s.analyze(conflict); len(learnt) != 101 {
+			b.Fatalf("analyze(): want 101 literals, got %d", len(learnt))
+		}
+	}
+}
+
+// BenchmarkReduceDB measures the reduction of a DB of 20000 learnt clauses of
+// random size, LBD, and activity.
+func BenchmarkReduceDB(b *testing.B) {
+	const nVars = 1000
+	const nLearnts = 20000
+	rng := rand.New(rand.NewSource(0))
+	learnts := make([][]Literal, nLearnts)
+	for i := range learnts {
+		for _, v := range rng.Perm(nVars)[:3+rng.Intn(30)] {
+			learnts[i] = append(learnts[i], PositiveLiteral(v)^Literal(rng.Intn(2)))
+		}
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		s := NewDefaultSolver()
+		for v := 0; v < nVars; v++ {
+			s.AddVariable()
+		}
+		for _, lits := range learnts {
+			c := s.pool.get(len(lits))
+			copy(c.literals, lits)
+			c.prevPos = 2
+			c.statusMask |= statusLearnt
+			c.lbd = uint32(3 + rng.Intn(10))
+			c.activity = rng.Float64()
+			s.Watch(c, lits[0].Opposite(), lits[1])
+			s.Watch(c, lits[1].Opposite(), lits[0])
+			s.locals = append(s.locals, c)
+		}
+		b.StartTimer()
+
+		s.ReduceDB()
+	}
+}
+
+// decodeCNF decodes a small CNF from fuzzing data. The first byte sets the
+// number of variables (at most 10). Each clause is then given by a byte
+// setting its size (at most 4) followed by one byte per literal: the lower
+// bits select the variable and the highest bit negates it.
+func decodeCNF(data []byte) (int, [][]Literal) {
+	if len(data) == 0 {
+		return 0, nil
+	}
+	nVars := 1 + int(data[0])%10
+	clauses := [][]Literal{}
+	for i := 1; i < len(data); {
+		size := 1 + int(data[i])%4
+		i++
+		clause := []Literal{}
+		for ; size > 0 && i < len(data); size-- {
+			l := PositiveLiteral(int(data[i]&0x7f) % nVars)
+			if data[i]&0x80 != 0 {
+				l = l.Opposite()
+			}
+			clause = append(clause, l)
+			i++
+		}
+		clauses = append(clauses, clause)
+	}
+	return nVars, clauses
+}
+
+// bruteForceModels returns the models of the CNF by enumerating all the
+// assignments of its variables, as binary strings (see modelString).
+func bruteForceModels(nVars int, clauses [][]Literal) map[string]bool {
+	models := map[string]bool{}
+	model := make([]bool, nVars)
+	for mask := 0; mask < 1<<nVars; mask++ {
+		for v := range model {
+			model[v] = mask&(1<<v) != 0
+		}
+		if VerifyModel(clauses, model) < 0 {
+			models[modelString(model)] = true
+		}
+	}
+	return models
+}
+
+func modelString(model []bool) string {
+	b := make([]byte, len(model))
+	for i, v := range model {
+		b[i] = '0'
+		if v {
+			b[i] = '1'
+		}
+	}
+	return string(b)
+}
+
+// FuzzSolve cross-checks the answer and the models found by the solver on
+// small random CNFs against an exhaustive enumeration of their assignments.
+func FuzzSolve(f *testing.F) {
+	f.Add([]byte{2, 1, 0, 1, 1, 0x80, 0x81})
+	f.Add([]byte{0, 0, 0, 0, 0x80})
+	f.Add([]byte{9, 2, 1, 2, 3, 2, 0x81, 0x82, 0x83, 3, 4, 5, 6, 7, 1, 0x84})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		nVars, clauses := decodeCNF(data)
+		if nVars == 0 {
+			return
+		}
+		want := bruteForceModels(nVars, clauses)
+
+		s, err := NewSolver(
+			WithPhaseSaving(len(data)%2 == 0),
+			WithLearning(LearningScheme(len(data)/2%4)),
+			WithExtraLearnts(ExtraLearnts{MaxSize: len(data) / 6 % 2 * 8, MaxLBD: 4}),
+			WithBinaryStrengthenLBD(len(data)/12%2*6),
+			WithProbing(len(data)/24%2 == 0),
+			WithTernaryResolvents(len(data)/48%2*100),
+			WithVerbosity(0),
+		)
+		if err != nil {
+			t.Fatalf("NewSolver(): want no error, got %s", err)
+		}
+		for v := 0; v < nVars; v++ {
+			s.AddVariable()
+		}
+		for _, c := range clauses {
+			s.AddClause(slices.Clone(c))
+		}
+
+		wantStatus := Lift(len(want) > 0)
+		if got := s.Solve().Status; got != wantStatus {
+			t.Fatalf("Solve(): want %s, got %s", wantStatus, got)
+		}
+		if wantStatus == True {
+			if c := VerifyModel(clauses, s.Model()); c >= 0 {
+				t.Fatalf("Model(): violates clause %d", c)
+			}
+		}
+
+		got := map[string]bool{}
+		s.EnumerateModels(EnumerateOptions{OnModel: func(model []bool) bool {
+			if got[modelString(model)] {
+				t.Fatalf("EnumerateModels(): model %s found twice", modelString(model))
+			}
+			got[modelString(model)] = true
+			return true
+		}})
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("EnumerateModels(): mismatch (-want, +got):\n%s", diff)
+		}
+	})
+}
+
+func TestTierStatistics(t *testing.T) {
+	s := NewDefaultSolver()
+	addPigeonhole(s, 6)
+	s.Solve()
+
+	var visits, guards uint64
+	for _, ts := range s.Statistics.Tiers {
+		visits += ts.Visits
+		guards += ts.Guards
+	}
+	if guards != s.Statistics.Guards || guards == 0 {
+		t.Errorf("guards: want %d (total guard hits), got %d", s.Statistics.Guards, guards)
+	}
+	if want := s.Statistics.Propagations - s.Statistics.Guards; visits != want {
+		t.Errorf("visits: want %d (propagations without guard hits), got %d", want, visits)
+	}
+	if s.Statistics.Tiers[TierProblem].Implied == 0 || s.Statistics.Tiers[TierLocal].Implied == 0 {
+		t.Errorf("implied: want literals implied by problem and local clauses, got %+v", s.Statistics.Tiers)
+	}
+}
+
+func TestSetTier(t *testing.T) {
+	s := NewDefaultSolver()
+	for i := 0; i < 3; i++ {
+		s.AddVariable()
+	}
+	c, _ := NewClause(s, []Literal{PositiveLiteral(0), PositiveLiteral(1), PositiveLiteral(2)}, false)
+
+	s.setTier(c, TierCore)
+
+	found := 0
+	for _, l := range c.literals[:2] {
+		for _, w := range s.watchers[l.Opposite()] {
+			if w.clause != c {
+				continue
+			}
+			found++
+			if w.tier != TierCore {
+				t.Errorf("watcher of %s: want tier %s, got %s", l.Opposite(), TierCore, w.tier)
+			}
+		}
+	}
+	if found != 2 {
+		t.Errorf("watchers: want 2, got %d", found)
+	}
+	if size := unsafe.Sizeof(watcher{}); size != 16 {
+		t.Errorf("watcher size: want 16 bytes, got %d", size)
+	}
+}
+
+func TestStatistics_decisions(t *testing.T) {
+	s := NewDefaultSolver()
+	for i := 0; i < 5; i++ {
+		s.AddVariable()
+	}
+
+	if status := s.Solve().Status; status != True {
+		t.Fatalf("Solve(): want true, got %s", status)
+	}
+	if got := s.Statistics.Decisions; got != 5 {
+		t.Errorf("Solve(): want 5 decisions, got %d", got)
+	}
+
+	if status := s.SolveWithAssumptions([]Literal{PositiveLiteral(0)}).Status; status != True {
+		t.Fatalf("SolveWithAssumptions(): want true, got %s", status)
+	}
+	if got := s.Statistics.Decisions; got != 4 {
+		t.Errorf("SolveWithAssumptions(): want 4 decisions, got %d", got)
+	}
+}
+
+func TestStatistics_json(t *testing.T) {
+	s := NewDefaultSolver()
+	addPigeonhole(s, 6)
+	s.Solve()
+
+	data, err := json.Marshal(s.Statistics)
+	if err != nil {
+		t.Fatalf("json.Marshal(): %s", err)
+	}
+	var got struct {
+		Conflicts    uint64  `json:"conflicts"`
+		AvgFastLBD   float64 `json:"avg_fast_lbd"`
+		AvgLearntLBD float64 `json:"avg_learnt_lbd"`
+		AvgTrail     float64 `json:"avg_trail"`
+		Tiers        []struct {
+			Visits uint64 `json:"visits"`
+		} `json:"tiers"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal(%s): %s", data, err)
+	}
+
+	if got.Conflicts != s.Statistics.Conflicts {
+		t.Errorf("conflicts: want %d, got %d", s.Statistics.Conflicts, got.Conflicts)
+	}
+	if got.AvgFastLBD != s.Statistics.AvgFastLBD.Val() || got.AvgFastLBD == 0 {
+		t.Errorf("avg_fast_lbd: want %v, got %v", s.Statistics.AvgFastLBD.Val(), got.AvgFastLBD)
+	}
+	if got.AvgLearntLBD != s.Statistics.AvgLearntLBD.Val() {
+		t.Errorf("avg_learnt_lbd: want %v, got %v", s.Statistics.AvgLearntLBD.Val(), got.AvgLearntLBD)
+	}
+	if got.AvgTrail == 0 {
+		t.Errorf("avg_trail: want a positive average, got 0")
+	}
+	if len(got.Tiers) != int(numTiers) || got.Tiers[TierProblem].Visits == 0 {
+		t.Errorf("tiers: want %d tiers with visited problem clauses, got %+v", numTiers, got.Tiers)
+	}
+}
+
+func TestDemoteCores(t *testing.T) {
+	s, err := NewSolver(WithReductions(Reductions{Fraction: 0.5, CoreLBD: 3, DemoteAfter: 2, Interval: 20000}))
+	if err != nil {
+		t.Fatalf("NewSolver(): want no error, got %s", err)
+	}
+	for i := 0; i < 4; i++ {
+		s.AddVariable()
+	}
+	for i := 0; i < 2; i++ {
+		c, _ := NewClause(s, []Literal{PositiveLiteral(i), PositiveLiteral(i + 2)}, false)
+		c.statusMask |= statusLearnt
+		c.lbd = 2
+		s.setTier(c, TierCore)
+		s.cores = append(s.cores, c)
+		s.Statistics.TotalCoreLBD += 2
+	}
+	used, unused := s.cores[0], s.cores[1]
+
+	for i := 0; i < 2; i++ {
+		used.used = true
+		s.demoteCores()
+	}
+
+	if !slices.Equal([]*Clause{used}, s.cores) {
+		t.Errorf("cores: want only the used clause, got %d clauses", len(s.cores))
+	}
+	if !slices.Equal([]*Clause{unused}, s.locals) {
+		t.Errorf("locals: want only the unused clause, got %d clauses", len(s.locals))
+	}
+	if unused.tier != TierLocal || unused.lbd != 2 {
+		t.Errorf("demoted clause: want tier %s and LBD 2, got tier %s and LBD %d", TierLocal, unused.tier, unused.lbd)
+	}
+	if s.Statistics.Demotions != 1 || s.Statistics.TotalCoreLBD != 2 {
+		t.Errorf("statistics: want 1 demotion and a total core LBD of 2, got %d and %d", s.Statistics.Demotions, s.Statistics.TotalCoreLBD)
+	}
+
+	// The demoted clause is only promoted again once it is used.
+	used.used = true
+	s.ReduceDB()
+	if unused.tier != TierLocal {
+		t.Errorf("unused demoted clause: want tier %s, got %s", TierLocal, unused.tier)
+	}
+	used.used = true
+	unused.used = true
+	s.ReduceDB()
+	if unused.tier != TierCore || len(s.cores) != 2 {
+		t.Errorf("used demoted clause: want tier %s and 2 cores, got %s and %d", TierCore, unused.tier, len(s.cores))
+	}
+}
+
+func TestSortForReduction(t *testing.T) {
+	a := &Clause{activity: 1, lbd: 8}
+	b := &Clause{activity: 2, lbd: 3}
+	c := &Clause{activity: 3, lbd: 9}
+	d := &Clause{activity: 4, lbd: 8}
+
+	testCases := []struct {
+		policy ReducePolicy
+		want   []*Clause
+	}{
+		{ReduceActivity, []*Clause{a, b, c, d}},
+		{ReduceLBD, []*Clause{c, a, d, b}},
+		{ReduceHybrid, []*Clause{a, c, b, d}}, // ranks: a 1, c 2, b 4, d 5
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.policy.String(), func(t *testing.T) {
+			got := []*Clause{d, c, b, a}
+			sortForReduction(got, tc.policy)
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("sortForReduction(): want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRestartGlucose(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		margin       float64
+		wantRestarts bool
+	}{
+		{"restart when above average", 1, true},
+		{"margin never reached", 1e9, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s, err := NewSolver(WithRestarts(Restarts{Policy: RestartGlucose, Initial: 50, Increment: tc.margin}))
+			if err != nil {
+				t.Fatalf("NewSolver(): want no error, got %s", err)
+			}
+			addPigeonhole(s, 7)
+
+			if got := s.Solve().Status; got != False {
+				t.Errorf("Solve(): want false, got %s", got)
+			}
+			if got := s.Statistics.Restarts > 1; got != tc.wantRestarts {
+				t.Errorf("restarts: want restarts %t, got %d searches", tc.wantRestarts, s.Statistics.Restarts)
+			}
+		})
+	}
+}
+
+func TestWithReductions(t *testing.T) {
+	for _, policy := range []ReducePolicy{ReduceActivity, ReduceLBD, ReduceHybrid} {
+		// Reduce early and often.
+		s, err := NewSolver(WithReductions(Reductions{Policy: policy, Fraction: 0.9, CoreLBD: 2, Interval: 100}))
+		if err != nil {
+			t.Fatalf("NewSolver(): want no error, got %s", err)
+		}
+		addPigeonhole(s, 7)
+
+		if got := s.Solve().Status; got != False {
+			t.Errorf("Solve() with %s reductions: want %s, got %s", policy, False, got)
+		}
+	}
+}
+
+func TestWithLearning(t *testing.T) {
+	for _, ls := range []LearningScheme{LearnFirstUIP, LearnLastUIP, LearnAllUIP, LearnDIP} {
+		proof := &bytes.Buffer{}
+		s, err := NewSolver(WithLearning(ls), WithProof(proof, ProofText), WithVerbosity(0))
+		if err != nil {
+			t.Fatalf("NewSolver(): want no error, got %s", err)
+		}
+		addPigeonhole(s, 5)
+		clauses := [][]int{}
+		for _, c := range s.constraints {
+			clauses = append(clauses, LiteralsToDIMACS(c.literals))
+		}
+
+		if got := s.Solve().Status; got != False {
+			t.Fatalf("Solve() with %s learning: want %s, got %s", ls, False, got)
+		}
+		if ls == LearnDIP && s.Statistics.ExtraLearnts == 0 {
+			t.Errorf("Statistics.ExtraLearnts with %s learning: want DIP clauses, got 0", ls)
+		}
+		t.Run(ls.String(), func(t *testing.T) {
+			checkRUPProof(t, clauses, proof.String())
+		})
+	}
+}
+
+func TestBumpReasonSide(t *testing.T) {
+	s := newChainSolver(5)
+	s.newDecisionLevel()
+	s.enqueue(PositiveLiteral(0), nil)
+	if c := s.Propagate(); c != nil {
+		t.Fatalf("Propagate(): want no conflict, got %s", c)
+	}
+
+	// Only x2, whose literal is in the reason of x3, must be bumped. Variable
+	// x4 is in the clause and x1 is in the reason of x2, which is not.
+	s.bumpReasonSide([]Literal{PositiveLiteral(4), NegativeLiteral(3)})
+	for v, want := range []bool{false, false, true, false, false} {
+		if got := s.order.heap.scores[v] > 0; got != want {
+			t.Errorf("x%d bumped: want %t, got %t", v, want, got)
+		}
+	}
+}
+
+func TestStrengthenBinary(t *testing.T) {
+	s := NewDefaultSolver()
+	for i := 0; i < 4; i++ {
+		s.AddVariable()
+	}
+	u, a, b, c := PositiveLiteral(0), PositiveLiteral(1), PositiveLiteral(2), PositiveLiteral(3)
+	s.AddClause([]Literal{u, a.Opposite()})
+	s.AddClause([]Literal{c, u, b.Opposite()}) // not binary
+
+	for _, l := range []Literal{a, b, c, u} {
+		s.newDecisionLevel()
+		s.enqueue(l.Opposite(), nil)
+	}
+
+	got := s.strengthenBinary([]Literal{u, a, b, c})
+	want := []Literal{u, b, c}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("strengthenBinary(): mismatch (-want, +got):\n%s", diff)
+	}
+	if s.Statistics.StrengthenedLiterals != 1 {
+		t.Errorf("Statistics.StrengthenedLiterals: want 1, got %d", s.Statistics.StrengthenedLiterals)
+	}
+}
+
+func TestWithBinaryStrengthenLBD(t *testing.T) {
+	proof := &bytes.Buffer{}
+	s, err := NewSolver(WithBinaryStrengthenLBD(100), WithProof(proof, ProofText))
+	if err != nil {
+		t.Fatalf("NewSolver(): want no error, got %s", err)
+	}
+	addPigeonhole(s, 5)
+	clauses := [][]int{}
+	for _, c := range s.constraints {
+		clauses = append(clauses, LiteralsToDIMACS(c.literals))
+	}
+
+	if got := s.Solve().Status; got != False {
+		t.Fatalf("Solve(): want %s, got %s", False, got)
+	}
+	if s.Statistics.StrengthenedLiterals == 0 {
+		t.Errorf("Statistics.StrengthenedLiterals: want strengthened clauses, got 0")
+	}
+	checkRUPProof(t, clauses, proof.String())
+}
+
+func TestProbe(t *testing.T) {
+	s := NewDefaultSolver()
+	for i := 0; i < 5; i++ {
+		s.AddVariable()
+	}
+	x := func(v int) Literal { return PositiveLiteral(v) }
+	s.AddClause([]Literal{x(0).Opposite(), x(1)})
+	s.AddClause([]Literal{x(0).Opposite(), x(2)})
+	s.AddClause([]Literal{x(1).Opposite(), x(2).Opposite(), x(3)})
+	s.AddClause([]Literal{x(1).Opposite(), x(2).Opposite(), x(3).Opposite()})
+	s.AddClause([]Literal{x(3).Opposite(), x(4)}) // x3 and x4 are equivalent
+	s.AddClause([]Literal{x(4).Opposite(), x(3)})
+
+	if !s.Probe() {
+		t.Fatalf("Probe(): want true, got false")
+	}
+	if got := s.VarValue(0); got != False {
+		t.Errorf("x0 (failed literal): want %s, got %s", False, got)
+	}
+	if s.Statistics.FailedLiterals == 0 || s.Statistics.Equivalences == 0 {
+		t.Errorf("statistics: want failed literals and equivalences, got %+v", s.Statistics)
+	}
+	if s.decisionLevel() != 0 {
+		t.Errorf("decision level: want 0, got %d", s.decisionLevel())
+	}
+}
+
+func TestProbe_phases(t *testing.T) {
+	s := newChainSolver(5)
+	for v := range s.order.phases {
+		s.order.phases[v] = False // probing assigns the variables to true
+	}
+	want := slices.Clone(s.order.phases)
+
+	if !s.Probe() {
+		t.Fatalf("Probe(): want true, got false")
+	}
+	if s.Statistics.Probes == 0 {
+		t.Fatalf("Probe(): want probes, got none")
+	}
+	if diff := cmp.Diff(want, s.order.phases); diff != "" {
+		t.Errorf("phases: mismatch (+want, -got):\n%s", diff)
+	}
+}
+
+func TestProbe_budget(t *testing.T) {
+	s := newChainSolver(5)
+	s.probeBudget = 0
+
+	if !s.Probe() {
+		t.Fatalf("Probe(): want true, got false")
+	}
+	if s.Statistics.Probes != 0 {
+		t.Errorf("Probe(): want no probes with an empty budget, got %d", s.Statistics.Probes)
+	}
+}
+
+func TestWithProbing(t *testing.T) {
+	proof := &bytes.Buffer{}
+	s, err := NewSolver(WithProbing(true), WithProof(proof, ProofText))
+	if err != nil {
+		t.Fatalf("NewSolver(): want no error, got %s", err)
+	}
+	addPigeonhole(s, 5)
+	clauses := [][]int{}
+	for _, c := range s.constraints {
+		clauses = append(clauses, LiteralsToDIMACS(c.literals))
+	}
+
+	if got := s.Solve().Status; got != False {
+		t.Fatalf("Solve(): want %s, got %s", False, got)
+	}
+	if s.Statistics.Probes == 0 {
+		t.Errorf("Statistics.Probes: want probes, got 0")
+	}
+	checkRUPProof(t, clauses, proof.String())
+}
+
+func TestTernaryResolution(t *testing.T) {
+	s, err := NewSolver(WithTernaryResolvents(10))
+	if err != nil {
+		t.Fatalf("NewSolver(): want no error, got %s", err)
+	}
+	for i := 0; i < 6; i++ {
+		s.AddVariable()
+	}
+	a, b, c, d, v, w := PositiveLiteral(0), PositiveLiteral(1), PositiveLiteral(2), PositiveLiteral(3), PositiveLiteral(4), PositiveLiteral(5)
+	s.AddClause([]Literal{a, b, v})
+	s.AddClause([]Literal{a, b, v.Opposite()}) // resolvent (a b) subsumes both
+	s.AddClause([]Literal{c, d, w})
+	s.AddClause([]Literal{c, a, w.Opposite()}) // resolvent (c d a) is learnt
+
+	if !s.TernaryResolution() {
+		t.Fatalf("TernaryResolution(): want true, got false")
+	}
+
+	got := [][]int{}
+	for _, cl := range s.constraints {
+		got = append(got, LiteralsToDIMACS(cl.literals))
+	}
+	want := [][]int{{3, 4, 6}, {3, 1, -6}, {1, 2}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("constraints: mismatch (-want, +got):\n%s", diff)
+	}
+	if len(s.locals) != 1 || !containsAll(s.locals[0].literals, []Literal{a, c, d}) {
+		t.Errorf("locals: want the learnt resolvent (a c d), got %v", s.locals)
+	}
+	if s.Statistics.TernaryResolvents != 2 || s.Statistics.SubsumedClauses != 2 {
+		t.Errorf("statistics: want 2 resolvents and 2 subsumed clauses, got %d and %d",
+			s.Statistics.TernaryResolvents, s.Statistics.SubsumedClauses)
+	}
+}
+
+func TestWithTernaryResolvents(t *testing.T) {
+	proof := &bytes.Buffer{}
+	s, err := NewSolver(WithTernaryResolvents(100), WithProof(proof, ProofText))
+	if err != nil {
+		t.Fatalf("NewSolver(): want no error, got %s", err)
+	}
+	for i := 0; i < 3; i++ {
+		s.AddVariable()
+	}
+	clauses := [][]int{}
+	for signs := 0; signs < 8; signs++ { // every clause on the 3 variables
+		clause := []int{}
+		for v := 0; v < 3; v++ {
+			clause = append(clause, (v+1)*(1-2*(signs>>v&1)))
+		}
+		clauses = append(clauses, clause)
+		s.AddClause(LiteralsFromDIMACS(clause))
+	}
+
+	if got := s.Solve().Status; got != False {
+		t.Fatalf("Solve(): want %s, got %s", False, got)
+	}
+	if s.Statistics.SubsumedClauses == 0 {
+		t.Errorf("Statistics.SubsumedClauses: want subsumed clauses, got 0")
+	}
+	checkRUPProof(t, clauses, proof.String())
+}
+
+func TestWithExtraLearnts(t *testing.T) {
+	proof := &bytes.Buffer{}
+	s, err := NewSolver(WithExtraLearnts(ExtraLearnts{MaxSize: 30, MaxLBD: 10}), WithProof(proof, ProofText))
+	if err != nil {
+		t.Fatalf("NewSolver(): want no error, got %s", err)
+	}
+	addPigeonhole(s, 5)
+	clauses := [][]int{}
+	for _, c := range s.constraints {
+		clauses = append(clauses, LiteralsToDIMACS(c.literals))
+	}
+
+	if got := s.Solve().Status; got != False {
+		t.Fatalf("Solve(): want %s, got %s", False, got)
+	}
+	if s.Statistics.ExtraLearnts == 0 {
+		t.Errorf("Statistics.ExtraLearnts: want extra learnt clauses, got 0")
+	}
+	checkRUPProof(t, clauses, proof.String())
+}
+
+func TestReduceGeometricLimit(t *testing.T) {
+	reductions := DefaultOptions.Reductions
+	reductions.Schedule = ReduceGeometricLimit
+	var reduced int
+	s, err := NewSolver(WithReductions(reductions), WithReduceHook(func() { reduced++ }))
+	if err != nil {
+		t.Fatalf("NewSolver(): want no error, got %s", err)
+	}
+	addPigeonhole(s, 8)
+
+	if got := s.Solve().Status; got != False {
+		t.Errorf("Solve(): want %s, got %s", False, got)
+	}
+	if reduced == 0 {
+		t.Errorf("reductions: want at least one, got none")
+	}
+	if want := float64(s.NumConstraints()) / 3; s.learntsLimit.max <= want {
+		t.Errorf("learnts limit: want more than %g, got %g", want, s.learntsLimit.max)
+	}
+}
@@ -0,0 +1,46 @@
+package sat
+
+// StopReason is the reason why the last solve call stopped its search before
+// deciding the problem.
+type StopReason uint8
+
+const (
+	// NotStopped means that the search was not stopped (i.e. the problem was
+	// decided).
+	NotStopped StopReason = iota
+	StoppedByInterrupt
+	StoppedByTimeout
+	StoppedByMaxConflicts
+	StoppedByMemory
+	StoppedByConflictBudget
+	StoppedByPropagationBudget
+
+	// Stop reasons specific to EnumerateModels.
+	StoppedByModelLimit
+	StoppedByCallback
+)
+
+func (r StopReason) String() string {
+	switch r {
+	case NotStopped:
+		return "not stopped"
+	case StoppedByInterrupt:
+		return "interrupted"
+	case StoppedByTimeout:
+		return "timeout"
+	case StoppedByMaxConflicts:
+		return "conflict limit"
+	case StoppedByMemory:
+		return "memory limit"
+	case StoppedByConflictBudget:
+		return "conflict budget"
+	case StoppedByPropagationBudget:
+		return "propagation budget"
+	case StoppedByModelLimit:
+		return "model limit"
+	case StoppedByCallback:
+		return "stopped by callback"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,145 @@
+package sat
+
+import "slices"
+
+// TernaryResolution resolves the pairs of ternary problem clauses on each
+// variable and adds the resolvents that have at most three literals, until
+// Options.TernaryResolvents resolvents were added. Resolvents are only kept
+// if they simplify the clause DB right away:
+//
+//   - a resolvent that subsumes problem clauses replaces them, which is
+//     always the case of binary resolvents as they subsume both antecedents;
+//   - other ternary resolvents are added as learnt clauses if they are not
+//     already in the clause DB, as they can propagate before their
+//     antecedents do.
+//
+// TernaryResolution must be called at the root level. It returns false if the
+// problem is unsatisfiable.
+func (s *Solver) TernaryResolution() bool {
+	if s.decisionLevel() != 0 {
+		return !s.unsat
+	}
+	if !s.Simplify() {
+		return false
+	}
+
+	occurs := s.occurrences()
+	added := 0
+	for v := 0; v < s.NumVariables() && added < s.ternaryResolvents; v++ {
+		pos := ternaryClauses(occurs.clauses(PositiveLiteral(v)))
+		neg := ternaryClauses(occurs.clauses(NegativeLiteral(v)))
+		for _, c := range pos {
+			for _, d := range neg {
+				if added >= s.ternaryResolvents {
+					break
+				}
+				if c.statusMask&statusDeleted != 0 || d.statusMask&statusDeleted != 0 {
+					continue
+				}
+				if !s.resolve(c, d, v) || len(s.tmpResolvent) > 3 {
+					continue
+				}
+				if s.addTernaryResolvent() {
+					added++
+				}
+			}
+		}
+	}
+
+	s.constraints = slices.DeleteFunc(s.constraints, func(c *Clause) bool {
+		return c.statusMask&statusDeleted != 0
+	})
+	return !s.unsat
+}
+
+// ternaryClauses returns the ternary clauses of the given occurrence list.
+func ternaryClauses(clauses []*Clause) []*Clause {
+	ternaries := []*Clause{}
+	for _, c := range clauses {
+		if len(c.literals) == 3 {
+			ternaries = append(ternaries, c)
+		}
+	}
+	return ternaries
+}
+
+// resolve stores in tmpResolvent the resolvent of clauses c and d on variable
+// v, which c contains positively and d negatively. It returns false if the
+// resolvent is a tautology.
+func (s *Solver) resolve(c, d *Clause, v int) bool {
+	s.tmpResolvent = s.tmpResolvent[:0]
+	for _, l := range c.literals {
+		if l.VarID() != v {
+			s.tmpResolvent = append(s.tmpResolvent, l)
+		}
+	}
+	n := len(s.tmpResolvent)
+	for _, l := range d.literals {
+		if l.VarID() == v || slices.Contains(s.tmpResolvent[:n], l) {
+			continue
+		}
+		if slices.Contains(s.tmpResolvent[:n], l.Opposite()) {
+			return false
+		}
+		s.tmpResolvent = append(s.tmpResolvent, l)
+	}
+	return true
+}
+
+// addTernaryResolvent adds the resolvent stored in tmpResolvent to the clause
+// DB if it simplifies it (see TernaryResolution). It returns true if the
+// resolvent was added.
+func (s *Solver) addTernaryResolvent() bool {
+	r := s.tmpResolvent
+
+	// The problem clauses that contain r are in the occurrence list of each
+	// literal of r, hence in the shortest of these lists.
+	occurs := s.occurrences()
+	shortest := r[0]
+	for _, l := range r[1:] {
+		if occurs.count(l) < occurs.count(shortest) {
+			shortest = l
+		}
+	}
+	subsumed := 0
+	for _, e := range occurs.clauses(shortest) {
+		if !containsAll(e.literals, r) {
+			continue
+		}
+		if len(e.literals) == len(r) {
+			return false // already in the clause DB
+		}
+		subsumed++
+	}
+
+	if subsumed == 0 {
+		s.addLearnt(r, len(r))
+	} else {
+		// The resolvent must be added to the proof before the clauses it was
+		// derived from are deleted.
+		if s.proof != nil {
+			s.proof.add(r)
+		}
+		for _, e := range occurs.clauses(shortest) {
+			if e.statusMask&statusDeleted == 0 && containsAll(e.literals, r) {
+				e.Delete(s)
+			}
+		}
+		c, _ := NewClause(s, r, false)
+		s.constraints = append(s.constraints, c)
+		occurs.add(c)
+		s.Statistics.SubsumedClauses += uint64(subsumed)
+	}
+	s.Statistics.TernaryResolvents++
+	return true
+}
+
+// containsAll returns true if all the literals of sub are in literals.
+func containsAll(literals []Literal, sub []Literal) bool {
+	for _, l := range sub {
+		if !slices.Contains(literals, l) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package sat
+
+import "fmt"
+
+// Tier is the class of a clause in the clause DB.
+type Tier uint8
+
+const (
+	// TierProblem contains the problem clauses.
+	TierProblem Tier = iota
+
+	// TierCore contains the learnt clauses with a low LBD, which are never
+	// deleted by the reductions of the clause DB. Core clauses that remain
+	// unused are moved back to TierLocal (see Reductions.DemoteAfter).
+	TierCore
+
+	// TierLocal contains the other learnt clauses, half of which are deleted
+	// by each reduction of the clause DB.
+	TierLocal
+
+	numTiers
+)
+
+func (t Tier) String() string {
+	switch t {
+	case TierProblem:
+		return "problem"
+	case TierCore:
+		return "core"
+	case TierLocal:
+		return "local"
+	default:
+		return fmt.Sprintf("Tier(%d)", t)
+	}
+}
+
+// TierStatistics are the propagation statistics of the clauses of a tier.
+type TierStatistics struct {
+	// Number of times the clauses were visited when propagating, that is
+	// the visited watchers whose guard was not true.
+	Visits uint64 `json:"visits"`
+
+	// Number of visited watchers whose guard was true, which does not require
+	// to propagate the clause (see Statistics.Guards).
+	Guards uint64 `json:"guards"`
+
+	// Number of literals implied by the clauses.
+	Implied uint64 `json:"implied"`
+}
+
+// GuardHitRate returns the fraction of the visited watchers whose guard was
+// true (0 if no watcher was visited).
+func (ts *TierStatistics) GuardHitRate() float64 {
+	if ts.Visits+ts.Guards == 0 {
+		return 0
+	}
+	return float64(ts.Guards) / float64(ts.Visits+ts.Guards)
+}
+
+// setTier moves clause c to tier t. This happens on clause DB reductions only,
+// which is when the tier kept by the clause's two watchers is updated.
+func (s *Solver) setTier(c *Clause, t Tier) {
+	c.tier = t
+	for _, l := range c.literals[:2] {
+		ws := s.watchers[l.Opposite()]
+		for i := range ws {
+			if ws[i].clause == c {
+				ws[i].tier = t
+				break
+			}
+		}
+	}
+}
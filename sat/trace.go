@@ -0,0 +1,56 @@
+package sat
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// conflictRecord is the trace of a conflict analysis, written as a JSON line.
+// Clauses are written as arrays of DIMACS literals.
+type conflictRecord struct {
+	Conflict       uint64  `json:"conflict"`        // number of the conflict in its solve call, from 1
+	Level          int     `json:"level"`           // decision level of the conflict
+	Clause         []int   `json:"clause"`          // conflicting clause
+	Antecedents    [][]int `json:"antecedents"`     // reasons resolved with it, in order
+	Learnt         []int   `json:"learnt"`          // learnt clause, asserting literal first
+	LBD            int     `json:"lbd"`             // LBD of the learnt clause
+	BacktrackLevel int     `json:"backtrack_level"` // level to which the search backtracks
+}
+
+// conflictTracer writes the trace of the analysis of the first conflicts (see
+// Options.ConflictTrace).
+type conflictTracer struct {
+	enc       *json.Encoder
+	remaining int
+	record    conflictRecord // record of the conflict being analyzed
+}
+
+func newConflictTracer(w io.Writer, max int) *conflictTracer {
+	return &conflictTracer{enc: json.NewEncoder(w), remaining: max}
+}
+
+// begin starts the trace of the analysis of the conflicting clause.
+func (t *conflictTracer) begin(s *Solver, conflict *Clause) {
+	t.record = conflictRecord{
+		Conflict:    s.Statistics.Conflicts,
+		Level:       s.decisionLevel(),
+		Clause:      LiteralsToDIMACS(conflict.literals),
+		Antecedents: [][]int{},
+	}
+}
+
+// resolve adds the reason clause resolved by the analysis to the trace.
+func (t *conflictTracer) resolve(c *Clause) {
+	t.record.Antecedents = append(t.record.Antecedents, LiteralsToDIMACS(c.literals))
+}
+
+// end completes the trace with the analysis' result and writes it. It returns
+// false once the maximum number of traced conflicts is reached.
+func (t *conflictTracer) end(learnt []Literal, lbd int, backtrackLevel int) bool {
+	t.record.Learnt = LiteralsToDIMACS(learnt)
+	t.record.LBD = lbd
+	t.record.BacktrackLevel = backtrackLevel
+	t.enc.Encode(t.record) // errors are ignored, see Options.ConflictTrace
+	t.remaining--
+	return t.remaining > 0
+}
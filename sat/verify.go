@@ -0,0 +1,25 @@
+package sat
+
+// VerifyModel returns the index of the first clause that is not satisfied by
+// the model, or -1 if the model satisfies all the clauses. Variables beyond
+// the end of the model are considered unassigned and their literals do not
+// satisfy any clause.
+func VerifyModel(clauses [][]Literal, model []bool) int {
+	for i, c := range clauses {
+		if !isSatisfied(c, model) {
+			return i
+		}
+	}
+	return -1
+}
+
+// isSatisfied returns true if the clause has a literal that is true in the
+// model.
+func isSatisfied(clause []Literal, model []bool) bool {
+	for _, l := range clause {
+		if v := l.VarID(); v < len(model) && model[v] == l.IsPositive() {
+			return true
+		}
+	}
+	return false
+}
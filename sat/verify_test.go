@@ -0,0 +1,28 @@
+package sat
+
+import "testing"
+
+func TestVerifyModel(t *testing.T) {
+	clauses := [][]Literal{
+		{PositiveLiteral(0), PositiveLiteral(1)},
+		{NegativeLiteral(0), PositiveLiteral(2)},
+		{NegativeLiteral(1), NegativeLiteral(2)},
+	}
+
+	testCases := []struct {
+		desc  string
+		model []bool
+		want  int
+	}{
+		{"model", []bool{true, false, true}, -1},
+		{"first clause violated", []bool{false, false, true}, 0},
+		{"last clause violated", []bool{true, true, true}, 2},
+		{"partial model", []bool{true, false}, 1},
+	}
+
+	for _, tc := range testCases {
+		if got := VerifyModel(clauses, tc.model); got != tc.want {
+			t.Errorf("VerifyModel(%s): want %d, got %d", tc.desc, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+
+	"github.com/rhartert/yass/gen"
+	"github.com/rhartert/yass/parsers"
+	"github.com/rhartert/yass/sat"
+)
+
+var flagMapOut = flag.String(
+	"map_out",
+	"",
+	"write the mapping of the original variables to the scrambled literals to this file, in the format of -var_map",
+)
+
+// runScramble writes to stdout an instance equivalent to the given one up to
+// a random renaming of its variables, random polarities, and a random order of
+// its clauses and literals (see gen.Scramble). Solving scrambled copies of an
+// instance, e.g. with the bench command, measures how robust the solver is to
+// the syntactic presentation of the instance.
+func runScramble(cfg *config) (int, error) {
+	cnf := &parsers.CNF{}
+	if err := loadInstance(cfg, cnf); err != nil {
+		return exitUnknown, fmt.Errorf("could not load instance: %s", err)
+	}
+
+	scrambled, mapping := gen.Scramble(rand.New(rand.NewSource(cfg.seed)), cnf)
+	if cfg.mapOut != "" {
+		if err := writeVarMap(cfg.mapOut, mapping); err != nil {
+			return exitUnknown, fmt.Errorf("could not write mapping: %s", err)
+		}
+	}
+	return exitUnknown, parsers.WriteDIMACS(os.Stdout, scrambled)
+}
+
+// writeVarMap writes the mapping as lines "x y" stating that literal y of the
+// scrambled instance is variable x of the original one.
+func writeVarMap(filename string, mapping []sat.Literal) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(f)
+	for v, l := range mapping {
+		fmt.Fprintf(bw, "%d %d\n", v+1, l.ToDIMACS())
+	}
+	if err := bw.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
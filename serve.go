@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/rhartert/yass/parsers"
+	"github.com/rhartert/yass/sat"
+)
+
+// maxInstanceBytes is the maximum size of the instances sent to the server.
+const maxInstanceBytes = 256 << 20
+
+// defaultServeTimeout is the timeout of the requests if neither the -timeout
+// flag nor the request sets one.
+const defaultServeTimeout = time.Minute
+
+// runServe serves the solver over HTTP. Instances are sent to POST /solve,
+// either as the request body or as the "instance" file of a multipart form,
+// possibly compressed, and the result is returned as the JSON object printed
+// by the solve command with -json. The search is configured by the command's
+// flags, and the timeout can be overridden per request with the "timeout"
+// query parameter (e.g. /solve?timeout=10s). At most -workers instances are
+// solved at the same time, other requests wait for a search to complete.
+func runServe(cfg *config) (int, error) {
+	if cfg.workers < 1 {
+		return exitUnknown, fmt.Errorf("the number of workers must be positive, got %d", cfg.workers)
+	}
+	log.Printf("listening on %s", cfg.listenAddr)
+	return exitUnknown, http.ListenAndServe(cfg.listenAddr, serveHandler(cfg))
+}
+
+// serveHandler returns the handler of the serve command.
+func serveHandler(cfg *config) http.Handler {
+	slots := make(chan struct{}, cfg.workers)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /solve", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+		case <-r.Context().Done():
+			return // the client went away while waiting
+		}
+		serveSolve(cfg, w, r)
+	})
+	return mux
+}
+
+// serveSolve solves the instance of the request.
+func serveSolve(cfg *config, w http.ResponseWriter, r *http.Request) {
+	reqCfg := *cfg
+	reqCfg.json = true // no logs
+	reqCfg.verbosity = 0
+	reqCfg.instanceFile = "request"
+	if reqCfg.timeout < 0 {
+		reqCfg.timeout = defaultServeTimeout
+	}
+	if t := r.URL.Query().Get("timeout"); t != "" {
+		timeout, err := time.ParseDuration(t)
+		if err != nil || timeout <= 0 {
+			httpError(w, http.StatusBadRequest, fmt.Errorf("invalid timeout %q: must be a positive duration", t))
+			return
+		}
+		reqCfg.timeout = timeout
+	}
+
+	body, name, err := instanceBody(w, r)
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err)
+		return
+	}
+	defer body.Close()
+	if name != "" {
+		reqCfg.instanceFile = name
+	}
+
+	s, err := sat.NewSolver(sat.WithOptions(solverOptions(&reqCfg)))
+	if err != nil {
+		httpError(w, http.StatusBadRequest, fmt.Errorf("invalid solver configuration: %s", err))
+		return
+	}
+	// Stop the search if the client goes away.
+	stop := context.AfterFunc(r.Context(), s.Interrupt)
+	defer stop()
+
+	tRead := time.Now()
+	in, err := parsers.DecompressAs(body, reqCfg.compression)
+	if err == nil {
+		defer in.Close()
+		err = parsers.LoadDIMACSReaderWithOptions(in, s, parsers.DIMACSOptions{
+			Strict: reqCfg.strict,
+		})
+	}
+	if err != nil {
+		httpError(w, http.StatusBadRequest, fmt.Errorf("could not load instance: %s", err))
+		return
+	}
+
+	tSolve := time.Now()
+	status := s.Solve().Status
+	res := newJSONResult(&reqCfg, s, status, tSolve.Sub(tRead).Seconds(), time.Since(tSolve).Seconds())
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// instanceBody returns the instance sent in the request along with its file
+// name, if any.
+func instanceBody(w http.ResponseWriter, r *http.Request) (io.ReadCloser, string, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxInstanceBytes)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return r.Body, "", nil
+	}
+	f, fh, err := r.FormFile("instance")
+	if err != nil {
+		return nil, "", fmt.Errorf("could not read instance: %s", err)
+	}
+	return f, fh.Filename, nil
+}
+
+// httpError replies to the request with the error as a JSON object.
+func httpError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(struct {
+		Error string `json:"error"`
+	}{err.Error()})
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/rhartert/yass/parsers"
+	"github.com/rhartert/yass/sat"
+)
+
+var flagShrinkOut = flag.String(
+	"out",
+	"shrunk.cnf",
+	"file in which the shrunk instance is written",
+)
+
+var flagPredicateTimeout = flag.Duration(
+	"predicate_timeout",
+	10*time.Second,
+	"timeout of each run of the shrink predicate command (-1 = no timeout)",
+)
+
+// errPredicateTimeout is returned when a run of the predicate command exceeds
+// its timeout.
+var errPredicateTimeout = errors.New("predicate command timed out")
+
+// shrinker minimizes a formula while preserving the behavior of a predicate
+// command, that is its exit code on the original formula.
+type shrinker struct {
+	command  []string
+	tmpFile  string
+	timeout  time.Duration // timeout of each run, none if negative
+	wantCode int
+	runs     int
+}
+
+// exitCode runs the predicate command on the formula and returns its exit
+// code. It returns errPredicateTimeout if the command does not terminate within
+// the shrinker's timeout.
+func (sh *shrinker) exitCode(cnf *parsers.CNF) (int, error) {
+	sh.runs++
+	if err := saveCNF(sh.tmpFile, cnf); err != nil {
+		return 0, err
+	}
+	ctx := context.Background()
+	if sh.timeout >= 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, sh.timeout)
+		defer cancel()
+	}
+	args := append(sh.command[1:len(sh.command):len(sh.command)], sh.tmpFile)
+	err := exec.CommandContext(ctx, sh.command[0], args...).Run()
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		return 0, errPredicateTimeout
+	case err == nil:
+		return 0, nil
+	case errors.As(err, &exitErr):
+		return exitErr.ExitCode(), nil
+	default:
+		return 0, err
+	}
+}
+
+// fails returns true if the predicate command behaves on the formula as it
+// does on the original formula. Runs that time out do not fail.
+func (sh *shrinker) fails(cnf *parsers.CNF) bool {
+	code, err := sh.exitCode(cnf)
+	return err == nil && code == sh.wantCode
+}
+
+// shrinkClauses removes chunks of clauses of decreasing size as long as the
+// formula keeps failing.
+func (sh *shrinker) shrinkClauses(cnf *parsers.CNF) *parsers.CNF {
+	for chunk := (len(cnf.Clauses) + 1) / 2; chunk > 0; chunk /= 2 {
+		for start := 0; start < len(cnf.Clauses); {
+			end := min(start+chunk, len(cnf.Clauses))
+			candidate := &parsers.CNF{NumVars: cnf.NumVars}
+			candidate.Clauses = append(candidate.Clauses, cnf.Clauses[:start]...)
+			candidate.Clauses = append(candidate.Clauses, cnf.Clauses[end:]...)
+			if sh.fails(candidate) {
+				cnf = candidate // the next chunk now starts at start
+			} else {
+				start = end
+			}
+		}
+	}
+	return cnf
+}
+
+// shrinkLiterals removes literals from the clauses one by one as long as the
+// formula keeps failing.
+func (sh *shrinker) shrinkLiterals(cnf *parsers.CNF) *parsers.CNF {
+	for i := range cnf.Clauses {
+		for j := 0; j < len(cnf.Clauses[i]); {
+			c := cnf.Clauses[i]
+			shorter := append(c[:j:j], c[j+1:]...)
+
+			candidate := &parsers.CNF{NumVars: cnf.NumVars}
+			candidate.Clauses = append(candidate.Clauses, cnf.Clauses...)
+			candidate.Clauses[i] = shorter
+			if sh.fails(candidate) {
+				cnf = candidate
+			} else {
+				j++
+			}
+		}
+	}
+	return cnf
+}
+
+// compactVariables renumbers the variables so that unused variables are
+// removed. The original formula is returned if the compacted one does not
+// fail anymore.
+func (sh *shrinker) compactVariables(cnf *parsers.CNF) *parsers.CNF {
+	ids := make([]int, cnf.NumVars)
+	for i := range ids {
+		ids[i] = -1
+	}
+	candidate := &parsers.CNF{}
+	for _, c := range cnf.Clauses {
+		renamed := make([]sat.Literal, len(c))
+		for i, l := range c {
+			if ids[l.VarID()] < 0 {
+				ids[l.VarID()] = candidate.AddVariable()
+			}
+			renamed[i] = sat.PositiveLiteral(ids[l.VarID()])
+			if !l.IsPositive() {
+				renamed[i] = renamed[i].Opposite()
+			}
+		}
+		candidate.Clauses = append(candidate.Clauses, renamed)
+	}
+	if candidate.NumVars < cnf.NumVars && sh.fails(candidate) {
+		return candidate
+	}
+	return cnf
+}
+
+// formulaSize returns the number of clauses plus the number of literals of the
+// formula.
+func formulaSize(cnf *parsers.CNF) int {
+	size := len(cnf.Clauses)
+	for _, c := range cnf.Clauses {
+		size += len(c)
+	}
+	return size
+}
+
+// runShrink minimizes the instance by removing clauses, literals, and unused
+// variables while the predicate command keeps exiting with the same code as
+// on the original instance (similarly to cnfdd). The command is run with the
+// path of the candidate instance as last argument and must exit with a non-zero
+// code on the original instance.
+func runShrink(cfg *config) (int, error) {
+	if len(cfg.args) < 2 {
+		return exitUnknown, fmt.Errorf("shrink requires an instance and a predicate command")
+	}
+
+	cnf := &parsers.CNF{}
+	if err := loadInstance(cfg, cnf); err != nil {
+		return exitUnknown, fmt.Errorf("could not load instance: %s", err)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "yass-shrink")
+	if err != nil {
+		return exitUnknown, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	sh := &shrinker{
+		command: cfg.args[1:],
+		tmpFile: filepath.Join(tmpDir, "candidate.cnf"),
+		timeout: cfg.predTimeout,
+	}
+	sh.wantCode, err = sh.exitCode(cnf)
+	if err != nil {
+		return exitUnknown, fmt.Errorf("could not run the predicate command: %w", err)
+	}
+	if sh.wantCode == 0 {
+		// Shrinking would otherwise preserve the success of the command,
+		// which usually holds on the empty formula.
+		return exitUnknown, fmt.Errorf("the predicate command succeeds on the original instance, there is nothing to shrink")
+	}
+	fmt.Printf("c predicate exit code: %d\n", sh.wantCode)
+
+	nClauses, nVars := len(cnf.Clauses), cnf.NumVars
+
+	// Removing literals can make clauses redundant (e.g. duplicates) so both
+	// steps are repeated until the formula cannot be shrunk anymore.
+	for size := -1; size != formulaSize(cnf); {
+		size = formulaSize(cnf)
+		cnf = sh.shrinkClauses(cnf)
+		cnf = sh.shrinkLiterals(cnf)
+	}
+	cnf = sh.compactVariables(cnf)
+
+	fmt.Printf("c predicate runs:      %d\n", sh.runs)
+	fmt.Printf("c clauses:             %d -> %d\n", nClauses, len(cnf.Clauses))
+	fmt.Printf("c variables:           %d -> %d\n", nVars, cnf.NumVars)
+	fmt.Printf("c shrunk instance:     %s\n", cfg.shrinkOut)
+	return exitUnknown, saveCNF(cfg.shrinkOut, cnf)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/rhartert/yass/sat"
+)
+
+// interruptOnSignal stops the solver's search on the first SIGINT so that the
+// statistics and the results found so far can be reported. A second SIGINT
+// kills the program as usual. The returned function stops trapping SIGINT.
+func interruptOnSignal(s *sat.Solver) (stop func()) {
+	sigs := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigs, os.Interrupt)
+	go func() {
+		select {
+		case <-sigs:
+			signal.Stop(sigs) // restore the default behavior
+			s.Interrupt()
+		case <-done:
+		}
+	}()
+	return func() {
+		signal.Stop(sigs)
+		close(done)
+	}
+}
+
+// printDiagnostics prints the diagnostics as comment lines.
+func printDiagnostics(w io.Writer, d sat.Diagnostics) {
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+
+	fmt.Fprintf(bw, "c diagnostics after %.2fs\n", d.Time.Seconds())
+	fmt.Fprintf(bw, "c   conflicts:       %d\n", d.Conflicts)
+	fmt.Fprintf(bw, "c   propagations:    %d\n", d.Propagations)
+	fmt.Fprintf(bw, "c   decisions:       %d\n", d.Decisions)
+	fmt.Fprintf(bw, "c   restarts:        %d\n", d.Restarts)
+	fmt.Fprintf(bw, "c   iterations:      %d\n", d.Iterations)
+	fmt.Fprintf(bw, "c   decision level:  %d\n", d.DecisionLevel)
+	fmt.Fprintf(bw, "c   assigned:        %d/%d variables\n", d.Assigned, d.Variables)
+	fmt.Fprintf(bw, "c   problem clauses: %d\n", d.ProblemClauses)
+	fmt.Fprintf(bw, "c   core learnts:    %d (avg LBD %.2f)\n", d.CoreLearnts, d.AvgCoreLBD)
+	fmt.Fprintf(bw, "c   local learnts:   %d\n", d.LocalLearnts)
+	fmt.Fprintf(bw, "c   top variables:\n")
+	for _, vs := range d.TopVariables {
+		fmt.Fprintf(bw, "c     %8d  score %.3g  value %s\n", vs.Var+1, vs.Score, vs.Value)
+	}
+}
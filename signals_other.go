@@ -0,0 +1,10 @@
+//go:build !unix
+
+package main
+
+import "github.com/rhartert/yass/sat"
+
+// diagnoseOnSignal does nothing as SIGUSR1 does not exist on this platform.
+func diagnoseOnSignal(s *sat.Solver) (stop func()) {
+	return func() {}
+}
@@ -0,0 +1,36 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rhartert/yass/sat"
+)
+
+// diagnoseOnSignal prints a diagnostics snapshot of the solver on stderr on
+// each SIGUSR1, without stopping the search. The returned function stops
+// trapping SIGUSR1.
+func diagnoseOnSignal(s *sat.Solver) (stop func()) {
+	sigs := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigs, syscall.SIGUSR1)
+	go func() {
+		for {
+			select {
+			case <-sigs:
+				s.RequestDiagnostics(func(d sat.Diagnostics) {
+					printDiagnostics(os.Stderr, d)
+				})
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(sigs)
+		close(done)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rhartert/yass/parsers"
+	"github.com/rhartert/yass/sat"
+)
+
+// runSimplify prints the instance in the DIMACS format after simplifying it
+// with its root-level implications: variables implied by unit propagation are
+// fixed with unit clauses, satisfied clauses are removed, and false literals
+// are removed from the remaining clauses. The simplified instance is printed
+// as a single empty clause if it is unsatisfiable.
+func runSimplify(cfg *config) (int, error) {
+	cnf := &parsers.CNF{}
+	if err := loadInstance(cfg, cnf); err != nil {
+		return exitUnknown, fmt.Errorf("could not load instance: %s", err)
+	}
+
+	s := sat.NewDefaultSolver()
+	if err := cnf.Load(s); err != nil {
+		return exitUnknown, fmt.Errorf("could not load instance: %s", err)
+	}
+
+	simplified := &parsers.CNF{NumVars: cnf.NumVars}
+	if !s.Simplify() {
+		simplified.Clauses = [][]sat.Literal{{}}
+		return exitUnsatisfiable, parsers.WriteDIMACS(os.Stdout, simplified)
+	}
+
+	for v := 0; v < cnf.NumVars; v++ {
+		switch s.VarValue(v) {
+		case sat.True:
+			simplified.AddClause([]sat.Literal{sat.PositiveLiteral(v)})
+		case sat.False:
+			simplified.AddClause([]sat.Literal{sat.NegativeLiteral(v)})
+		}
+	}
+	nUnits := len(simplified.Clauses)
+
+	clause := []sat.Literal{}
+	for _, c := range cnf.Clauses {
+		clause = clause[:0]
+		satisfied := false
+		for _, l := range c {
+			switch s.LitValue(l) {
+			case sat.True:
+				satisfied = true
+			case sat.Unknown:
+				clause = append(clause, l)
+			}
+		}
+		if !satisfied {
+			simplified.AddClause(clause)
+		}
+	}
+
+	fmt.Printf("c fixed variables: %d\n", nUnits)
+	fmt.Printf("c removed clauses: %d\n", len(cnf.Clauses)-len(simplified.Clauses)+nUnits)
+	return exitUnknown, parsers.WriteDIMACS(os.Stdout, simplified)
+}
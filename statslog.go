@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/rhartert/yass/sat"
+)
+
+// statsLog appends the search progress to a file, either as CSV records or as
+// JSON lines depending on the file's extension.
+type statsLog struct {
+	file *os.File
+	csv  *csv.Writer   // nil if JSON lines are written
+	json *json.Encoder // nil if CSV records are written
+	err  error         // first write error
+}
+
+// statsRecord is a progress record as written in JSON lines.
+type statsRecord struct {
+	Time         float64 `json:"time_sec"`
+	Conflicts    uint64  `json:"conflicts"`
+	Restarts     uint64  `json:"restarts"`
+	Learnts      int     `json:"learnts"`
+	CoreLearnts  int     `json:"core_learnts"`
+	AvgCoreLBD   float64 `json:"avg_core_lbd"`
+	AvgLearntLBD float64 `json:"avg_learnt_lbd"`
+}
+
+var statsHeader = []string{
+	"time_sec",
+	"conflicts",
+	"restarts",
+	"learnts",
+	"core_learnts",
+	"avg_core_lbd",
+	"avg_learnt_lbd",
+}
+
+// openStatsLog opens the file in append mode. Files with the ".csv" extension
+// receive CSV records (with a header if the file is empty) and other files
+// receive JSON lines.
+func openStatsLog(filename string) (*statsLog, error) {
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	sl := &statsLog{file: f}
+
+	if !strings.HasSuffix(filename, ".csv") {
+		sl.json = json.NewEncoder(f)
+		return sl, nil
+	}
+
+	sl.csv = csv.NewWriter(f)
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if fi.Size() == 0 {
+		sl.err = sl.csv.Write(statsHeader)
+	}
+	return sl, nil
+}
+
+// Write appends the progress to the log. Errors are reported by Close.
+func (sl *statsLog) Write(p sat.Progress) {
+	if sl.err != nil {
+		return
+	}
+	if sl.json != nil {
+		sl.err = sl.json.Encode(statsRecord{
+			Time:         p.Time.Seconds(),
+			Conflicts:    p.Conflicts,
+			Restarts:     p.Restarts,
+			Learnts:      p.Learnts,
+			CoreLearnts:  p.CoreLearnts,
+			AvgCoreLBD:   p.AvgCoreLBD,
+			AvgLearntLBD: p.AvgLearntLBD,
+		})
+		return
+	}
+	sl.err = sl.csv.Write([]string{
+		strconv.FormatFloat(p.Time.Seconds(), 'f', 3, 64),
+		strconv.FormatUint(p.Conflicts, 10),
+		strconv.FormatUint(p.Restarts, 10),
+		strconv.Itoa(p.Learnts),
+		strconv.Itoa(p.CoreLearnts),
+		strconv.FormatFloat(p.AvgCoreLBD, 'f', 2, 64),
+		strconv.FormatFloat(p.AvgLearntLBD, 'f', 2, 64),
+	})
+}
+
+// Close flushes the log and closes its file. It returns the first error that
+// occurred while writing the log, if any.
+func (sl *statsLog) Close() error {
+	if sl.csv != nil {
+		sl.csv.Flush()
+		if sl.err == nil {
+			sl.err = sl.csv.Error()
+		}
+	}
+	if err := sl.file.Close(); sl.err == nil {
+		sl.err = err
+	}
+	return sl.err
+}
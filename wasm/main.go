@@ -0,0 +1,198 @@
+//go:build js && wasm
+
+// Command wasm exposes the solver to JavaScript when compiled to WebAssembly:
+//
+//	GOOS=js GOARCH=wasm go build -o yass.wasm ./wasm
+//
+// Once the module is started with Go's wasm_exec.js, it defines a global yass
+// object with two functions:
+//
+//   - yass.solve(cnf, options) solves the DIMACS CNF problem given as a string
+//     and returns a Promise of the result, an object with the status, the
+//     model as DIMACS literals (if satisfiable), the stop reason (if unknown),
+//     and the search statistics. Options are optional: timeoutMs,
+//     maxConflicts, seed, phaseSaving, randomFreq, and onProgress (called
+//     with the search statistics every progressInterval conflicts).
+//   - yass.interrupt() stops the running searches, which resolve as unknown.
+//
+// The search regularly yields to the browser's event loop so that the page
+// stays responsive while solving.
+package main
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"syscall/js"
+	"time"
+
+	"github.com/rhartert/yass/parsers"
+	"github.com/rhartert/yass/sat"
+)
+
+// yieldInterval is the number of search iterations between two yields to the
+// event loop.
+const yieldInterval = 20000
+
+// running contains the solvers whose search is running.
+var running = struct {
+	sync.Mutex
+	solvers map[*sat.Solver]struct{}
+}{solvers: map[*sat.Solver]struct{}{}}
+
+func main() {
+	js.Global().Set("yass", js.ValueOf(map[string]any{
+		"solve":     js.FuncOf(solve),
+		"interrupt": js.FuncOf(interrupt),
+	}))
+	select {} // keep the functions alive
+}
+
+// solve implements yass.solve(cnf, options).
+func solve(_ js.Value, args []js.Value) any {
+	if len(args) < 1 || args[0].Type() != js.TypeString {
+		return rejected(errors.New("yass.solve: the first argument must be a DIMACS CNF string"))
+	}
+	cnf := args[0].String()
+	opts := js.Undefined()
+	if len(args) > 1 {
+		opts = args[1]
+	}
+
+	var executor js.Func
+	executor = js.FuncOf(func(_ js.Value, promise []js.Value) any {
+		resolve, reject := promise[0], promise[1]
+		go func() {
+			defer executor.Release()
+			res, err := solveCNF(cnf, opts)
+			if err != nil {
+				reject.Invoke(jsError(err))
+				return
+			}
+			resolve.Invoke(res)
+		}()
+		return nil
+	})
+	return js.Global().Get("Promise").New(executor)
+}
+
+// interrupt implements yass.interrupt().
+func interrupt(js.Value, []js.Value) any {
+	running.Lock()
+	defer running.Unlock()
+	for s := range running.solvers {
+		s.Interrupt()
+	}
+	return nil
+}
+
+// solveCNF solves the CNF problem with the options given as a JS object and
+// returns the result as a JS object.
+func solveCNF(cnf string, opts js.Value) (map[string]any, error) {
+	options := []sat.Option{
+		// Sleeping parks the goroutine, which gives control back to the
+		// event loop.
+		sat.WithYield(yieldInterval, func() { time.Sleep(time.Millisecond) }),
+	}
+	if opts.Type() == js.TypeObject {
+		if v := opts.Get("timeoutMs"); v.Type() == js.TypeNumber {
+			options = append(options, sat.WithTimeout(time.Duration(v.Float()*float64(time.Millisecond))))
+		}
+		if v := opts.Get("maxConflicts"); v.Type() == js.TypeNumber {
+			options = append(options, sat.WithMaxConflicts(int64(v.Int())))
+		}
+		if v := opts.Get("seed"); v.Type() == js.TypeNumber {
+			options = append(options, sat.WithSeed(int64(v.Int())))
+		}
+		if v := opts.Get("phaseSaving"); v.Type() == js.TypeBoolean {
+			options = append(options, sat.WithPhaseSaving(v.Bool()))
+		}
+		if v := opts.Get("randomFreq"); v.Type() == js.TypeNumber {
+			options = append(options, sat.WithRandomDecisionFreq(v.Float()))
+		}
+		if f := opts.Get("onProgress"); f.Type() == js.TypeFunction {
+			interval := uint64(1000)
+			if v := opts.Get("progressInterval"); v.Type() == js.TypeNumber && v.Int() > 0 {
+				interval = uint64(v.Int())
+			}
+			options = append(options, sat.WithProgress(interval, func(p sat.Progress) {
+				f.Invoke(progressObject(p))
+			}))
+		}
+	}
+
+	s, err := sat.NewSolver(options...)
+	if err != nil {
+		return nil, err
+	}
+	if err := parsers.LoadDIMACSReader(strings.NewReader(cnf), s); err != nil {
+		return nil, err
+	}
+
+	running.Lock()
+	running.solvers[s] = struct{}{}
+	running.Unlock()
+	result := s.Solve()
+	running.Lock()
+	delete(running.solvers, s)
+	running.Unlock()
+
+	res := map[string]any{
+		"status": statusName(result.Status),
+		"statistics": map[string]any{
+			"conflicts":    result.Statistics.Conflicts,
+			"propagations": result.Statistics.Propagations,
+			"decisions":    result.Statistics.Decisions,
+			"restarts":     result.Statistics.Restarts,
+		},
+	}
+	switch result.Status {
+	case sat.True:
+		model := []any{}
+		for v, val := range result.Model {
+			l := sat.PositiveLiteral(v)
+			if !val {
+				l = l.Opposite()
+			}
+			model = append(model, l.ToDIMACS())
+		}
+		res["model"] = model
+	case sat.Unknown:
+		res["stopReason"] = result.StopReason.String()
+	}
+	return res, nil
+}
+
+// progressObject returns the progress as a JS object.
+func progressObject(p sat.Progress) map[string]any {
+	return map[string]any{
+		"timeMs":       p.Time.Milliseconds(),
+		"conflicts":    p.Conflicts,
+		"propagations": p.Propagations,
+		"decisions":    p.Decisions,
+		"restarts":     p.Restarts,
+		"learnts":      p.Learnts,
+	}
+}
+
+// statusName returns the status as printed in the SAT competition format.
+func statusName(status sat.LBool) string {
+	switch status {
+	case sat.True:
+		return "SATISFIABLE"
+	case sat.False:
+		return "UNSATISFIABLE"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// jsError returns the error as a JS Error.
+func jsError(err error) js.Value {
+	return js.Global().Get("Error").New(err.Error())
+}
+
+// rejected returns a Promise rejected with the error.
+func rejected(err error) js.Value {
+	return js.Global().Get("Promise").Call("reject", jsError(err))
+}
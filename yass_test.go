@@ -1,12 +1,33 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"io/fs"
+	"math/big"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/rhartert/yass/gen"
 	"github.com/rhartert/yass/parsers"
 	"github.com/rhartert/yass/sat"
 )
@@ -92,7 +113,7 @@ func toSet(s [][]bool) map[string]struct{} {
 
 // solveAll returns an unordered list of all the instance's models.
 func solveAll(s *sat.Solver) [][]bool {
-	for s.Solve() == sat.True {
+	for s.Solve().Status == sat.True {
 		// Add a new clause to forbid the last model found. Note that literal
 		// must be flipped: !(a ^ b ^ c) corresponds to (!a v !b v !c).
 		modelClause := make([]sat.Literal, s.NumVariables())
@@ -141,3 +162,950 @@ func TestSolveAll(t *testing.T) {
 		})
 	}
 }
+
+// runCommand runs the CLI with the given arguments and returns what it printed
+// on stdout and its exit code. The flags of the command are reset to their
+// default values afterwards.
+func runCommand(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+	out, code, err := runCommandErr(t, args...)
+	if err != nil {
+		t.Fatalf("%s: want no error, got %s", args[0], err)
+	}
+	return out, code
+}
+
+// runCommandErr is like runCommand but returns the error of the command.
+func runCommandErr(t *testing.T, args ...string) (string, int, error) {
+	t.Helper()
+	return runCommandLines(t, nil, args...)
+}
+
+// runCommandLines is like runCommandErr but also calls onLine, if not nil,
+// with each line printed on stdout while the command runs.
+func runCommandLines(t *testing.T, onLine func(line string), args ...string) (string, int, error) {
+	t.Helper()
+	cmd, fs := parseCommand(args)
+	defer fs.VisitAll(func(f *flag.Flag) { f.Value.Set(f.DefValue) })
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe(): %s", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+	out := make(chan string)
+	go func() {
+		var sb strings.Builder
+		br := bufio.NewReader(r)
+		for {
+			line, err := br.ReadString('\n')
+			sb.WriteString(line)
+			if onLine != nil && line != "" {
+				onLine(strings.TrimSuffix(line, "\n"))
+			}
+			if err != nil {
+				break
+			}
+		}
+		out <- sb.String()
+	}()
+
+	cfg, err := parseConfig(fs)
+	if err != nil {
+		t.Fatalf("parseConfig(): %s", err)
+	}
+	code, err := cmd.run(cfg)
+	w.Close()
+	return <-out, code, err
+}
+
+// writeInstance writes the DIMACS instance in a temporary file and returns its
+// name.
+func writeInstance(t *testing.T, dimacs string) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "instance.cnf")
+	if err := os.WriteFile(filename, []byte(dimacs), 0o644); err != nil {
+		t.Fatalf("WriteFile(): %s", err)
+	}
+	return filename
+}
+
+// TestSolve_allModels verifies that the models enumerated by the solve command
+// are printed after the status line.
+func TestSolve_allModels(t *testing.T) {
+	instance := writeInstance(t, "p cnf 2 1\n1 2 0\n")
+
+	out, code := runCommand(t, "solve", "-quiet", "-all_models", instance)
+
+	if code != exitSatisfiable {
+		t.Errorf("exit code: want %d, got %d", exitSatisfiable, code)
+	}
+	var got []string
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "s ") || strings.HasPrefix(line, "v ") {
+			got = append(got, line)
+		}
+	}
+	want := []string{"s SATISFIABLE", "v 1 2 0", "v 1 -2 0", "v -1 2 0"}
+	if len(got) > 0 {
+		slices.Sort(got[1:])
+		slices.Reverse(got[1:])
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("output: mismatch (+want, -got):\n%s", diff)
+	}
+	if !strings.Contains(out, "c models:       3\n") {
+		t.Errorf("output: want 3 models reported, got:\n%s", out)
+	}
+}
+
+// TestSolve_interrupt verifies that a SIGINT received during an enumeration
+// stops it, and that the statistics and the models found so far are printed.
+func TestSolve_interrupt(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("SIGINT cannot be sent to a process on windows")
+	}
+	self, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("FindProcess(): %s", err)
+	}
+	// Also trap SIGINT in the test, so that the test binary is not killed if
+	// the command stops trapping it before the signal is received.
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt)
+	defer signal.Stop(sigs)
+
+	instance := writeInstance(t, "p cnf 30 1\n1 2 0\n") // billions of models
+
+	// Each solve call of the enumeration starts by logging the size of the
+	// problem: the signal is sent once two models have been found.
+	solveCalls := 0
+	onLine := func(line string) {
+		if !strings.HasPrefix(line, "c variables:") {
+			return
+		}
+		if solveCalls++; solveCalls == 3 {
+			if err := self.Signal(os.Interrupt); err != nil {
+				t.Errorf("Signal(): %s", err)
+			}
+		}
+	}
+	out, code, err := runCommandLines(t, onLine, "solve", "-all_models", "-timeout", "1m", instance)
+
+	if err != nil {
+		t.Fatalf("solve: want no error, got %s", err)
+	}
+	if code != exitUnknown {
+		t.Errorf("exit code: want %d, got %d", exitUnknown, code)
+	}
+	for _, want := range []string{"c stopped:      interrupted\n", "c conflicts:", "s SATISFIABLE\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output: want %q, got:\n%s", want, out)
+		}
+	}
+	models, values := 0, 0
+	for _, line := range strings.Split(out, "\n") {
+		if rest, ok := strings.CutPrefix(line, "c models:"); ok {
+			models, _ = strconv.Atoi(strings.TrimSpace(rest))
+		}
+		if strings.HasPrefix(line, "v ") && strings.HasSuffix(line, " 0") {
+			values++
+		}
+	}
+	if models < 2 || values != models {
+		t.Errorf("output: want the models found (at least 2) to be printed, got %d models and %d printed", models, values)
+	}
+}
+
+// TestSolve_assumptions verifies that the solve command solves the instance
+// under the assumptions of the -assume and -assume_file flags, and reports the
+// failed assumptions.
+func TestSolve_assumptions(t *testing.T) {
+	instance := writeInstance(t, "p cnf 3 2\n-1 2 0\n-2 3 0\n") // 1 => 2 => 3
+	assumeFile := filepath.Join(t.TempDir(), "assumptions")
+	if err := os.WriteFile(assumeFile, []byte("1 -3 0\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile(): %s", err)
+	}
+
+	testCases := []struct {
+		desc       string
+		args       []string
+		wantCode   int
+		wantLine   string // line expected in the output
+		wantFailed []int
+	}{
+		{"satisfiable", []string{"-assume", "1"}, exitSatisfiable, "v 1 2 3 0", nil},
+		{"unsatisfiable", []string{"-assume", "1 -3"}, exitUnsatisfiable, "s UNSATISFIABLE", []int{-3, 1}},
+		{"file", []string{"-assume_file", assumeFile}, exitUnsatisfiable, "s UNSATISFIABLE", []int{-3, 1}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			args := append([]string{"solve", "-quiet"}, tc.args...)
+			out, code := runCommand(t, append(args, instance)...)
+
+			if code != tc.wantCode {
+				t.Errorf("exit code: want %d, got %d", tc.wantCode, code)
+			}
+			lines := strings.Split(out, "\n")
+			if !slices.Contains(lines, tc.wantLine) {
+				t.Errorf("output: want line %q, got:\n%s", tc.wantLine, out)
+			}
+			var gotFailed []int
+			for _, line := range lines {
+				if rest, ok := strings.CutPrefix(line, "c failed assumptions: "); ok {
+					gotFailed, _ = parseLiterals(rest)
+					slices.Sort(gotFailed)
+				}
+			}
+			if diff := cmp.Diff(tc.wantFailed, gotFailed); diff != "" {
+				t.Errorf("failed assumptions: mismatch (+want, -got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestSolve_debugAddr verifies that the debug server can be started by several
+// runs of the solve command in the same process.
+func TestSolve_debugAddr(t *testing.T) {
+	instance := writeInstance(t, "p cnf 2 1\n1 2 0\n")
+
+	for i := 0; i < 2; i++ {
+		_, code := runCommand(t, "solve", "-quiet", "-debug_addr", "localhost:0", instance)
+
+		if code != exitSatisfiable {
+			t.Errorf("run %d: exit code: want %d, got %d", i, exitSatisfiable, code)
+		}
+	}
+}
+
+// TestSolve_unsatClause verifies that the solve command reports the clause, and
+// its line, whose addition made the instance unsatisfiable.
+func TestSolve_unsatClause(t *testing.T) {
+	instance := writeInstance(t, "p cnf 1 3\nc comment\n1 0\n-1\n0\n1 0\n")
+
+	out, code := runCommand(t, "solve", instance)
+
+	if code != exitUnsatisfiable {
+		t.Errorf("exit code: want %d, got %d", exitUnsatisfiable, code)
+	}
+	if want := "c unsat clause: 2 on line 4 (falsified when added)\n"; !strings.Contains(out, want) {
+		t.Errorf("output: want %q, got:\n%s", want, out)
+	}
+}
+
+// TestSolve_model verifies that the solve command prints the model as "v"
+// lines only when -print_model is set, and writes the status and model to the
+// file of the -model_out flag.
+func TestSolve_model(t *testing.T) {
+	satInstance := writeInstance(t, "p cnf 3 3\n1 0\n-2 0\n-1 2 3 0\n")
+	unsatInstance := writeInstance(t, "p cnf 2 3\n1 2 0\n-1 0\n-2 0\n")
+
+	testCases := []struct {
+		desc       string
+		instance   string
+		printModel bool
+		wantCode   int
+		wantValues []string // "v" lines printed on stdout
+		wantFile   string   // content of the -model_out file
+	}{
+		{"sat", satInstance, true, exitSatisfiable, []string{"v 1 -2 3 0"}, "s SATISFIABLE\nv 1 -2 3 0\n"},
+		{"sat no print", satInstance, false, exitSatisfiable, nil, "s SATISFIABLE\nv 1 -2 3 0\n"},
+		{"unsat", unsatInstance, true, exitUnsatisfiable, nil, "s UNSATISFIABLE\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			modelOut := filepath.Join(t.TempDir(), "model")
+			printModel := fmt.Sprintf("-print_model=%t", tc.printModel)
+
+			out, code := runCommand(t, "solve", "-quiet", printModel, "-model_out", modelOut, tc.instance)
+
+			if code != tc.wantCode {
+				t.Errorf("exit code: want %d, got %d", tc.wantCode, code)
+			}
+			var gotValues []string
+			for _, line := range strings.Split(out, "\n") {
+				if strings.HasPrefix(line, "v ") {
+					gotValues = append(gotValues, line)
+				}
+			}
+			if diff := cmp.Diff(tc.wantValues, gotValues); diff != "" {
+				t.Errorf("value lines: mismatch (+want, -got):\n%s", diff)
+			}
+			gotFile, err := os.ReadFile(modelOut)
+			if err != nil {
+				t.Fatalf("ReadFile(): %s", err)
+			}
+			if diff := cmp.Diff(tc.wantFile, string(gotFile)); diff != "" {
+				t.Errorf("model file: mismatch (+want, -got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestPrintModel verifies that long models are wrapped on several "v" lines and
+// that the model is terminated by a single 0.
+func TestPrintModel(t *testing.T) {
+	model := make([]bool, 40)
+	for i := range model {
+		model[i] = i%2 == 0
+	}
+
+	var sb strings.Builder
+	printModel(&sb, model)
+
+	lines := strings.Split(strings.TrimSuffix(sb.String(), "\n"), "\n")
+	var got []int
+	for i, line := range lines {
+		rest, ok := strings.CutPrefix(line, "v ")
+		if !ok || len(line) > 80 {
+			t.Fatalf("line %d: want a \"v\" line of at most 80 characters, got %q", i, line)
+		}
+		if last := i == len(lines)-1; strings.HasSuffix(line, " 0") != last {
+			t.Errorf("line %d: want the 0 terminator on the last line only, got %q", i, line)
+		}
+		lits, err := parseLiterals(rest)
+		if err != nil {
+			t.Fatalf("line %d: %s", i, err)
+		}
+		got = append(got, lits...)
+	}
+	if len(lines) < 2 {
+		t.Errorf("printModel(): want the model wrapped on several lines, got %d", len(lines))
+	}
+	want := []int{}
+	for i, v := range model {
+		if v {
+			want = append(want, i+1)
+		} else {
+			want = append(want, -i-1)
+		}
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("printModel(): mismatch (+want, -got):\n%s", diff)
+	}
+}
+
+// TestSolve_incrementalHeader verifies that the solve command solves the queries
+// of incremental problems recognized by their problem line.
+func TestSolve_incrementalHeader(t *testing.T) {
+	instance := writeInstance(t, "p inccnf\n-1 2 0\na 1 0\na 1 -2 0\n")
+
+	out, code := runCommand(t, "solve", instance)
+
+	if code != exitUnknown {
+		t.Errorf("exit code: want %d, got %d", exitUnknown, code)
+	}
+	for _, want := range []string{"c query 1: true\n", "c query 2: false\n", "c queries:      2\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output: want %q, got:\n%s", want, out)
+		}
+	}
+}
+
+// TestBench verifies that the bench command summarizes the results of the
+// instances of a directory, and rejects directories without instances.
+func TestBench(t *testing.T) {
+	dir := t.TempDir()
+	for name, dimacs := range map[string]string{
+		"sat.cnf":   "p cnf 2 1\n1 2 0\n",
+		"unsat.cnf": "p cnf 1 2\n1 0\n-1 0\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(dimacs), 0o644); err != nil {
+			t.Fatalf("WriteFile(): %s", err)
+		}
+	}
+
+	out, _ := runCommand(t, "bench", "-timeout", "10s", dir)
+
+	for _, want := range []string{"solved:  2/2 (1 SAT, 1 UNSAT)\n", "PAR-2:   0.0"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output: want %q, got:\n%s", want, out)
+		}
+	}
+
+	if _, _, err := runCommandErr(t, "bench", "-timeout", "1s", t.TempDir()); err == nil {
+		t.Errorf("bench on an empty directory: want error, got none")
+	}
+}
+
+// TestSolve_severalInstances verifies that the solve command summarizes the
+// results of several instances and rejects the flags that only apply to a
+// single instance.
+func TestSolve_severalInstances(t *testing.T) {
+	satFile := writeInstance(t, "p cnf 2 1\n1 2 0\n")
+	unsatFile := writeInstance(t, "p cnf 1 2\n1 0\n-1 0\n")
+
+	out, _ := runCommand(t, "solve", "-parallel", "2", satFile, unsatFile)
+
+	if !strings.Contains(out, "solved:  2/2 (1 SAT, 1 UNSAT)") {
+		t.Errorf("output: want 2 solved instances, got:\n%s", out)
+	}
+
+	for _, flags := range [][]string{
+		{"-json"},
+		{"-proof", filepath.Join(t.TempDir(), "proof")},
+		{"-all_models"},
+		{"-assume", "1"},
+	} {
+		args := append(append([]string{"solve"}, flags...), satFile, unsatFile)
+		if _, _, err := runCommandErr(t, args...); err == nil {
+			t.Errorf("solve %s: want error, got none", strings.Join(flags, " "))
+		}
+	}
+}
+
+// TestEquiv verifies the verdict of the equiv command, which is only known if
+// the miter or the statuses of both instances are.
+func TestEquiv(t *testing.T) {
+	php, _ := runCommand(t, "gen", "php", "-n", "8")
+	hard := writeInstance(t, php)
+	satisfiable := writeInstance(t, "p cnf 2 1\n1 2 0\n")
+	equivalent := writeInstance(t, "p cnf 2 2\n2 1 0\n1 2 0\n")
+	unsatisfiable := writeInstance(t, "p cnf 2 2\n1 0\n-1 0\n")
+
+	testCases := []struct {
+		desc     string
+		args     []string
+		wantLine string
+		wantCode int
+	}{
+		{"equivalent", []string{satisfiable, equivalent}, "s EQUIVALENT", exitUnsatisfiable},
+		{"not equisatisfiable", []string{satisfiable, unsatisfiable}, "s NOT EQUIVALENT", exitSatisfiable},
+		{"unknown", []string{"-max_conflicts", "10", hard, hard}, "s UNKNOWN", exitUnknown},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			out, code := runCommand(t, append([]string{"equiv"}, tc.args...)...)
+
+			if code != tc.wantCode {
+				t.Errorf("exit code: want %d, got %d", tc.wantCode, code)
+			}
+			if !strings.Contains(out, tc.wantLine+"\n") {
+				t.Errorf("output: want %q, got:\n%s", tc.wantLine, out)
+			}
+		})
+	}
+}
+
+// TestServe verifies that the serve command solves the instances of the
+// requests, with a default timeout, and rejects invalid timeouts.
+func TestServe(t *testing.T) {
+	_, fs := parseCommand([]string{"serve", "-workers", "2"})
+	defer fs.VisitAll(func(f *flag.Flag) { f.Value.Set(f.DefValue) })
+	cfg, err := parseConfig(fs)
+	if err != nil {
+		t.Fatalf("parseConfig(): %s", err)
+	}
+	server := httptest.NewServer(serveHandler(cfg))
+	defer server.Close()
+
+	testCases := []struct {
+		query       string
+		wantCode    int
+		wantTimeout string
+	}{
+		{"", http.StatusOK, "1m0s"},
+		{"?timeout=10s", http.StatusOK, "10s"},
+		{"?timeout=-1s", http.StatusBadRequest, ""},
+		{"?timeout=soon", http.StatusBadRequest, ""},
+	}
+
+	// Requests are sent concurrently, beyond the number of workers.
+	var wg sync.WaitGroup
+	for _, tc := range testCases {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := http.Post(server.URL+"/solve"+tc.query, "text/plain", strings.NewReader("p cnf 2 1\n1 2 0\n"))
+			if err != nil {
+				t.Errorf("POST %q: want no error, got %s", tc.query, err)
+				return
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tc.wantCode {
+				t.Errorf("POST %q: want status %d, got %d", tc.query, tc.wantCode, resp.StatusCode)
+				return
+			}
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+			var got struct {
+				Status string
+				Config struct{ Timeout string }
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Errorf("POST %q: invalid JSON: %s", tc.query, err)
+				return
+			}
+			if got.Status != "SATISFIABLE" || got.Config.Timeout != tc.wantTimeout {
+				t.Errorf("POST %q: want SATISFIABLE with timeout %s, got %s with timeout %s",
+					tc.query, tc.wantTimeout, got.Status, got.Config.Timeout)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// TestShrink verifies that the shrink command minimizes an instance while the
+// predicate command keeps exiting with the same code.
+func TestShrink(t *testing.T) {
+	instance := writeInstance(t, "p cnf 4 4\n1 2 0\n-3 4 0\n2 -4 0\n1 3 4 0\n")
+	out := filepath.Join(t.TempDir(), "shrunk.cnf")
+
+	// The predicate fails if a clause starts with a negative literal.
+	_, _, err := runCommandErr(t, "shrink", "-out", out, instance,
+		"sh", "-c", `grep -q "^-" "$1" && exit 1; exit 0`, "sh")
+
+	if err != nil {
+		t.Fatalf("shrink: want no error, got %s", err)
+	}
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("ReadFile(): %s", err)
+	}
+	if diff := cmp.Diff("p cnf 1 1\n-1 0\n", string(got)); diff != "" {
+		t.Errorf("shrunk instance: mismatch (+want, -got):\n%s", diff)
+	}
+}
+
+func TestShrink_predicateSucceeds(t *testing.T) {
+	instance := writeInstance(t, "p cnf 2 1\n1 2 0\n")
+	out := filepath.Join(t.TempDir(), "shrunk.cnf")
+
+	_, _, err := runCommandErr(t, "shrink", "-out", out, instance, "true")
+
+	if err == nil {
+		t.Errorf("shrink: want error, got none")
+	}
+	if _, err := os.Stat(out); err == nil {
+		t.Errorf("shrink: want no shrunk instance written")
+	}
+}
+
+func TestShrink_predicateTimeout(t *testing.T) {
+	instance := writeInstance(t, "p cnf 2 1\n1 2 0\n")
+	out := filepath.Join(t.TempDir(), "shrunk.cnf")
+
+	start := time.Now()
+	_, _, err := runCommandErr(t, "shrink", "-out", out,
+		"-predicate_timeout", "100ms", instance, "sh", "-c", "sleep 10", "sh")
+
+	if !errors.Is(err, errPredicateTimeout) {
+		t.Errorf("shrink: want error %q, got %v", errPredicateTimeout, err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("shrink: want the predicate to be stopped, ran for %s", d)
+	}
+}
+
+// TestCompileDDNNF verifies that the Decision-DNNF compiled from the instances
+// of testdataDir with 20 variables (larger instances take too long to compile)
+// have as many models as the instances.
+func TestCompileDDNNF(t *testing.T) {
+	testCases, err := listTestCases(filepath.Join(testdataDir, "uf20-91"))
+	if err != nil {
+		t.Fatalf("Error parsing test cases: %s", err)
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.instanceName, func(t *testing.T) {
+			t.Parallel()
+
+			want, err := parsers.ReadModels(tc.modelsFile)
+			if err != nil {
+				t.Errorf("Model parsing error: %s", err)
+			}
+			cnf := &parsers.CNF{}
+			if err := parsers.LoadDIMACS(tc.instanceFile, false, cnf); err != nil {
+				t.Errorf("Instance parsing error: %s", err)
+			}
+
+			d, err := compileDDNNF(cnf.Clauses, -1, -1)
+			if err != nil {
+				t.Fatalf("compileDDNNF(): want no error, got %s", err)
+			}
+			got := d.count(cnf.NumVars)
+
+			if got.Cmp(big.NewInt(int64(len(want)))) != 0 {
+				t.Errorf("Incorrect number of models: got %s, want %d", got, len(want))
+			}
+		})
+	}
+}
+
+// readNNF reads a circuit in the NNF format of c2d, whose root is the last node.
+func readNNF(r io.Reader) (*ddnnf, int, error) {
+	d := &ddnnf{}
+	numVars := 0
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		ints := make([]int, len(fields)-1)
+		for i, f := range fields[1:] {
+			n, err := strconv.Atoi(f)
+			if err != nil {
+				return nil, 0, err
+			}
+			ints[i] = n
+		}
+		switch fields[0] {
+		case "nnf":
+			numVars = ints[2]
+		case "L":
+			d.nodes = append(d.nodes, nnfNode{kind: 'L', lit: sat.LiteralFromDIMACS(ints[0]), decision: -1})
+		case "A":
+			d.nodes = append(d.nodes, nnfNode{kind: 'A', decision: -1, children: ints[1:]})
+		case "O":
+			d.nodes = append(d.nodes, nnfNode{kind: 'O', decision: ints[0] - 1, children: ints[2:]})
+		default:
+			return nil, 0, fmt.Errorf("unexpected line %q", sc.Text())
+		}
+	}
+	d.root = len(d.nodes) - 1
+	return d, numVars, sc.Err()
+}
+
+// TestCompileDDNNF_c2d verifies that the Decision-DNNF written in the c2d
+// format can be read back with the same number of models.
+func TestCompileDDNNF_c2d(t *testing.T) {
+	cnf := &parsers.CNF{}
+	if err := parsers.LoadDIMACS(filepath.Join(testdataDir, "uf20-91", "uf20-01.cnf"), false, cnf); err != nil {
+		t.Fatalf("Instance parsing error: %s", err)
+	}
+	d, err := compileDDNNF(cnf.Clauses, -1, -1)
+	if err != nil {
+		t.Fatalf("compileDDNNF(): want no error, got %s", err)
+	}
+	buf := &bytes.Buffer{}
+	if err := d.write(buf, cnf.NumVars); err != nil {
+		t.Fatalf("write(): want no error, got %s", err)
+	}
+
+	got, numVars, err := readNNF(buf)
+	if err != nil {
+		t.Fatalf("readNNF(): want no error, got %s", err)
+	}
+	if numVars != cnf.NumVars || len(got.nodes) != len(d.reachable()) {
+		t.Errorf("readNNF(): want %d nodes over %d variables, got %d nodes over %d variables",
+			len(d.reachable()), cnf.NumVars, len(got.nodes), numVars)
+	}
+	if want, got := d.count(cnf.NumVars), got.count(numVars); got.Cmp(want) != 0 {
+		t.Errorf("count: want %s, got %s", want, got)
+	}
+}
+
+// TestCompile_budget verifies that the compile command stops once it exceeds
+// its timeout or node limit.
+func TestCompile_budget(t *testing.T) {
+	instance := filepath.Join(testdataDir, "uf20-91", "uf20-01.cnf")
+
+	for _, tc := range []struct {
+		flags []string
+		want  string
+	}{
+		{[]string{"-timeout", "1ns"}, "c stopped:      timeout\n"},
+		{[]string{"-max_nodes", "10"}, "c stopped:      node limit\n"},
+	} {
+		args := append(append([]string{"compile"}, tc.flags...), instance)
+		out, code := runCommand(t, args...)
+
+		if code != exitUnknown {
+			t.Errorf("compile %s: exit code: want %d, got %d", strings.Join(tc.flags, " "), exitUnknown, code)
+		}
+		if !strings.Contains(out, tc.want) {
+			t.Errorf("compile %s: want %q, got:\n%s", strings.Join(tc.flags, " "), tc.want, out)
+		}
+	}
+}
+
+// TestBuildOBDD verifies that the OBDD of an instance projected onto a few
+// variables leads to the true terminal for exactly the assignments of these
+// variables that can be extended to a model.
+func TestBuildOBDD(t *testing.T) {
+	vars := []int{3, 0, 7, 12, 5}
+	s := sat.NewDefaultSolver()
+	if err := parsers.LoadDIMACS(filepath.Join(testdataDir, "uf20-91", "uf20-01.cnf"), false, s); err != nil {
+		t.Fatalf("Instance parsing error: %s", err)
+	}
+
+	b, ok := buildOBDD(s, vars)
+	if !ok {
+		t.Fatalf("buildOBDD(): want OBDD, got stopped")
+	}
+
+	for mask := 0; mask < 1<<len(vars); mask++ {
+		assumptions := make([]sat.Literal, len(vars))
+		n := b.root
+		for i, v := range vars {
+			assumptions[i] = sat.NegativeLiteral(v)
+			if mask&(1<<i) != 0 {
+				assumptions[i] = sat.PositiveLiteral(v)
+			}
+			if node := b.nodes[n]; n > obddTrue && node.level == i {
+				n = node.low
+				if mask&(1<<i) != 0 {
+					n = node.high
+				}
+			}
+		}
+		want := s.SolveWithAssumptions(assumptions).Status == sat.True
+		if got := n == obddTrue; got != want {
+			t.Errorf("OBDD with assumptions %v: want %t, got %t", assumptions, want, got)
+		}
+	}
+}
+
+// The golden statistics test solves each instance of testdataDir with the
+// default options and compares the search statistics with the ones recorded
+// in goldenStatsFile. The search being deterministic, any difference reveals
+// a change of the search behavior. Intended changes are recorded by running
+// the test with the -update flag:
+//
+//	go test -run TestGoldenStats -update
+var updateGolden = flag.Bool("update", false, "update the golden statistics file")
+
+// File containing the statistics of the golden test, one line per instance.
+var goldenStatsFile = filepath.Join(testdataDir, "stats.golden")
+
+// Conflict limit of each search in the golden statistics test, which keeps the
+// test fast on hard instances. It is above the first reduction of the clause
+// DB (after 20000 conflicts by default) so that the golden statistics also
+// cover the reductions.
+const goldenMaxConflicts = 25000
+
+// searchStats returns the search statistics of the instance as a line of the
+// golden statistics file.
+func searchStats(tc testCase) (string, error) {
+	s, err := sat.NewSolver(sat.WithMaxConflicts(goldenMaxConflicts))
+	if err != nil {
+		return "", err
+	}
+	if err := parsers.LoadDIMACS(tc.instanceFile, false, s); err != nil {
+		return "", err
+	}
+	status := s.Solve().Status
+	name, err := filepath.Rel(testdataDir, tc.instanceFile)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s %s conflicts=%d decisions=%d propagations=%d",
+		filepath.ToSlash(name), status, s.Statistics.Conflicts,
+		s.Statistics.Decisions, s.Statistics.Propagations), nil
+}
+
+func TestGoldenStats(t *testing.T) {
+	testCases, err := listTestCases(testdataDir)
+	if err != nil {
+		t.Fatalf("Error parsing test cases: %s", err)
+	}
+
+	got := make([]string, len(testCases))
+	t.Run("solve", func(t *testing.T) {
+		for i, tc := range testCases {
+			t.Run(tc.instanceName, func(t *testing.T) {
+				t.Parallel()
+				stats, err := searchStats(tc)
+				if err != nil {
+					t.Fatalf("Error solving instance: %s", err)
+				}
+				got[i] = stats
+			})
+		}
+	})
+	if t.Failed() {
+		return
+	}
+
+	content := strings.Join(got, "\n") + "\n"
+	if *updateGolden {
+		if err := os.WriteFile(goldenStatsFile, []byte(content), 0o644); err != nil {
+			t.Fatalf("Error writing golden file: %s", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(goldenStatsFile)
+	if err != nil {
+		t.Fatalf("Error reading golden file (use -update to create it): %s", err)
+	}
+	if diff := cmp.Diff(strings.Split(string(want), "\n"), strings.Split(content, "\n")); diff != "" {
+		t.Errorf("Search statistics mismatch (-want, +got), use -update if intended:\n%s", diff)
+	}
+}
+
+// The differential test compares the answers of YASS with the ones of the
+// reference solvers found in the PATH (see referenceSolvers) on the instances
+// of testdataDir and on generated instances. It formalizes the way the models
+// of the test cases were pre-computed and is opt-in as it depends on external
+// solvers:
+//
+//	go test -run TestDifferential -differential
+var (
+	flagDifferential = flag.Bool("differential", false, "compare answers with the reference solvers found in the PATH")
+	flagDiffTimeout  = flag.Duration("differential_timeout", 10*time.Second, "time limit of each solver on each instance of the differential test")
+	flagDiffGenerate = flag.Int("differential_generate", 100, "number of generated instances in the differential test")
+)
+
+// Reference solvers of the differential test, which must follow the SAT
+// competition exit codes.
+var referenceSolvers = []string{"kissat", "minisat", "cadical", "glucose"}
+
+// referenceStatus returns the answer of the reference solver on the instance
+// file, or Unknown if the solver does not answer before the timeout.
+func referenceStatus(solver string, instanceFile string, timeout time.Duration) (sat.LBool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := exec.CommandContext(ctx, solver, instanceFile).Run()
+	if ctx.Err() != nil {
+		return sat.Unknown, nil
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return sat.Unknown, fmt.Errorf("%s: %v", solver, err)
+	}
+	switch exitErr.ExitCode() {
+	case exitSatisfiable:
+		return sat.True, nil
+	case exitUnsatisfiable:
+		return sat.False, nil
+	default:
+		return sat.Unknown, fmt.Errorf("%s: unexpected %s", solver, exitErr)
+	}
+}
+
+// yassStatus returns the answer of YASS on the instance file, or Unknown if
+// it does not answer before the timeout. The model found, if any, is checked.
+func yassStatus(instanceFile string, timeout time.Duration) (sat.LBool, error) {
+	cnf := &parsers.CNF{}
+	if err := parsers.LoadDIMACS(instanceFile, false, cnf); err != nil {
+		return sat.Unknown, err
+	}
+	s, err := sat.NewSolver(sat.WithTimeout(timeout))
+	if err != nil {
+		return sat.Unknown, err
+	}
+	if err := cnf.Load(s); err != nil {
+		return sat.Unknown, err
+	}
+	status := s.Solve().Status
+	if status == sat.True {
+		if c := sat.VerifyModel(cnf.Clauses, s.Model()); c >= 0 {
+			return status, fmt.Errorf("model violates clause %d", c+1)
+		}
+	}
+	return status, nil
+}
+
+// generateInstances writes n random instances of various families in dir and
+// returns their files.
+func generateInstances(dir string, n int) ([]string, error) {
+	rng := rand.New(rand.NewSource(0))
+	files := []string{}
+	for i := 0; i < n; i++ {
+		var cnf *parsers.CNF
+		switch i % 3 {
+		case 0:
+			cnf = gen.RandomKSATRatio(rng, 3, 50+rng.Intn(150), gen.Threshold3SAT)
+		case 1:
+			cnf = gen.Parity(rng, 4+rng.Intn(12))
+		default:
+			nNodes := 10 + rng.Intn(30)
+			edges := gen.RandomGraph(rng, nNodes, 2*nNodes)
+			cnf = gen.GraphColoring(nNodes, edges, 3)
+		}
+		file := filepath.Join(dir, fmt.Sprintf("generated-%d.cnf", i))
+		if err := saveCNF(file, cnf); err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+func TestDifferential(t *testing.T) {
+	if !*flagDifferential {
+		t.Skip("differential test not enabled, use -differential")
+	}
+	solvers := []string{}
+	for _, name := range referenceSolvers {
+		if path, err := exec.LookPath(name); err == nil {
+			solvers = append(solvers, path)
+		}
+	}
+	if len(solvers) == 0 {
+		t.Skipf("no reference solver found in the PATH among %v", referenceSolvers)
+	}
+
+	testCases, err := listTestCases(testdataDir)
+	if err != nil {
+		t.Fatalf("Error parsing test cases: %s", err)
+	}
+	files := []string{}
+	for _, tc := range testCases {
+		files = append(files, tc.instanceFile)
+	}
+	generated, err := generateInstances(t.TempDir(), *flagDiffGenerate)
+	if err != nil {
+		t.Fatalf("Error generating instances: %s", err)
+	}
+	files = append(files, generated...)
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			t.Parallel()
+			want, err := yassStatus(file, *flagDiffTimeout)
+			if err != nil {
+				t.Fatalf("yass: %s", err)
+			}
+			for _, solver := range solvers {
+				got, err := referenceStatus(solver, file, *flagDiffTimeout)
+				if err != nil {
+					t.Errorf("Reference solver error: %s", err)
+					continue
+				}
+				if want != sat.Unknown && got != sat.Unknown && want != got {
+					t.Errorf("Answer mismatch: yass answers %s but %s answers %s",
+						statusName(want), filepath.Base(solver), statusName(got))
+				}
+			}
+		})
+	}
+}
+
+// TestParseProofFormat verifies that only the DRAT proof formats are accepted.
+func TestParseProofFormat(t *testing.T) {
+	for name, want := range map[string]sat.ProofFormat{"text": sat.ProofText, "binary": sat.ProofBinary} {
+		if got, err := parseProofFormat(name); err != nil || got != want {
+			t.Errorf("parseProofFormat(%q): want %s, got %s (%v)", name, want, got, err)
+		}
+	}
+	for _, name := range []string{"lrat", "drup"} {
+		if _, err := parseProofFormat(name); err == nil {
+			t.Errorf("parseProofFormat(%q): want error, got none", name)
+		}
+	}
+}
+
+// TestWasmBuild verifies that the WebAssembly wrapper compiles. Its build
+// constraint excludes it from the builds and checks of the other platforms.
+func TestWasmBuild(t *testing.T) {
+	if testing.Short() {
+		t.Skip("WebAssembly build skipped in short mode")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found in the PATH")
+	}
+
+	cmd := exec.Command(goTool, "build", "-o", filepath.Join(t.TempDir(), "yass.wasm"), "./wasm")
+	cmd.Env = append(os.Environ(), "GOOS=js", "GOARCH=wasm")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("GOOS=js GOARCH=wasm go build ./wasm: %s\n%s", err, out)
+	}
+}